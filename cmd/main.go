@@ -1,6 +1,8 @@
 package main
 
 import (
+	_ "time/tzdata" // embed the IANA timezone database for user timezone settings
+
 	"github.com/jt00721/habit-tracker/config"
 )
 
@@ -1,21 +1,37 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/jt00721/habit-tracker/infrastructure"
+	"github.com/jt00721/habit-tracker/internal/domain"
 	"github.com/jt00721/habit-tracker/internal/repository"
 	"github.com/jt00721/habit-tracker/internal/routes"
 	"github.com/jt00721/habit-tracker/internal/usecase"
 )
 
 type App struct {
-	Router  *gin.Engine
-	HabitUc *usecase.HabitUsecase
+	Router      *gin.Engine
+	HabitUc     *usecase.HabitUsecase
+	SettingsUc  *usecase.SettingsUsecase
+	SkipUc      *usecase.SkipUsecase
+	StatsUc     *usecase.StatsUsecase
+	UserUc      *usecase.UserUsecase
+	TokenUc     *usecase.AccessTokenUsecase
+	SSOUc       *usecase.SSOUsecase
+	TwoFactorUc *usecase.TwoFactorUsecase
+	SharingUc   *usecase.SharingUsecase
+	ChallengeUc *usecase.ChallengeUsecase
+
+	StreakResetUc       *usecase.StreakResetUsecase
+	StreakResetInterval time.Duration // 0 disables the scheduled streak reset
 }
 
 func NewApp() *App {
@@ -32,18 +48,127 @@ func NewApp() *App {
 
 	// Initialize repositories & fetchers
 	habitRepo := &repository.HabitRepository{DB: infrastructure.DB}
-	habitUc := &usecase.HabitUsecase{HabitRepo: habitRepo}
+	settingsRepo := &repository.SettingsRepository{DB: infrastructure.DB}
+	timerRepo := &repository.TimerSessionRepository{DB: infrastructure.DB}
+	skipRepo := &repository.SkipRepository{DB: infrastructure.DB}
+	userRepo := &repository.UserRepository{DB: infrastructure.DB}
+	sessionRepo := &repository.SessionRepository{DB: infrastructure.DB}
+	tokenRepo := &repository.AccessTokenRepository{DB: infrastructure.DB}
+	habitUc := &usecase.HabitUsecase{HabitRepo: habitRepo, SettingsRepo: settingsRepo, TimerRepo: timerRepo, SkipRepo: skipRepo}
+	settingsUc := &usecase.SettingsUsecase{SettingsRepo: settingsRepo, HabitRepo: habitRepo, SkipRepo: skipRepo}
+	skipUc := &usecase.SkipUsecase{SkipRepo: skipRepo, HabitRepo: habitRepo, SettingsRepo: settingsRepo}
+	statsUc := &usecase.StatsUsecase{HabitRepo: habitRepo, SettingsRepo: settingsRepo, SkipRepo: skipRepo}
+	userUc := &usecase.UserUsecase{UserRepo: userRepo, SessionRepo: sessionRepo, SessionTTL: sessionTTL(), AdminEmail: adminEmail()}
+	tokenUc := &usecase.AccessTokenUsecase{TokenRepo: tokenRepo}
+	ssoUc := &usecase.SSOUsecase{UserRepo: userRepo, AdminEmail: userUc.AdminEmail}
+	twoFactorUc := &usecase.TwoFactorUsecase{UserRepo: userRepo, TwoFactorRepo: &repository.TwoFactorRepository{DB: infrastructure.DB}, SessionRepo: sessionRepo}
+	sharingUc := &usecase.SharingUsecase{HabitRepo: habitRepo, SharingRepo: &repository.SharingRepository{DB: infrastructure.DB}, UserRepo: userRepo}
+	challengeUc := &usecase.ChallengeUsecase{ChallengeRepo: &repository.ChallengeRepository{DB: infrastructure.DB}, HabitRepo: habitRepo, UserRepo: userRepo, SettingsRepo: settingsRepo, SkipRepo: skipRepo}
+	// Only set when configured, a nil *OIDCProvider would not be a nil Provider
+	if provider := oidcProvider(); provider != nil {
+		ssoUc.Provider = provider
+	}
+	if err := userUc.BootstrapAdmin(); err != nil {
+		log.Fatalf("Admin bootstrap failed: %v", err)
+	}
+	jobLock := &repository.JobLockRepository{DB: infrastructure.DB}
+	streakResetUc := &usecase.StreakResetUsecase{HabitRepo: habitRepo, SettingsRepo: settingsRepo, SkipRepo: skipRepo, TimerRepo: timerRepo, Lock: jobLock}
 
 	// Create Gin router
 	router := gin.Default()
 	router.Static("/static", "./static")
 
-	routes.SetupRoutes(router, habitUc)
+	routes.SetupRoutes(router, habitUc, settingsUc, skipUc, streakResetUc, statsUc, userUc, tokenUc, ssoUc, twoFactorUc, sharingUc, challengeUc)
 
 	return &App{
-		Router:  router,
-		HabitUc: habitUc,
+		Router:              router,
+		HabitUc:             habitUc,
+		SettingsUc:          settingsUc,
+		SkipUc:              skipUc,
+		StatsUc:             statsUc,
+		UserUc:              userUc,
+		TokenUc:             tokenUc,
+		SSOUc:               ssoUc,
+		TwoFactorUc:         twoFactorUc,
+		SharingUc:           sharingUc,
+		ChallengeUc:         challengeUc,
+		StreakResetUc:       streakResetUc,
+		StreakResetInterval: streakResetInterval(),
+	}
+}
+
+// streakResetInterval reads STREAK_RESET_INTERVAL as a duration such as "30m",
+// defaulting to hourly. "0" turns the scheduled reset off.
+func streakResetInterval() time.Duration {
+	value := os.Getenv("STREAK_RESET_INTERVAL")
+	if value == "" {
+		return time.Hour
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		log.Printf("Warning: Invalid STREAK_RESET_INTERVAL %q, using 1h", value)
+		return time.Hour
 	}
+	return interval
+}
+
+// sessionTTL reads SESSION_TTL as a duration such as "12h", defaulting to a day.
+func sessionTTL() time.Duration {
+	value := os.Getenv("SESSION_TTL")
+	if value == "" {
+		return 24 * time.Hour
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		log.Printf("Warning: Invalid SESSION_TTL %q, using 24h", value)
+		return 24 * time.Hour
+	}
+	return ttl
+}
+
+// adminEmail reads ADMIN_EMAIL, the email of the account that is made admin and
+// adopts the habits created before accounts existed. Unset, no account is made admin.
+func adminEmail() string {
+	value := os.Getenv("ADMIN_EMAIL")
+	if value == "" {
+		return ""
+	}
+
+	email, err := domain.NormalizeEmail(value)
+	if err != nil {
+		log.Printf("Warning: Invalid ADMIN_EMAIL %q, no account will be made admin", value)
+		return ""
+	}
+	return email
+}
+
+// oidcProvider configures single sign-on from OIDC_ISSUER_URL, OIDC_CLIENT_ID,
+// OIDC_CLIENT_SECRET and OIDC_REDIRECT_URL, the URL of /api/oidc/callback as
+// registered with the provider. OIDC_SCOPES optionally overrides the requested
+// scopes. Single sign-on is off unless the issuer is set.
+func oidcProvider() *infrastructure.OIDCProvider {
+	issuer := os.Getenv("OIDC_ISSUER_URL")
+	if issuer == "" {
+		return nil
+	}
+
+	clientID := os.Getenv("OIDC_CLIENT_ID")
+	redirectURL := os.Getenv("OIDC_REDIRECT_URL")
+	if clientID == "" || redirectURL == "" {
+		log.Println("Warning: OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required for single sign-on, disabling it")
+		return nil
+	}
+
+	log.Println("Single sign-on enabled with", issuer)
+	return infrastructure.NewOIDCProvider(infrastructure.OIDCConfig{
+		IssuerURL:    issuer,
+		ClientID:     clientID,
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  redirectURL,
+		Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+	})
 }
 
 // Run starts the server
@@ -58,6 +183,11 @@ func (app *App) Run() {
 		ip = ":"
 	}
 
+	if app.StreakResetInterval > 0 {
+		log.Println("Scheduling streak reset every", app.StreakResetInterval)
+		app.StreakResetUc.Start(context.Background(), app.StreakResetInterval)
+	}
+
 	fmt.Println("Server running on port", port)
 	app.Router.Run(ip + port)
 }
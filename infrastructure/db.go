@@ -42,7 +42,7 @@ func InitDB() error {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	err = db.AutoMigrate(&domain.Habit{})
+	err = db.AutoMigrate(&domain.Habit{}, &domain.HabitCompletion{}, &domain.Settings{}, &domain.TimerSession{}, &domain.Relapse{}, &domain.Skip{}, &domain.StreakRun{}, &domain.User{}, &domain.Session{}, &domain.AccessToken{}, &domain.UserIdentity{}, &domain.RecoveryCode{}, &domain.LoginChallenge{}, &domain.HabitMember{}, &domain.CompletionReaction{}, &domain.CompletionComment{}, &domain.Challenge{}, &domain.ChallengeParticipant{})
 	if err != nil {
 		log.Fatal("Migration failed:", err)
 		return fmt.Errorf("failed to auto-migrate database model: %w", err)
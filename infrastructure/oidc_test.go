@@ -0,0 +1,150 @@
+package infrastructure_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/infrastructure"
+	"github.com/jt00721/habit-tracker/internal/domain"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURL = "http://localhost:8080/api/oidc/callback"
+
+func TestOIDCAuthCodeURL(t *testing.T) {
+	issuer := testutils.NewMockIssuer(t)
+	defer issuer.Close()
+
+	provider := infrastructure.NewOIDCProvider(issuer.Config(redirectURL))
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	assert.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, issuer.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+
+	query := parsed.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, testutils.MockClientID, query.Get("client_id"))
+	assert.Equal(t, redirectURL, query.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, "state", query.Get("state"))
+	assert.Equal(t, "nonce", query.Get("nonce"))
+	assert.Equal(t, "challenge", query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	// The issuer in the metadata must be the configured one
+	config := issuer.Config(redirectURL)
+	config.IssuerURL = issuer.URL + "/"
+	_, err = infrastructure.NewOIDCProvider(config).AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	assert.ErrorContains(t, err, "expected")
+}
+
+func TestOIDCExchange(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		modifyClaims func(map[string]interface{})
+		signingKey   *rsa.PrivateKey
+		verifier     string
+		nonce        string
+		want         *domain.ExternalIdentity
+		errContains  string
+	}{
+		{
+			name: "valid id token",
+			want: &domain.ExternalIdentity{Subject: "mock-subject", Email: "sso@example.com", EmailVerified: true},
+		},
+		{
+			name: "email_verified as a string",
+			modifyClaims: func(claims map[string]interface{}) {
+				claims["email_verified"] = "true"
+				claims["aud"] = []string{testutils.MockClientID, "other-client"}
+				claims["azp"] = testutils.MockClientID
+			},
+			want: &domain.ExternalIdentity{Subject: "mock-subject", Email: "sso@example.com", EmailVerified: true},
+		},
+		{
+			name:        "wrong code verifier",
+			verifier:    "not-the-verifier",
+			errContains: "token endpoint returned 400",
+		},
+		{
+			name:        "wrong nonce",
+			nonce:       "replayed",
+			errContains: "nonce does not match",
+		},
+		{
+			name:        "signed by another key",
+			signingKey:  otherKey,
+			errContains: "invalid id token signature",
+		},
+		{
+			name: "other issuer",
+			modifyClaims: func(claims map[string]interface{}) {
+				claims["iss"] = "https://evil.example.com"
+			},
+			errContains: "id token issued by",
+		},
+		{
+			name: "other audience",
+			modifyClaims: func(claims map[string]interface{}) {
+				claims["aud"] = "other-client"
+			},
+			errContains: "not meant for this client",
+		},
+		{
+			name: "issued to another client",
+			modifyClaims: func(claims map[string]interface{}) {
+				claims["aud"] = []string{testutils.MockClientID, "other-client"}
+				claims["azp"] = "other-client"
+			},
+			errContains: "issued to another client",
+		},
+		{
+			name: "expired",
+			modifyClaims: func(claims map[string]interface{}) {
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			},
+			errContains: "expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := testutils.NewMockIssuer(t)
+			defer issuer.Close()
+			issuer.ModifyClaims = tt.modifyClaims
+			issuer.SigningKey = tt.signingKey
+
+			provider := infrastructure.NewOIDCProvider(issuer.Config(redirectURL))
+			code := issuer.Authorize("nonce", domain.CodeChallenge("verifier"), redirectURL)
+
+			verifier, nonce := "verifier", "nonce"
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			identity, err := provider.Exchange(context.Background(), code, verifier, nonce)
+
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			tt.want.Issuer = issuer.URL
+			assert.Equal(t, tt.want, identity)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scope limits what a personal access token can do.
+type Scope string
+
+const (
+	ScopeHabitsRead       Scope = "habits:read"       // read habits, their history and stats
+	ScopeHabitsWrite      Scope = "habits:write"      // create, edit and delete habits and skips
+	ScopeCompletionsWrite Scope = "completions:write" // check in, log relapses and run timers
+)
+
+// Scopes lists every scope, in the order they are stored.
+var Scopes = []Scope{ScopeHabitsRead, ScopeHabitsWrite, ScopeCompletionsWrite}
+
+// AccessTokenPrefix starts every personal access token, telling it apart from a
+// session token and making leaked tokens easy to search for.
+const AccessTokenPrefix = "htp_"
+
+// AccessToken is a long-lived personal access token a user creates for scripts
+// and automations. Only a hash of the token is stored. Scopes is a comma
+// separated list, e.g. "habits:read,completions:write".
+type AccessToken struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"not null;index"`
+	Name       string     `gorm:"not null"`
+	TokenHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"not null"`
+	LastUsedAt *time.Time // nil until the token is first used
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
+
+// HasScope reports whether the token grants the scope.
+func (t AccessToken) HasScope(scope Scope) bool {
+	for _, granted := range strings.Split(t.Scopes, ",") {
+		if granted != "" && Scope(granted) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScopes validates the requested scopes and returns them in the stored
+// form, without duplicates and in the order of Scopes.
+func ParseScopes(scopes []string) (string, error) {
+	requested := map[Scope]bool{}
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !isValidScope(Scope(scope)) {
+			return "", fmt.Errorf("invalid scope: %s", scope)
+		}
+		requested[Scope(scope)] = true
+	}
+	if len(requested) == 0 {
+		return "", fmt.Errorf("at least one scope is required")
+	}
+
+	var parsed []string
+	for _, scope := range Scopes {
+		if requested[scope] {
+			parsed = append(parsed, string(scope))
+		}
+	}
+	return strings.Join(parsed, ","), nil
+}
+
+func isValidScope(scope Scope) bool {
+	for _, valid := range Scopes {
+		if scope == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+type AccessTokenRepository interface {
+	Create(t *AccessToken) error
+	GetByID(id uint) (*AccessToken, error)
+	GetByTokenHash(hash string) (*AccessToken, error)
+	GetForUser(userID uint) ([]AccessToken, error)
+	UpdateLastUsed(id uint, at time.Time) error
+	Delete(id uint) error
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "testing"
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		want    string
+		wantErr bool
+	}{
+		{"single scope", []string{"habits:read"}, "habits:read", false},
+		{"ordered and deduplicated", []string{"completions:write", " Habits:Read", "habits:read"}, "habits:read,completions:write", false},
+		{"unknown scope", []string{"habits:read", "admin"}, "", true},
+		{"no scopes", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseScopes(tt.scopes)
+		if tt.wantErr && err == nil {
+			t.Errorf("%s: expected validation error, got nil", tt.name)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: expected no validation error, got %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestAccessTokenHasScope(t *testing.T) {
+	token := AccessToken{Scopes: "habits:read,completions:write"}
+
+	if !token.HasScope(ScopeHabitsRead) || !token.HasScope(ScopeCompletionsWrite) {
+		t.Errorf("expected token to have its scopes")
+	}
+	if token.HasScope(ScopeHabitsWrite) {
+		t.Errorf("expected token not to have habits:write")
+	}
+	if (AccessToken{}).HasScope("") {
+		t.Errorf("expected a token without scopes to have none")
+	}
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxChallengeDays bounds a challenge's window, long challenges are better
+// tracked as regular habits.
+const maxChallengeDays = 366
+
+// Challenge is a habit a group commits to for a date window, such as walking
+// 10k steps daily in November. Each participant tracks the shared habit
+// definition as a habit of their own, created when they join, and only
+// completions within the window count towards the leaderboard. Dates are civil
+// dates in the configured timezone, stored at midnight UTC.
+type Challenge struct {
+	ID             uint      `gorm:"primaryKey"`
+	CreatorID      uint      `gorm:"not null;index"`
+	Name           string    `gorm:"not null"`
+	Description    string    `gorm:"not null;default:''"`
+	Frequency      string    `gorm:"not null"`
+	TimesPerPeriod int       `gorm:"not null;default:0"`
+	Weekdays       string    `gorm:"not null;default:''"`
+	MonthDays      string    `gorm:"not null;default:''"`
+	IntervalDays   int       `gorm:"not null;default:0"`
+	RRule          string    `gorm:"column:rrule;not null;default:''"`
+	TargetValue    float64   `gorm:"not null;default:0"`
+	Unit           string    `gorm:"not null;default:''"`
+	StartDate      time.Time `gorm:"type:date;not null;index"`
+	EndDate        time.Time `gorm:"type:date;not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+// ChallengeParticipant links a user who joined a challenge to the habit they
+// track it with.
+type ChallengeParticipant struct {
+	ID          uint      `gorm:"primaryKey"`
+	ChallengeID uint      `gorm:"not null;uniqueIndex:idx_challenge_participants_challenge_user"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_challenge_participants_challenge_user;index"`
+	HabitID     uint      `gorm:"not null;index"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// ValidateChallenge checks the challenge's name, date window and habit
+// definition, normalising the schedule as for a habit.
+func ValidateChallenge(challenge *Challenge) error {
+	challenge.Name = strings.TrimSpace(challenge.Name)
+	if challenge.Name == "" {
+		return fmt.Errorf("challenge name cannot be empty")
+	}
+
+	if challenge.StartDate.IsZero() || challenge.EndDate.IsZero() {
+		return fmt.Errorf("challenge start and end dates are required")
+	}
+	challenge.StartDate = civilDate(challenge.StartDate)
+	challenge.EndDate = civilDate(challenge.EndDate)
+	if challenge.EndDate.Before(challenge.StartDate) {
+		return fmt.Errorf("challenge end date cannot be before its start date")
+	}
+	if civilDays(challenge.EndDate.Date())-civilDays(challenge.StartDate.Date()) >= maxChallengeDays {
+		return fmt.Errorf("challenge cannot be longer than %d days", maxChallengeDays)
+	}
+
+	habit := challenge.Habit(0)
+	if err := ValidateSchedule(&habit); err != nil {
+		return err
+	}
+	if !IsValidFrequency(habit.Frequency) {
+		return fmt.Errorf("invalid frequency type: %s", habit.Frequency)
+	}
+
+	challenge.Frequency = habit.Frequency
+	challenge.Weekdays = habit.Weekdays
+	challenge.MonthDays = habit.MonthDays
+	challenge.RRule = habit.RRule
+	challenge.Unit = habit.Unit
+	return nil
+}
+
+// Habit returns the habit a participant tracks the challenge with.
+func (challenge Challenge) Habit(userID uint) Habit {
+	return Habit{
+		UserID:         userID,
+		Name:           challenge.Name,
+		Frequency:      challenge.Frequency,
+		Kind:           string(Build),
+		TimesPerPeriod: challenge.TimesPerPeriod,
+		Weekdays:       challenge.Weekdays,
+		MonthDays:      challenge.MonthDays,
+		IntervalDays:   challenge.IntervalDays,
+		RRule:          challenge.RRule,
+		TargetValue:    challenge.TargetValue,
+		Unit:           challenge.Unit,
+	}
+}
+
+// Window returns the instants the challenge starts and ends, as the start of
+// its first day and of the day after its last, under the given settings.
+func (challenge Challenge) Window(settings Settings) (time.Time, time.Time) {
+	start, end := challenge.StartDate, challenge.EndDate.AddDate(0, 0, 1)
+	return settings.DayStart(start.Date()), settings.DayStart(end.Date())
+}
+
+// HasEnded reports whether the challenge's last day is over at now.
+func (challenge Challenge) HasEnded(settings Settings, now time.Time) bool {
+	_, end := challenge.Window(settings)
+	return !now.Before(end)
+}
@@ -0,0 +1,13 @@
+package domain
+
+type ChallengeRepository interface {
+	Create(c *Challenge) error
+	GetAll() ([]Challenge, error)
+	GetByID(id uint) (*Challenge, error)
+	Delete(id uint) error
+
+	Join(p *ChallengeParticipant, habit *Habit) error
+	Leave(challengeID, userID uint) error
+	GetParticipant(challengeID, userID uint) (*ChallengeParticipant, error)
+	GetParticipants(challengeID uint) ([]ChallengeParticipant, error)
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateChallenge(t *testing.T) {
+	date := func(value string) time.Time {
+		d, _ := time.Parse("2006-01-02", value)
+		return d
+	}
+
+	tests := []struct {
+		name          string
+		challenge     Challenge
+		wantFrequency string
+		wantErr       string
+	}{
+		{
+			name:          "daily step goal",
+			challenge:     Challenge{Name: " Walk 10k ", Frequency: "daily", TargetValue: 10000, Unit: "steps", StartDate: date("2026-11-01"), EndDate: date("2026-11-30")},
+			wantFrequency: "daily",
+		},
+		{
+			name:          "frequency from rrule",
+			challenge:     Challenge{Name: "Gym", RRule: "FREQ=WEEKLY;BYDAY=MO,TH", StartDate: date("2026-11-01"), EndDate: date("2026-11-01")},
+			wantFrequency: "weekly",
+		},
+		{
+			name:      "missing name",
+			challenge: Challenge{Frequency: "daily", StartDate: date("2026-11-01"), EndDate: date("2026-11-30")},
+			wantErr:   "challenge name cannot be empty",
+		},
+		{
+			name:      "missing dates",
+			challenge: Challenge{Name: "Walk", Frequency: "daily", StartDate: date("2026-11-01")},
+			wantErr:   "challenge start and end dates are required",
+		},
+		{
+			name:      "ends before it starts",
+			challenge: Challenge{Name: "Walk", Frequency: "daily", StartDate: date("2026-11-30"), EndDate: date("2026-11-01")},
+			wantErr:   "challenge end date cannot be before its start date",
+		},
+		{
+			name:      "too long",
+			challenge: Challenge{Name: "Walk", Frequency: "daily", StartDate: date("2026-01-01"), EndDate: date("2027-01-02")},
+			wantErr:   "challenge cannot be longer than 366 days",
+		},
+		{
+			name:      "invalid schedule",
+			challenge: Challenge{Name: "Walk", Frequency: "daily", Weekdays: "mon", StartDate: date("2026-11-01"), EndDate: date("2026-11-30")},
+			wantErr:   "weekdays can only be set on weekly habits",
+		},
+		{
+			name:      "invalid frequency",
+			challenge: Challenge{Name: "Walk", Frequency: "hourly", StartDate: date("2026-11-01"), EndDate: date("2026-11-30")},
+			wantErr:   "invalid frequency type: hourly",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge := tt.challenge
+			err := ValidateChallenge(&challenge)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("expected %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if challenge.Frequency != tt.wantFrequency {
+				t.Errorf("expected %q, got %q", tt.wantFrequency, challenge.Frequency)
+			}
+			if challenge.Name != strings.TrimSpace(tt.challenge.Name) {
+				t.Errorf("expected %q, got %q", strings.TrimSpace(tt.challenge.Name), challenge.Name)
+			}
+		})
+	}
+}
+
+func TestChallengeWindow(t *testing.T) {
+	settings := Settings{Timezone: "Europe/London", DayEndHour: 3}
+	challenge := Challenge{StartDate: time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, time.November, 30, 0, 0, 0, 0, time.UTC)}
+	loc := settings.Location()
+
+	start, end := challenge.Window(settings)
+	if want := time.Date(2026, time.November, 1, 3, 0, 0, 0, loc); !start.Equal(want) {
+		t.Errorf("expected %v, got %v", want, start)
+	}
+	if want := time.Date(2026, time.December, 1, 3, 0, 0, 0, loc); !end.Equal(want) {
+		t.Errorf("expected %v, got %v", want, end)
+	}
+
+	// 01:00 on the 1st of December still counts towards the 30th of November
+	if challenge.HasEnded(settings, time.Date(2026, time.December, 1, 1, 0, 0, 0, loc)) {
+		t.Error("expected the challenge to still be running")
+	}
+	if !challenge.HasEnded(settings, time.Date(2026, time.December, 1, 3, 0, 0, 0, loc)) {
+		t.Error("expected the challenge to have ended")
+	}
+}
+
+func TestChallengeHabit(t *testing.T) {
+	challenge := Challenge{Name: "Walk 10k", Frequency: "daily", TargetValue: 10000, Unit: "steps"}
+	habit := challenge.Habit(7)
+	if habit.UserID != 7 || habit.Name != "Walk 10k" || habit.Kind != string(Build) || habit.TargetValue != 10000 || habit.Unit != "steps" {
+		t.Errorf("expected the challenge's habit definition, got %+v", habit)
+	}
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// HabitCompletion records a single time a habit was marked as completed. Quantity
+// is the amount logged towards a measurable habit's target, e.g. 3 glasses, and
+// Strength the habit's strength score once the completion was recorded.
+type HabitCompletion struct {
+	ID          uint      `gorm:"primaryKey"`
+	HabitID     uint      `gorm:"not null;index"`
+	CompletedAt time.Time `gorm:"not null;index"`
+	Quantity    float64   `gorm:"not null;default:1"`
+	Strength    float64   `gorm:"not null;default:0"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+type FreezeEventType string
+
+const (
+	FreezeEarned FreezeEventType = "earned"
+	FreezeUsed   FreezeEventType = "used"
+)
+
+// FreezeEvent records a streak freeze being earned at the end of a run of
+// completed periods, or spent to bridge the missed period starting at PeriodStart.
+type FreezeEvent struct {
+	Type        FreezeEventType
+	PeriodStart time.Time
+}
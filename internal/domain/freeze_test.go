@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreakEngineFreezes(t *testing.T) {
+	settings := DefaultSettings()
+	settings.FreezeEarnInterval = 2
+	settings.MaxStreakFreezes = 1
+
+	tests := []struct {
+		name        string
+		completions []time.Time
+		now         time.Time
+		wantStreak  int
+		wantFreezes int
+		wantHistory []FreezeEvent
+	}{
+		{
+			name: "freeze is earned after a run of completed periods",
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 2, 8),
+			},
+			now:         date(2024, time.January, 2, 9),
+			wantStreak:  2,
+			wantFreezes: 1,
+			wantHistory: []FreezeEvent{{Type: FreezeEarned, PeriodStart: date(2024, time.January, 2, 0)}},
+		},
+		{
+			name: "freeze bridges a single missed day",
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 2, 8),
+				date(2024, time.January, 4, 8),
+			},
+			now:         date(2024, time.January, 4, 9),
+			wantStreak:  3,
+			wantFreezes: 0,
+			wantHistory: []FreezeEvent{
+				{Type: FreezeEarned, PeriodStart: date(2024, time.January, 2, 0)},
+				{Type: FreezeUsed, PeriodStart: date(2024, time.January, 3, 0)},
+			},
+		},
+		{
+			name: "freeze keeps the streak current until the next day is missed",
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 2, 8),
+			},
+			now:         date(2024, time.January, 4, 9),
+			wantStreak:  2,
+			wantFreezes: 0,
+			wantHistory: []FreezeEvent{
+				{Type: FreezeEarned, PeriodStart: date(2024, time.January, 2, 0)},
+				{Type: FreezeUsed, PeriodStart: date(2024, time.January, 3, 0)},
+			},
+		},
+		{
+			name: "freeze does not bridge two missed days",
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 2, 8),
+				date(2024, time.January, 5, 8),
+			},
+			now:         date(2024, time.January, 5, 9),
+			wantStreak:  1,
+			wantFreezes: 1,
+			wantHistory: []FreezeEvent{{Type: FreezeEarned, PeriodStart: date(2024, time.January, 2, 0)}},
+		},
+		{
+			name: "no freeze without a completed run",
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 3, 8),
+			},
+			now:         date(2024, time.January, 3, 9),
+			wantStreak:  1,
+			wantFreezes: 0,
+		},
+		{
+			name: "freezes are capped",
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 2, 8),
+				date(2024, time.January, 3, 8),
+				date(2024, time.January, 4, 8),
+			},
+			now:         date(2024, time.January, 4, 9),
+			wantStreak:  4,
+			wantFreezes: 1,
+			wantHistory: []FreezeEvent{{Type: FreezeEarned, PeriodStart: date(2024, time.January, 2, 0)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewStreakEngine(Schedule{Frequency: Daily}, settings)
+
+			result := engine.Calculate(completionsAt(tt.completions...), tt.now)
+			if result.CurrentStreak != tt.wantStreak {
+				t.Errorf("Expected CurrentStreak to be %d, got %d", tt.wantStreak, result.CurrentStreak)
+			}
+			if result.Freezes != tt.wantFreezes {
+				t.Errorf("Expected Freezes to be %d, got %d", tt.wantFreezes, result.Freezes)
+			}
+			if len(result.FreezeHistory) != len(tt.wantHistory) {
+				t.Fatalf("Expected %d freeze events, got %v", len(tt.wantHistory), result.FreezeHistory)
+			}
+			for i, event := range tt.wantHistory {
+				got := result.FreezeHistory[i]
+				if got.Type != event.Type || !got.PeriodStart.Equal(event.PeriodStart) {
+					t.Errorf("Expected freeze event %d to be %v, got %v", i, event, got)
+				}
+			}
+		})
+	}
+}
+
+func TestStreakEngineFreezesDisabled(t *testing.T) {
+	settings := DefaultSettings()
+	settings.FreezeEarnInterval = 0
+
+	engine := NewStreakEngine(Schedule{Frequency: Daily}, settings)
+	completions := completionsAt(
+		date(2024, time.January, 1, 8),
+		date(2024, time.January, 2, 8),
+		date(2024, time.January, 4, 8),
+	)
+
+	result := engine.Calculate(completions, date(2024, time.January, 4, 9))
+	if result.CurrentStreak != 1 || result.Freezes != 0 {
+		t.Errorf("Expected streak 1 without freezes, got %d with %d freezes", result.CurrentStreak, result.Freezes)
+	}
+}
@@ -3,12 +3,34 @@ package domain
 import "time"
 
 type Habit struct {
-	ID               uint   `gorm:"primaryKey"`
-	Name             string `gorm:"not null"`
-	Frequency        string `gorm:"not null"`
-	CurrentStreak    int
+	ID               uint       `gorm:"primaryKey"`
+	UserID           uint       `gorm:"index"` // owner
+	User             *User      `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Name             string     `gorm:"not null"`
+	Frequency        string     `gorm:"not null"`
+	Kind             string     `gorm:"not null;default:'build'"` // "build" or "quit"
+	TimesPerPeriod   int        `gorm:"not null;default:0"`
+	Weekdays         string     `gorm:"not null;default:''"`              // weekly habits, e.g. "mon,wed,fri"
+	MonthDays        string     `gorm:"not null;default:''"`              // monthly habits, e.g. "1,15"
+	IntervalDays     int        `gorm:"not null;default:0"`               // daily habits, e.g. 2 for every other day
+	RRule            string     `gorm:"column:rrule;not null;default:''"` // RFC 5545 recurrence rule, e.g. "FREQ=WEEKLY;BYDAY=MO,TH"
+	TargetValue      float64    `gorm:"not null;default:0"`               // measurable habits, e.g. 8 for "drink 8 glasses"
+	Unit             string     `gorm:"not null;default:''"`              // measurable habits, e.g. "glasses"
+	CurrentStreak    int        // days clean for quit habits
+	LongestStreak    int        `gorm:"not null;default:0"`
+	Strength         float64    `gorm:"not null;default:0"` // habit strength score, 0-100
 	LastCompletedAt  *time.Time // Use pointer to handle null values
 	TotalCompletions int
-	CreatedAt        time.Time `gorm:"autoCreateTime"`
-	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+	StreakFreezes    int        `gorm:"not null;default:0"` // freezes available to bridge a missed period
+	LastRelapseAt    *time.Time // quit habits only
+	CreatedAt        time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime"`
+
+	NextDueAt       *time.Time      `gorm:"-"` // start of the earliest period still to be completed
+	NextOccurrences []time.Time     `gorm:"-"` // start of the upcoming periods
+	PeriodProgress  float64         `gorm:"-"` // completions, or quantity for measurable habits, logged this period
+	FreezeHistory   []FreezeEvent   `gorm:"-"` // streak freezes earned and spent
+	StreakRuns      []StreakRun     `gorm:"-"` // past streaks, oldest first, saved with the habit unless nil
+	StrengthHistory []StrengthPoint `gorm:"-"` // strength after each recent period, oldest first
+	Role            HabitRole       `gorm:"-"` // the requesting user's access, as owner or partner
 }
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// HabitRole is a user's access to a habit. The owner is the habit's UserID and
+// has no membership, partners are invited as viewers or co-owners.
+type HabitRole string
+
+const (
+	RoleOwner   HabitRole = "owner"
+	RoleCoOwner HabitRole = "co-owner"
+	RoleViewer  HabitRole = "viewer"
+)
+
+const (
+	maxReactionLength = 16 // runes, enough for an emoji sequence
+	maxCommentLength  = 500
+)
+
+// HabitMember gives an accountability partner access to someone else's habit.
+// Viewers see its streak and history and can react to and comment on
+// completions, co-owners can also edit and complete it.
+type HabitMember struct {
+	ID        uint      `gorm:"primaryKey"`
+	HabitID   uint      `gorm:"not null;uniqueIndex:idx_habit_members_habit_user"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_habit_members_habit_user;index"`
+	Role      HabitRole `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// CompletionReaction is a partner's emoji reaction to a completion, at most one
+// of each emoji per user.
+type CompletionReaction struct {
+	ID           uint      `gorm:"primaryKey"`
+	CompletionID uint      `gorm:"not null;uniqueIndex:idx_completion_reactions_user_emoji"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_completion_reactions_user_emoji"`
+	Emoji        string    `gorm:"not null;uniqueIndex:idx_completion_reactions_user_emoji"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// CompletionComment is a note left on a completion by its habit's owner or a partner.
+type CompletionComment struct {
+	ID           uint      `gorm:"primaryKey"`
+	CompletionID uint      `gorm:"not null;index"`
+	UserID       uint      `gorm:"not null;index"`
+	Body         string    `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// ParseMemberRole validates the role a partner is invited with. Ownership
+// cannot be given away.
+func ParseMemberRole(role string) (HabitRole, error) {
+	switch HabitRole(role) {
+	case RoleViewer, RoleCoOwner:
+		return HabitRole(role), nil
+	case "":
+		return RoleViewer, nil
+	default:
+		return "", fmt.Errorf("invalid role: %s", role)
+	}
+}
+
+// CanEdit reports whether the role may change the habit and its history.
+func (r HabitRole) CanEdit() bool {
+	return r == RoleOwner || r == RoleCoOwner
+}
+
+// ValidateReaction checks a reaction is a short token such as an emoji.
+func ValidateReaction(emoji string) error {
+	if emoji == "" || strings.IndexFunc(emoji, unicode.IsSpace) >= 0 || utf8.RuneCountInString(emoji) > maxReactionLength {
+		return fmt.Errorf("invalid reaction")
+	}
+	return nil
+}
+
+// NormalizeComment trims a comment and checks it is not empty or too long.
+func NormalizeComment(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("comment cannot be empty")
+	}
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		return "", fmt.Errorf("comment must be at most %d characters", maxCommentLength)
+	}
+	return body, nil
+}
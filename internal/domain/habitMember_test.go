@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMemberRole(t *testing.T) {
+	tests := []struct {
+		role    string
+		want    HabitRole
+		wantErr bool
+	}{
+		{role: "", want: RoleViewer},
+		{role: "viewer", want: RoleViewer},
+		{role: "co-owner", want: RoleCoOwner},
+		{role: "owner", wantErr: true},
+		{role: "admin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMemberRole(tt.role)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expected an error for role %q", tt.role)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("expected %q, got %q (%v)", tt.want, got, err)
+		}
+	}
+}
+
+func TestValidateReaction(t *testing.T) {
+	for _, emoji := range []string{"🔥", "👍🏽", "+1"} {
+		if err := ValidateReaction(emoji); err != nil {
+			t.Errorf("expected %q to be valid, got %v", emoji, err)
+		}
+	}
+	for _, emoji := range []string{"", "great job", strings.Repeat("🔥", 17)} {
+		if err := ValidateReaction(emoji); err == nil {
+			t.Errorf("expected %q to be invalid", emoji)
+		}
+	}
+}
+
+func TestNormalizeComment(t *testing.T) {
+	got, err := NormalizeComment("  Keep it up!\n")
+	if err != nil || got != "Keep it up!" {
+		t.Errorf("expected %q, got %q (%v)", "Keep it up!", got, err)
+	}
+
+	if _, err := NormalizeComment("   "); err == nil {
+		t.Error("expected an empty comment to be rejected")
+	}
+	if _, err := NormalizeComment(strings.Repeat("a", 501)); err == nil {
+		t.Error("expected a long comment to be rejected")
+	}
+}
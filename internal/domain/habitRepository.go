@@ -1,11 +1,26 @@
 package domain
 
+import "time"
+
 type HabitRepository interface {
 	Create(h *Habit) error
 	GetByID(id uint) (*Habit, error)
+	GetByIDForUser(id, userID uint) (*Habit, error)
+	GetByIDForMember(id, userID uint) (*Habit, HabitRole, error)
 	GetAll() ([]Habit, error)
+	GetAllForUser(userID uint) ([]Habit, error)
+	GetAllSharedWithUser(userID uint) ([]Habit, error)
 	Update(h *Habit) error
 	Delete(id uint) error
 	SafeUpdate(h *Habit) error
 	GetStreaks() ([]Habit, error)
+	GetStreaksForUser(userID uint) ([]Habit, error)
+	AddCompletion(h *Habit, c *HabitCompletion) error
+	AddCompletions(habits []*Habit, completions []*HabitCompletion) error
+	GetCompletions(habitID uint) ([]HabitCompletion, error)
+	GetCompletion(id uint) (*HabitCompletion, error)
+	RemoveCompletions(h *Habit, from, to time.Time) error
+	AddRelapse(h *Habit, r *Relapse) error
+	GetRelapses(habitID uint) ([]Relapse, error)
+	GetStreakRuns(habitID uint) ([]StreakRun, error)
 }
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxHeatmapDays bounds a heatmap's range to a little over a year view
+const maxHeatmapDays = 371
+
+// Heatmap holds one entry per day from From to To, inclusive, for rendering a
+// calendar view. Completions counts the completions logged on each day, Due the
+// habits with a period starting that day and Skipped the habits with the day skipped.
+type Heatmap struct {
+	From        string // YYYY-MM-DD
+	To          string // YYYY-MM-DD
+	Completions []int
+	Due         []int
+	Skipped     []int
+
+	from time.Time
+}
+
+// NewHeatmap returns an empty heatmap for the days from to to, given as dates.
+func NewHeatmap(from, to time.Time) (*Heatmap, error) {
+	from, to = civilDate(from), civilDate(to)
+	if to.Before(from) {
+		return nil, fmt.Errorf("heatmap end date cannot be before its start date")
+	}
+
+	days := civilDays(to.Date()) - civilDays(from.Date()) + 1
+	if days > maxHeatmapDays {
+		return nil, fmt.Errorf("heatmap cannot span more than %d days", maxHeatmapDays)
+	}
+
+	return &Heatmap{
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		Completions: make([]int, days),
+		Due:         make([]int, days),
+		Skipped:     make([]int, days),
+		from:        from,
+	}, nil
+}
+
+// Add counts a habit's completions and scheduled days into the heatmap, with
+// days bucketed in the engine's location. Days before since are never due.
+func (heatmap *Heatmap) Add(engine StreakEngine, completions []HabitCompletion, since time.Time) {
+	for _, completion := range completions {
+		if i := heatmap.index(civilDate(engine.localDay(completion.CompletedAt))); i >= 0 {
+			heatmap.Completions[i]++
+		}
+	}
+
+	first := civilDate(engine.localDay(since))
+	for i := range heatmap.Due {
+		day := heatmap.from.AddDate(0, 0, i)
+		switch {
+		case day.Before(first):
+		case engine.isSkippedDay(day):
+			heatmap.Skipped[i]++
+		case engine.startsPeriod(day):
+			heatmap.Due[i]++
+		}
+	}
+}
+
+// startsPeriod reports whether a period of the schedule starts on day, given as a date.
+func (engine StreakEngine) startsPeriod(day time.Time) bool {
+	if engine.RRule != nil {
+		if day.Before(engine.ruleStart) || (!engine.ruleEnd.IsZero() && day.After(engine.ruleEnd)) {
+			return false
+		}
+	}
+
+	start := engine.dayStart(day.Date())
+	return engine.PeriodStart(start).Equal(start)
+}
+
+func (heatmap *Heatmap) index(day time.Time) int {
+	i := civilDays(day.Date()) - civilDays(heatmap.from.Date())
+	if i < 0 || i >= len(heatmap.Completions) {
+		return -1
+	}
+	return i
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewHeatmap(t *testing.T) {
+	heatmap, err := NewHeatmap(date(2024, time.January, 1, 0), date(2024, time.December, 31, 0))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(heatmap.Completions) != 366 || heatmap.From != "2024-01-01" || heatmap.To != "2024-12-31" {
+		t.Errorf("Expected a leap year of days, got %d days from %s to %s", len(heatmap.Completions), heatmap.From, heatmap.To)
+	}
+
+	if _, err := NewHeatmap(date(2024, time.January, 2, 0), date(2024, time.January, 1, 0)); err == nil {
+		t.Errorf("Expected an error for an end date before the start date")
+	}
+	if _, err := NewHeatmap(date(2022, time.January, 1, 0), date(2024, time.January, 1, 0)); err == nil {
+		t.Errorf("Expected an error for a range longer than a year view")
+	}
+}
+
+func TestHeatmapAdd(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Timezone = "America/New_York"
+
+	heatmap, _ := NewHeatmap(date(2024, time.January, 1, 0), date(2024, time.January, 14, 0))
+
+	engine := NewStreakEngine(Schedule{Frequency: Weekly}, settings)
+	engine.Skips = []Skip{{StartDate: date(2024, time.January, 8, 0), EndDate: date(2024, time.January, 8, 0)}}
+	completions := completionsAt(
+		date(2024, time.January, 2, 15),
+		date(2024, time.January, 3, 2), // still January 2nd in New York
+		date(2024, time.January, 10, 15),
+	)
+	heatmap.Add(engine, completions, date(2023, time.December, 1, 0))
+
+	if heatmap.Completions[1] != 2 || heatmap.Completions[9] != 1 || heatmap.Completions[2] != 0 {
+		t.Errorf("Expected completions bucketed by local day, got %v", heatmap.Completions)
+	}
+	if heatmap.Due[0] != 1 || heatmap.Due[1] != 0 || heatmap.Due[7] != 0 {
+		t.Errorf("Expected only the start of each unskipped week to be due, got %v", heatmap.Due)
+	}
+	if heatmap.Skipped[7] != 1 {
+		t.Errorf("Expected the skipped day to be marked, got %v", heatmap.Skipped)
+	}
+
+	// A second habit, tracked since January 10th, adds to the counts
+	heatmap.Add(NewStreakEngine(Schedule{Frequency: Daily}, settings), nil, date(2024, time.January, 10, 17))
+	if heatmap.Due[8] != 0 || heatmap.Due[9] != 1 || heatmap.Due[13] != 1 {
+		t.Errorf("Expected days before the habit was tracked not to be due, got %v", heatmap.Due)
+	}
+}
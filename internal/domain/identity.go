@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+)
+
+// UserIdentity links a user to their account at an external OpenID Connect
+// provider, identified by the provider's issuer and its subject for the user.
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	Issuer    string    `gorm:"not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	Subject   string    `gorm:"not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// ExternalIdentity is who an identity provider says signed in, taken from a
+// verified ID token.
+type ExternalIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// IdentityProvider signs users in with the OpenID Connect authorization code
+// flow, protected with PKCE.
+type IdentityProvider interface {
+	// AuthCodeURL returns the provider's login page to send the user to.
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems the code the provider sent the user back with and returns
+	// the identity from its ID token, once the token has been verified.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error)
+}
+
+// CodeChallenge returns the PKCE S256 challenge for a code verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
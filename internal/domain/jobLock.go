@@ -0,0 +1,8 @@
+package domain
+
+// JobLock runs background jobs so that only one app instance runs a given job at a time.
+type JobLock interface {
+	// TryRun runs fn while holding the lock identified by key. It reports false
+	// without running fn if another instance holds the lock.
+	TryRun(key int64, fn func() error) (bool, error)
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "fmt"
+
+// HabitKind tells whether a habit is about doing something (build) or stopping
+// something (quit). A quit habit's streak is the number of days since its last relapse.
+type HabitKind string
+
+const (
+	Build HabitKind = "build"
+	Quit  HabitKind = "quit"
+)
+
+func IsValidHabitKind(kind string) bool {
+	return kind == string(Build) || kind == string(Quit)
+}
+
+// ValidateKind checks the habit's kind, defaulting it to build. Quit habits are
+// tracked per day, so they default to a daily frequency and cannot have a
+// schedule or a target.
+func ValidateKind(habit *Habit) error {
+	if habit.Kind == "" {
+		habit.Kind = string(Build)
+	}
+	if !IsValidHabitKind(habit.Kind) {
+		return fmt.Errorf("invalid habit kind: %s", habit.Kind)
+	}
+	if habit.Kind != string(Quit) {
+		return nil
+	}
+
+	if habit.Frequency == "" {
+		habit.Frequency = string(Daily)
+	}
+	if habit.Frequency != string(Daily) {
+		return fmt.Errorf("quit habits only support a daily frequency")
+	}
+	if habit.TimesPerPeriod > 0 || habit.Weekdays != "" || habit.MonthDays != "" ||
+		habit.IntervalDays > 1 || habit.RRule != "" || habit.TargetValue > 0 || habit.Unit != "" {
+		return fmt.Errorf("quit habits cannot have a schedule or target")
+	}
+	return nil
+}
+
+// IsQuit reports whether the habit tracks abstinence rather than completions.
+func (habit Habit) IsQuit() bool {
+	return habit.Kind == string(Quit)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "testing"
+
+func TestValidateKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		habit   Habit
+		wantErr bool
+	}{
+		{"defaults to build", Habit{Frequency: "weekly", Weekdays: "mon"}, false},
+		{"quit habit", Habit{Kind: "quit"}, false},
+		{"unknown kind", Habit{Kind: "maybe", Frequency: "daily"}, true},
+		{"weekly quit habit", Habit{Kind: "quit", Frequency: "weekly"}, true},
+		{"quit habit with target", Habit{Kind: "quit", TargetValue: 3}, true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateKind(&tt.habit)
+		if tt.wantErr && err == nil {
+			t.Errorf("%s: expected validation error, got nil", tt.name)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: expected no validation error, got %v", tt.name, err)
+		}
+	}
+
+	habit := Habit{Kind: "quit"}
+	_ = ValidateKind(&habit)
+	if habit.Frequency != string(Daily) {
+		t.Errorf("Expected quit habit to default to daily, got %s", habit.Frequency)
+	}
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Relapse records a slip on a quit habit, which resets its days clean.
+type Relapse struct {
+	ID         uint      `gorm:"primaryKey"`
+	HabitID    uint      `gorm:"not null;index"`
+	OccurredAt time.Time `gorm:"not null;index"`
+	Note       string    `gorm:"not null;default:''"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
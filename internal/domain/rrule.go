@@ -0,0 +1,332 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RRule is the subset of an RFC 5545 recurrence rule supported for habit
+// schedules: FREQ (DAILY, WEEKLY or MONTHLY), INTERVAL, BYDAY, BYMONTHDAY,
+// COUNT, UNTIL and WKST. Occurrences are whole days, and each occurrence starts
+// a period that lasts until the next one.
+type RRule struct {
+	Frequency  Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+	WeekStart  *time.Weekday
+}
+
+const maxRRuleCount = 1000
+
+// maxRuleSearchDays bounds the days searched for an occurrence of a recurrence
+// rule, and in total for the last occurrence of a COUNT rule. Only days within
+// the rule's intervals count, so sparse rules are not scanned day by day.
+const maxRuleSearchDays = 20000
+
+// maxFirstOccurrenceDays bounds how far, in the same days, a rule's first
+// occurrence may be from its start.
+const maxFirstOccurrenceDays = 1000
+
+var rruleFrequencies = map[string]Frequency{
+	"DAILY":   Daily,
+	"WEEKLY":  Weekly,
+	"MONTHLY": Monthly,
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// ParseRRule parses and validates a recurrence rule such as
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR". An "RRULE:" prefix is allowed.
+func ParseRRule(value string) (*RRule, error) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "RRULE:")
+	rule := &RRule{Interval: 1}
+
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("invalid rrule part: %s", part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate rrule part: %s", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			frequency, ok := rruleFrequencies[val]
+			if !ok {
+				return nil, fmt.Errorf("unsupported rrule frequency: %s", val)
+			}
+			rule.Frequency = frequency
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 || interval > 366 {
+				return nil, fmt.Errorf("invalid rrule interval: %s", val)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, name := range strings.Split(val, ",") {
+				weekday, ok := rruleWeekdays[name]
+				if !ok {
+					return nil, fmt.Errorf("invalid rrule weekday: %s", name)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, part := range strings.Split(val, ",") {
+				day, err := strconv.Atoi(part)
+				if err != nil || day == 0 || day < -31 || day > 31 {
+					return nil, fmt.Errorf("invalid rrule month day: %s", part)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, day)
+			}
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 || count > maxRRuleCount {
+				return nil, fmt.Errorf("invalid rrule count: %s", val)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := time.Parse("20060102T150405Z", val)
+			if err != nil {
+				until, err = time.Parse("20060102", val)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid rrule until: %s", val)
+			}
+			rule.Until = &until
+		case "WKST":
+			weekday, ok := rruleWeekdays[val]
+			if !ok {
+				return nil, fmt.Errorf("invalid rrule week start: %s", val)
+			}
+			rule.WeekStart = &weekday
+		default:
+			return nil, fmt.Errorf("unsupported rrule part: %s", key)
+		}
+	}
+
+	if rule.Frequency == "" {
+		return nil, fmt.Errorf("rrule must specify FREQ")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("rrule cannot specify both COUNT and UNTIL")
+	}
+	return rule, nil
+}
+
+// String returns the rule in canonical form.
+func (rule RRule) String() string {
+	parts := []string{"FREQ=" + strings.ToUpper(string(rule.Frequency))}
+	if rule.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(rule.Interval))
+	}
+	if len(rule.ByDay) > 0 {
+		days := make([]string, len(rule.ByDay))
+		for i, weekday := range rule.ByDay {
+			days[i] = strings.ToUpper(weekday.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(rule.ByMonthDay) > 0 {
+		days := make([]string, len(rule.ByMonthDay))
+		for i, day := range rule.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if rule.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(rule.Count))
+	}
+	if rule.Until != nil {
+		parts = append(parts, "UNTIL="+rule.Until.UTC().Format("20060102T150405Z"))
+	}
+	if rule.WeekStart != nil {
+		parts = append(parts, "WKST="+strings.ToUpper(rule.WeekStart.String()[:2]))
+	}
+	return strings.Join(parts, ";")
+}
+
+// HasOccurrence reports whether the rule occurs within maxFirstOccurrenceDays
+// of the civil date start, taking weeks to start on weekStart.
+func (rule RRule) HasOccurrence(start time.Time, weekStart time.Weekday) bool {
+	start = civilDate(start)
+	_, _, ok := rule.search(start, start, weekStart, true, maxFirstOccurrenceDays)
+	return ok && (rule.Until == nil || !civilDate(rule.Until.UTC()).Before(start))
+}
+
+// search returns the first occurrence on or after day (or on or before it
+// when searching backwards) of a series that starts on start, looking at no
+// more than limit days within the rule's intervals. It also returns the number
+// of days it looked at.
+func (rule RRule) search(start, day time.Time, weekStart time.Weekday, forward bool, limit int) (time.Time, int, bool) {
+	if forward && day.Before(start) {
+		day = start
+	}
+	step := 1
+	if !forward {
+		step = -1
+	}
+
+	examined := 0
+	for examined < limit && !day.Before(start) {
+		if aligned := rule.align(start, day, weekStart, forward); !aligned.Equal(day) {
+			day = aligned
+			continue
+		}
+		examined++
+		if rule.occursOn(start, day, weekStart) {
+			return day, examined, true
+		}
+		day = day.AddDate(0, 0, step)
+	}
+	return time.Time{}, examined, false
+}
+
+// align returns day if it falls within one of the rule's intervals counted
+// from start, otherwise the nearest day in the search direction that does: the
+// first day of the next interval, or the last day of the previous one. day must
+// not be before start.
+func (rule RRule) align(start, day time.Time, weekStart time.Weekday, forward bool) time.Time {
+	interval := rule.interval()
+	round := func(n int) int {
+		if forward {
+			return (n + interval - 1) / interval * interval
+		}
+		return n / interval * interval
+	}
+
+	switch rule.Frequency {
+	case Weekly:
+		if rule.WeekStart != nil {
+			weekStart = *rule.WeekStart
+		}
+		first := civilWeekStart(start, weekStart)
+		weeks := (civilWeekStart(day, weekStart) - first) / 7
+		if weeks%interval == 0 {
+			return day
+		}
+		week := epochDate(first + round(weeks)*7)
+		if forward {
+			return week
+		}
+		return week.AddDate(0, 0, 6)
+	case Monthly:
+		months := (day.Year()-start.Year())*12 + int(day.Month()) - int(start.Month())
+		if months%interval == 0 {
+			return day
+		}
+		month := time.Date(start.Year(), start.Month()+time.Month(round(months)), 1, 0, 0, 0, 0, time.UTC)
+		if forward {
+			return month
+		}
+		return month.AddDate(0, 1, -1)
+	default:
+		days := civilDays(day.Date()) - civilDays(start.Date())
+		if days%interval == 0 {
+			return day
+		}
+		return start.AddDate(0, 0, round(days))
+	}
+}
+
+func (rule RRule) interval() int {
+	if rule.Interval < 1 {
+		return 1
+	}
+	return rule.Interval
+}
+
+// occursOn reports whether the rule has an occurrence on day for a series that
+// starts on start, both given as civil dates at UTC midnight. The series bounds
+// (start, COUNT and UNTIL) are left to the caller.
+func (rule RRule) occursOn(start, day time.Time, weekStart time.Weekday) bool {
+	interval := rule.interval()
+
+	switch rule.Frequency {
+	case Weekly:
+		if rule.WeekStart != nil {
+			weekStart = *rule.WeekStart
+		}
+		weeks := (civilWeekStart(day, weekStart) - civilWeekStart(start, weekStart)) / 7
+		if weeks%interval != 0 {
+			return false
+		}
+		if len(rule.ByDay) == 0 && day.Weekday() != start.Weekday() {
+			return false
+		}
+	case Monthly:
+		months := (day.Year()-start.Year())*12 + int(day.Month()) - int(start.Month())
+		if months%interval != 0 {
+			return false
+		}
+		if len(rule.ByDay) == 0 && len(rule.ByMonthDay) == 0 && day.Day() != start.Day() {
+			return false
+		}
+	default:
+		days := civilDays(day.Date()) - civilDays(start.Date())
+		if days%interval != 0 {
+			return false
+		}
+	}
+
+	if len(rule.ByDay) > 0 && !containsWeekday(rule.ByDay, day.Weekday()) {
+		return false
+	}
+	if len(rule.ByMonthDay) > 0 && !rule.matchesMonthDay(day) {
+		return false
+	}
+	return true
+}
+
+// matchesMonthDay resolves negative BYMONTHDAY values, which count back from the end of the month.
+func (rule RRule) matchesMonthDay(day time.Time) bool {
+	length := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, monthDay := range rule.ByMonthDay {
+		if monthDay < 0 {
+			monthDay = length + 1 + monthDay
+		}
+		if monthDay == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func containsWeekday(weekdays []time.Weekday, weekday time.Weekday) bool {
+	for _, candidate := range weekdays {
+		if candidate == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// epochDate returns the civil date the given number of days after the Unix epoch.
+func epochDate(days int) time.Time {
+	return time.Unix(int64(days)*86400, 0).UTC()
+}
+
+// civilWeekStart returns the civil day number of the start of the week containing day.
+func civilWeekStart(day time.Time, weekStart time.Weekday) int {
+	offset := (int(day.Weekday()) - int(weekStart) + 7) % 7
+	return civilDays(day.Date()) - offset
+}
@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"weekly on weekdays", "FREQ=WEEKLY;BYDAY=MO,WE,FR", "FREQ=WEEKLY;BYDAY=MO,WE,FR", false},
+		{"prefix and lower case", "RRULE:freq=daily;interval=2", "FREQ=DAILY;INTERVAL=2", false},
+		{"monthly with count", "FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=12", "FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=12", false},
+		{"date-only until", "FREQ=DAILY;UNTIL=20241231", "FREQ=DAILY;UNTIL=20241231T000000Z", false},
+		{"missing frequency", "INTERVAL=2", "", true},
+		{"unsupported frequency", "FREQ=HOURLY", "", true},
+		{"unsupported part", "FREQ=DAILY;BYHOUR=8", "", true},
+		{"duplicate part", "FREQ=DAILY;FREQ=WEEKLY", "", true},
+		{"invalid weekday", "FREQ=WEEKLY;BYDAY=XX", "", true},
+		{"invalid month day", "FREQ=MONTHLY;BYMONTHDAY=32", "", true},
+		{"zero interval", "FREQ=DAILY;INTERVAL=0", "", true},
+		{"count and until", "FREQ=DAILY;COUNT=3;UNTIL=20241231", "", true},
+	}
+
+	for _, tt := range tests {
+		rule, err := ParseRRule(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error, got nil", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", tt.name, err)
+			continue
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestStreakEngineRRuleOccurrences(t *testing.T) {
+	anchor := date(2024, time.January, 1, 8) // Monday
+
+	tests := []struct {
+		name  string
+		rule  string
+		now   time.Time
+		count int
+		want  []time.Time
+	}{
+		{
+			name:  "every other week on Monday and Thursday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			now:   date(2024, time.January, 2, 8),
+			count: 4,
+			want: []time.Time{
+				date(2024, time.January, 4, 0),
+				date(2024, time.January, 15, 0),
+				date(2024, time.January, 18, 0),
+				date(2024, time.January, 29, 0),
+			},
+		},
+		{
+			name:  "last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			now:   date(2024, time.January, 2, 8),
+			count: 3,
+			want: []time.Time{
+				date(2024, time.January, 31, 0),
+				date(2024, time.February, 29, 0),
+				date(2024, time.March, 31, 0),
+			},
+		},
+		{
+			name:  "count limits the series",
+			rule:  "FREQ=DAILY;INTERVAL=3;COUNT=3",
+			now:   date(2024, time.January, 1, 9),
+			count: 5,
+			want: []time.Time{
+				date(2024, time.January, 4, 0),
+				date(2024, time.January, 7, 0),
+			},
+		},
+		{
+			name:  "until limits the series",
+			rule:  "FREQ=WEEKLY;UNTIL=20240115",
+			now:   date(2024, time.January, 1, 9),
+			count: 5,
+			want: []time.Time{
+				date(2024, time.January, 8, 0),
+				date(2024, time.January, 15, 0),
+			},
+		},
+		{
+			name:  "every other Monday as a daily rule",
+			rule:  "FREQ=DAILY;INTERVAL=14;BYDAY=MO",
+			now:   date(2024, time.January, 1, 9),
+			count: 2,
+			want: []time.Time{
+				date(2024, time.January, 15, 0),
+				date(2024, time.January, 29, 0),
+			},
+		},
+		{
+			name:  "quarterly on the 15th",
+			rule:  "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=15",
+			now:   date(2024, time.January, 1, 9),
+			count: 3,
+			want: []time.Time{
+				date(2024, time.January, 15, 0),
+				date(2024, time.April, 15, 0),
+				date(2024, time.July, 15, 0),
+			},
+		},
+		{
+			name:  "rule that never occurs",
+			rule:  "FREQ=DAILY;INTERVAL=364;BYDAY=TU;COUNT=1000",
+			now:   date(2024, time.January, 1, 9),
+			count: 3,
+			want:  []time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			engine := NewStreakEngine(Schedule{Frequency: rule.Frequency, RRule: rule, Anchor: anchor}, DefaultSettings())
+
+			got := engine.Occurrences(tt.now, tt.count)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d occurrences, got %v", len(tt.want), got)
+			}
+			for i := range tt.want {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("Expected occurrence %d to be %v, got %v", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStreakEngineRRuleStreak(t *testing.T) {
+	rule, _ := ParseRRule("FREQ=WEEKLY;BYDAY=MO,TH")
+	engine := NewStreakEngine(Schedule{Frequency: Weekly, RRule: rule, Anchor: date(2024, time.January, 1, 8)}, DefaultSettings())
+
+	// Mon 1st, Thu 4th (done on Saturday, still within Thursday's period) and Mon 8th
+	completions := []time.Time{
+		date(2024, time.January, 1, 9),
+		date(2024, time.January, 6, 9),
+		date(2024, time.January, 8, 9),
+	}
+
+	result := engine.Calculate(completionsAt(completions...), date(2024, time.January, 10, 9))
+	if result.CurrentStreak != 3 {
+		t.Errorf("Expected CurrentStreak to be 3, got %d", result.CurrentStreak)
+	}
+
+	result = engine.Calculate(completionsAt(completions...), date(2024, time.January, 16, 9))
+	if result.CurrentStreak != 0 {
+		t.Errorf("Expected streak to lapse after a missed occurrence, got %d", result.CurrentStreak)
+	}
+}
+
+func TestStreakEngineSparseRRuleIsBounded(t *testing.T) {
+	// Starting on a Wednesday, every 364th day is a Wednesday and never a Monday
+	rule, _ := ParseRRule("FREQ=DAILY;INTERVAL=364;BYDAY=MO;COUNT=1000")
+	anchor := date(2024, time.January, 3, 8)
+
+	started := time.Now()
+	engine := NewStreakEngine(Schedule{Frequency: Daily, RRule: rule, Anchor: anchor}, DefaultSettings())
+	engine.Calculate(completionsAt(date(2024, time.January, 3, 9), date(2024, time.January, 4, 9)), date(2024, time.January, 5, 9))
+	if _, ok := engine.NextDue(nil, date(2024, time.January, 5, 9)); ok {
+		t.Error("Expected a rule that never occurs to never be due")
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Expected the search for occurrences to be bounded, took %v", elapsed)
+	}
+
+	if rule.HasOccurrence(anchor, time.Monday) {
+		t.Error("Expected the rule to have no occurrence when starting on a Wednesday")
+	}
+	if !rule.HasOccurrence(date(2024, time.January, 1, 0), time.Monday) {
+		t.Error("Expected the rule to occur when starting on a Monday")
+	}
+}
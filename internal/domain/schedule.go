@@ -0,0 +1,233 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule describes how often a habit is due. The frequency sets the calendar
+// unit, which can be refined by at most one of:
+//   - Weekdays (weekly): a period starts on each listed weekday, e.g. Mon/Wed/Fri
+//   - MonthDays (monthly): a period starts on each listed day of the month, e.g. 1st and 15th
+//   - IntervalDays (daily): a period lasts N days counted from Anchor, e.g. every 2 days
+//   - RRule: a period starts on each occurrence of the rule, with Anchor as DTSTART
+//
+// TimesPerPeriod is how many completions a period needs to count towards the streak.
+// A measurable habit instead needs the quantities logged in a period to add up to
+// TargetValue.
+type Schedule struct {
+	Frequency      Frequency
+	TimesPerPeriod int
+	TargetValue    float64
+	Weekdays       []time.Weekday
+	MonthDays      []int
+	IntervalDays   int
+	RRule          *RRule
+	Anchor         time.Time
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseWeekdays parses a comma separated list of weekdays such as "mon,wed,fri".
+func ParseWeekdays(value string) ([]time.Weekday, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	seen := map[time.Weekday]bool{}
+	var weekdays []time.Weekday
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) > 3 {
+			name = name[:3]
+		}
+		weekday, ok := weekdayNames[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday: %s", name)
+		}
+		if !seen[weekday] {
+			seen[weekday] = true
+			weekdays = append(weekdays, weekday)
+		}
+	}
+
+	sort.Slice(weekdays, func(i, j int) bool { return weekdays[i] < weekdays[j] })
+	return weekdays, nil
+}
+
+// FormatWeekdays is the inverse of ParseWeekdays.
+func FormatWeekdays(weekdays []time.Weekday) string {
+	names := make([]string, len(weekdays))
+	for i, weekday := range weekdays {
+		names[i] = strings.ToLower(weekday.String()[:3])
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseMonthDays parses a comma separated list of days of the month such as "1,15".
+// Days past the end of a shorter month fall on its last day.
+func ParseMonthDays(value string) ([]int, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	seen := map[int]bool{}
+	var days []int
+	for _, part := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || day < 1 || day > 31 {
+			return nil, fmt.Errorf("invalid day of month: %s", strings.TrimSpace(part))
+		}
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+
+	sort.Ints(days)
+	return days, nil
+}
+
+// FormatMonthDays is the inverse of ParseMonthDays.
+func FormatMonthDays(days []int) string {
+	parts := make([]string, len(days))
+	for i, day := range days {
+		parts[i] = strconv.Itoa(day)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ValidateSchedule checks the habit's schedule fields and normalises the
+// weekday and month day lists.
+func ValidateSchedule(habit *Habit) error {
+	if habit.TimesPerPeriod < 0 {
+		return fmt.Errorf("times per period cannot be negative")
+	}
+	// Zero interval days leaves the habit without an interval
+	if habit.IntervalDays != 0 && (habit.IntervalDays < 1 || habit.IntervalDays > 365) {
+		return fmt.Errorf("interval days must be between 1 and 365")
+	}
+	if habit.TargetValue < 0 {
+		return fmt.Errorf("target value cannot be negative")
+	}
+	if habit.TargetValue > 0 && habit.TimesPerPeriod > 0 {
+		return fmt.Errorf("times per period cannot be combined with a target value")
+	}
+
+	habit.Unit = strings.TrimSpace(habit.Unit)
+	if habit.Unit != "" && habit.TargetValue == 0 {
+		return fmt.Errorf("unit requires a target value")
+	}
+
+	weekdays, err := ParseWeekdays(habit.Weekdays)
+	if err != nil {
+		return err
+	}
+	monthDays, err := ParseMonthDays(habit.MonthDays)
+	if err != nil {
+		return err
+	}
+
+	if habit.RRule != "" {
+		rule, err := ParseRRule(habit.RRule)
+		if err != nil {
+			return err
+		}
+		if len(weekdays) > 0 || len(monthDays) > 0 || habit.IntervalDays > 0 {
+			return fmt.Errorf("rrule cannot be combined with weekdays, month days or interval days")
+		}
+		if habit.Frequency == "" {
+			habit.Frequency = string(rule.Frequency)
+		} else if Frequency(habit.Frequency) != rule.Frequency {
+			return fmt.Errorf("rrule frequency does not match habit frequency")
+		}
+
+		// A rule such as FREQ=DAILY;INTERVAL=7;BYDAY=MO never occurs unless it starts on a Monday
+		anchor := habit.CreatedAt
+		if anchor.IsZero() {
+			anchor = time.Now()
+		}
+		if !rule.HasOccurrence(anchor.UTC(), DefaultSettings().WeekStart) {
+			return fmt.Errorf("rrule has no occurrences")
+		}
+		habit.RRule = rule.String()
+	}
+
+	if len(weekdays) > 0 && Frequency(habit.Frequency) != Weekly {
+		return fmt.Errorf("weekdays can only be set on weekly habits")
+	}
+	if len(monthDays) > 0 && Frequency(habit.Frequency) != Monthly {
+		return fmt.Errorf("month days can only be set on monthly habits")
+	}
+	if habit.IntervalDays > 0 && Frequency(habit.Frequency) != Daily {
+		return fmt.Errorf("interval days can only be set on daily habits")
+	}
+
+	habit.Weekdays = FormatWeekdays(weekdays)
+	habit.MonthDays = FormatMonthDays(monthDays)
+	return nil
+}
+
+// HabitSchedule builds the schedule stored on a habit. The habit is expected to
+// have passed ValidateSchedule.
+func HabitSchedule(habit Habit) Schedule {
+	weekdays, _ := ParseWeekdays(habit.Weekdays)
+	monthDays, _ := ParseMonthDays(habit.MonthDays)
+
+	var rule *RRule
+	if habit.RRule != "" {
+		rule, _ = ParseRRule(habit.RRule)
+	}
+
+	return Schedule{
+		Frequency:      Frequency(habit.Frequency),
+		TimesPerPeriod: habit.TimesPerPeriod,
+		TargetValue:    habit.TargetValue,
+		Weekdays:       weekdays,
+		MonthDays:      monthDays,
+		IntervalDays:   habit.IntervalDays,
+		RRule:          rule,
+		Anchor:         habit.CreatedAt,
+	}
+}
+
+// IsMeasurable reports whether periods are completed by reaching a target quantity.
+func (schedule Schedule) IsMeasurable() bool {
+	return schedule.TargetValue > 0
+}
+
+// RequiredProgress is the progress needed to complete a period: the target value
+// for measurable habits, otherwise the number of required completions.
+func (schedule Schedule) RequiredProgress() float64 {
+	if schedule.IsMeasurable() {
+		return schedule.TargetValue
+	}
+	return float64(schedule.RequiredCompletions())
+}
+
+// Progress is how much a single completion contributes towards its period.
+func (schedule Schedule) Progress(completion HabitCompletion) float64 {
+	if schedule.IsMeasurable() {
+		return completion.Quantity
+	}
+	return 1
+}
+
+// RequiredCompletions is the number of completions needed to complete a period.
+func (schedule Schedule) RequiredCompletions() int {
+	if schedule.TimesPerPeriod < 1 {
+		return 1
+	}
+	return schedule.TimesPerPeriod
+}
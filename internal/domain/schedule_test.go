@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWeekdays(t *testing.T) {
+	weekdays, err := ParseWeekdays("Fri, monday,wed,mon")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := []time.Weekday{time.Monday, time.Wednesday, time.Friday}
+	if len(weekdays) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, weekdays)
+	}
+	for i := range want {
+		if weekdays[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, weekdays)
+		}
+	}
+
+	if got := FormatWeekdays(weekdays); got != "mon,wed,fri" {
+		t.Errorf("Expected weekdays to format as 'mon,wed,fri', got %s", got)
+	}
+
+	if _, err := ParseWeekdays("mon,funday"); err == nil {
+		t.Errorf("Expected error for unknown weekday")
+	}
+}
+
+func TestParseMonthDays(t *testing.T) {
+	days, err := ParseMonthDays("15, 1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := FormatMonthDays(days); got != "1,15" {
+		t.Errorf("Expected month days to format as '1,15', got %s", got)
+	}
+
+	for _, invalid := range []string{"0", "32", "first"} {
+		if _, err := ParseMonthDays(invalid); err == nil {
+			t.Errorf("Expected error for month day %q", invalid)
+		}
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		habit   Habit
+		wantErr bool
+	}{
+		{"plain daily", Habit{Frequency: "daily"}, false},
+		{"three times per week", Habit{Frequency: "weekly", TimesPerPeriod: 3}, false},
+		{"specific weekdays", Habit{Frequency: "weekly", Weekdays: "mon,wed,fri"}, false},
+		{"every two days", Habit{Frequency: "daily", IntervalDays: 2}, false},
+		{"1st and 15th", Habit{Frequency: "monthly", MonthDays: "1,15"}, false},
+		{"negative times per period", Habit{Frequency: "weekly", TimesPerPeriod: -1}, true},
+		{"weekdays on a daily habit", Habit{Frequency: "daily", Weekdays: "mon"}, true},
+		{"month days on a weekly habit", Habit{Frequency: "weekly", MonthDays: "1"}, true},
+		{"interval on a monthly habit", Habit{Frequency: "monthly", IntervalDays: 2}, true},
+		{"interval too long", Habit{Frequency: "daily", IntervalDays: 400}, true},
+		{"negative interval", Habit{Frequency: "daily", IntervalDays: -1}, true},
+		{"interval of one day on a weekly habit", Habit{Frequency: "weekly", IntervalDays: 1}, true},
+		{"interval of one day on a daily habit", Habit{Frequency: "daily", IntervalDays: 1}, false},
+		{"invalid weekday", Habit{Frequency: "weekly", Weekdays: "someday"}, true},
+		{"recurrence rule", Habit{Frequency: "weekly", RRule: "FREQ=WEEKLY;BYDAY=MO,TH"}, false},
+		{"recurrence rule sets frequency", Habit{RRule: "FREQ=MONTHLY;BYMONTHDAY=1"}, false},
+		{"recurrence rule frequency mismatch", Habit{Frequency: "daily", RRule: "FREQ=WEEKLY"}, true},
+		{"recurrence rule with weekdays", Habit{Frequency: "weekly", Weekdays: "mon", RRule: "FREQ=WEEKLY"}, true},
+		{"recurrence rule with interval days", Habit{Frequency: "daily", IntervalDays: 1, RRule: "FREQ=DAILY"}, true},
+		{"invalid recurrence rule", Habit{Frequency: "daily", RRule: "FREQ=HOURLY"}, true},
+		{"recurrence rule that never occurs", Habit{RRule: "FREQ=DAILY;INTERVAL=364;BYDAY=MO", CreatedAt: time.Date(2024, time.January, 3, 8, 0, 0, 0, time.UTC)}, true},
+		{"recurrence rule on its start weekday", Habit{RRule: "FREQ=DAILY;INTERVAL=364;BYDAY=MO", CreatedAt: time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)}, false},
+		{"measurable target", Habit{Frequency: "daily", TargetValue: 8, Unit: "glasses"}, false},
+		{"negative target", Habit{Frequency: "daily", TargetValue: -1}, true},
+		{"target with times per period", Habit{Frequency: "weekly", TargetValue: 30, TimesPerPeriod: 3}, true},
+		{"unit without target", Habit{Frequency: "daily", Unit: "pages"}, true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateSchedule(&tt.habit)
+		if tt.wantErr && err == nil {
+			t.Errorf("%s: expected validation error, got nil", tt.name)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: expected no validation error, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestValidateScheduleNormalisesRRule(t *testing.T) {
+	habit := Habit{RRule: "rrule:byday=mo,we;freq=weekly"}
+	if err := ValidateSchedule(&habit); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if habit.Frequency != string(Weekly) {
+		t.Errorf("Expected frequency to be taken from the rule, got %s", habit.Frequency)
+	}
+	if habit.RRule != "FREQ=WEEKLY;BYDAY=MO,WE" {
+		t.Errorf("Expected canonical rule, got %s", habit.RRule)
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// Session is a login that is still valid until ExpiresAt. Only a hash of its
+// token is stored, so a leaked database does not leak usable tokens.
+type Session struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// IsExpired reports whether the session can no longer be used at the given time.
+func (s Session) IsExpired(at time.Time) bool {
+	return !at.Before(s.ExpiresAt)
+}
+
+// NewToken returns a random 256-bit token, URL-safe so it can be sent in a
+// header or cookie as is, along with the hash to store for it.
+func NewToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hash a token is stored and looked up by. Tokens are
+// random, so a fast unsalted hash is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+type SessionRepository interface {
+	Create(s *Session) error
+	GetByTokenHash(hash string) (*Session, error)
+	Delete(id uint) error
+	DeleteForUser(userID, exceptID uint) error
+	DeleteExpired(before time.Time) error
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewToken(t *testing.T) {
+	token, hash, err := NewToken()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(token) != 43 {
+		t.Errorf("expected a 43 character token, got %q", token)
+	}
+	if hash != HashToken(token) {
+		t.Errorf("expected the hash of the token, got %q", hash)
+	}
+	if hash == token {
+		t.Errorf("expected the token to be hashed")
+	}
+
+	other, _, _ := NewToken()
+	if other == token {
+		t.Errorf("expected a new token each time, got %q twice", token)
+	}
+}
+
+func TestSessionIsExpired(t *testing.T) {
+	expiresAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	session := Session{ExpiresAt: expiresAt}
+
+	if session.IsExpired(expiresAt.Add(-time.Second)) {
+		t.Errorf("expected session to be valid before it expires")
+	}
+	if !session.IsExpired(expiresAt) {
+		t.Errorf("expected session to expire at its expiry time")
+	}
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Settings controls how calendar periods are computed for a user's streaks.
+type Settings struct {
+	ID                 uint         `gorm:"primaryKey"`
+	UserID             uint         `gorm:"not null;default:0;uniqueIndex"` // 0 for settings saved before accounts existed
+	Timezone           string       `gorm:"not null"`                       // IANA timezone name, e.g. "Europe/London"
+	WeekStart          time.Weekday // first day of the week, 0 = Sunday ... 6 = Saturday
+	DayEndHour         int          // hour (0-12) at which a day ends, e.g. 3 counts 02:00 towards the previous day
+	FreezeEarnInterval int          `gorm:"not null;default:7"` // consecutive completed periods that earn a streak freeze, 0 disables freezes
+	MaxStreakFreezes   int          `gorm:"not null;default:2"` // most freezes a habit can hold at once
+	CreatedAt          time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt          time.Time    `gorm:"autoUpdateTime"`
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Timezone:           "UTC",
+		WeekStart:          time.Monday,
+		FreezeEarnInterval: 7,
+		MaxStreakFreezes:   2,
+	}
+}
+
+func (s Settings) Validate() error {
+	if _, err := time.LoadLocation(s.Timezone); err != nil || s.Timezone == "" || s.Timezone == "Local" {
+		return fmt.Errorf("invalid timezone: %s", s.Timezone)
+	}
+	if s.WeekStart < time.Sunday || s.WeekStart > time.Saturday {
+		return fmt.Errorf("invalid first day of week: %d", s.WeekStart)
+	}
+	if s.DayEndHour < 0 || s.DayEndHour > 12 {
+		return fmt.Errorf("invalid day end hour: %d", s.DayEndHour)
+	}
+	if s.FreezeEarnInterval < 0 || s.FreezeEarnInterval > 365 {
+		return fmt.Errorf("invalid freeze earn interval: %d", s.FreezeEarnInterval)
+	}
+	if s.MaxStreakFreezes < 0 || s.MaxStreakFreezes > 10 {
+		return fmt.Errorf("invalid maximum streak freezes: %d", s.MaxStreakFreezes)
+	}
+	return nil
+}
+
+// Location returns the settings' timezone, falling back to UTC if it cannot be loaded.
+func (s Settings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// DayStart returns the instant the given calendar day starts, taking the
+// timezone and day end hour into account.
+func (s Settings) DayStart(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, s.DayEndHour, 0, 0, 0, s.Location())
+}
@@ -0,0 +1,6 @@
+package domain
+
+type SettingsRepository interface {
+	Get(userID uint) (*Settings, error)
+	Save(s *Settings) error
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Settings
+		wantErr  bool
+	}{
+		{"defaults", DefaultSettings(), false},
+		{"iana timezone", Settings{Timezone: "America/New_York", WeekStart: time.Sunday, DayEndHour: 3}, false},
+		{"empty timezone", Settings{Timezone: "", WeekStart: time.Monday}, true},
+		{"unknown timezone", Settings{Timezone: "Mars/Olympus_Mons", WeekStart: time.Monday}, true},
+		{"server local timezone", Settings{Timezone: "Local", WeekStart: time.Monday}, true},
+		{"invalid week start", Settings{Timezone: "UTC", WeekStart: 7}, true},
+		{"negative day end hour", Settings{Timezone: "UTC", DayEndHour: -1}, true},
+		{"day end hour after midday", Settings{Timezone: "UTC", DayEndHour: 13}, true},
+		{"freezes disabled", Settings{Timezone: "UTC", FreezeEarnInterval: 0}, false},
+		{"negative freeze earn interval", Settings{Timezone: "UTC", FreezeEarnInterval: -1}, true},
+		{"too many streak freezes", Settings{Timezone: "UTC", FreezeEarnInterval: 7, MaxStreakFreezes: 11}, true},
+	}
+
+	for _, tt := range tests {
+		err := tt.settings.Validate()
+		if tt.wantErr && err == nil {
+			t.Errorf("%s: expected validation error, got nil", tt.name)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: expected no validation error, got %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package domain
+
+type SharingRepository interface {
+	CreateMember(m *HabitMember) error
+	GetMember(habitID, userID uint) (*HabitMember, error)
+	GetMembers(habitID uint) ([]HabitMember, error)
+	UpdateMemberRole(habitID, userID uint, role HabitRole) error
+	DeleteMember(habitID, userID uint) error
+
+	AddReaction(r *CompletionReaction) error
+	RemoveReaction(completionID, userID uint, emoji string) error
+	GetReactions(completionID uint) ([]CompletionReaction, error)
+	AddComment(c *CompletionComment) error
+	GetComment(id uint) (*CompletionComment, error)
+	GetComments(completionID uint) ([]CompletionComment, error)
+	DeleteComment(id uint) error
+}
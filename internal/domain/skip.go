@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type SkipReason string
+
+const (
+	SkipDay  SkipReason = "skip"
+	Vacation SkipReason = "vacation"
+	SickDay  SkipReason = "sick"
+)
+
+// maxSkipDays bounds a single skip so a typo cannot pause streaks indefinitely.
+const maxSkipDays = 366
+
+// Skip marks the calendar days from StartDate to EndDate (inclusive) as neutral
+// for one habit, or for every habit of the user who created it when HabitID is
+// nil. A period whose days are all skipped neither continues nor breaks a streak.
+// Dates are civil dates in the habit owner's timezone, stored at midnight UTC.
+type Skip struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;default:0;index"`
+	HabitID   *uint     `gorm:"index"`
+	StartDate time.Time `gorm:"type:date;not null"`
+	EndDate   time.Time `gorm:"type:date;not null"`
+	Reason    string    `gorm:"not null;default:'skip'"`
+	Note      string    `gorm:"not null;default:''"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func IsValidSkipReason(reason string) bool {
+	return reason == string(SkipDay) || reason == string(Vacation) || reason == string(SickDay)
+}
+
+// ValidateSkip checks the skip's date range and reason, defaulting a missing end
+// date to the start date and a missing reason to a plain skip.
+func ValidateSkip(skip *Skip) error {
+	if skip.StartDate.IsZero() {
+		return fmt.Errorf("skip start date is required")
+	}
+	if skip.EndDate.IsZero() {
+		skip.EndDate = skip.StartDate
+	}
+	skip.StartDate = civilDate(skip.StartDate)
+	skip.EndDate = civilDate(skip.EndDate)
+
+	if skip.EndDate.Before(skip.StartDate) {
+		return fmt.Errorf("skip end date cannot be before its start date")
+	}
+	if civilDays(skip.EndDate.Date())-civilDays(skip.StartDate.Date()) >= maxSkipDays {
+		return fmt.Errorf("skip cannot be longer than %d days", maxSkipDays)
+	}
+
+	if skip.Reason == "" {
+		skip.Reason = string(SkipDay)
+	}
+	if !IsValidSkipReason(skip.Reason) {
+		return fmt.Errorf("invalid skip reason: %s", skip.Reason)
+	}
+	return nil
+}
+
+// Covers reports whether the civil date day falls within the skip.
+func (skip Skip) Covers(day time.Time) bool {
+	day = civilDate(day)
+	return !day.Before(civilDate(skip.StartDate)) && !day.After(civilDate(skip.EndDate))
+}
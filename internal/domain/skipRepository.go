@@ -0,0 +1,9 @@
+package domain
+
+type SkipRepository interface {
+	Create(s *Skip) error
+	GetByID(id uint) (*Skip, error)
+	GetForUser(userID uint) ([]Skip, error)
+	GetForHabit(habitID, ownerID uint) ([]Skip, error)
+	Delete(id uint) error
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateSkip(t *testing.T) {
+	tests := []struct {
+		name    string
+		skip    Skip
+		wantErr bool
+	}{
+		{"single day", Skip{StartDate: date(2024, time.January, 10, 0)}, false},
+		{"vacation", Skip{StartDate: date(2024, time.July, 1, 0), EndDate: date(2024, time.July, 14, 0), Reason: "vacation"}, false},
+		{"missing start date", Skip{EndDate: date(2024, time.July, 14, 0)}, true},
+		{"end before start", Skip{StartDate: date(2024, time.July, 14, 0), EndDate: date(2024, time.July, 1, 0)}, true},
+		{"too long", Skip{StartDate: date(2024, time.January, 1, 0), EndDate: date(2025, time.January, 1, 0)}, true},
+		{"unknown reason", Skip{StartDate: date(2024, time.January, 10, 0), Reason: "lazy"}, true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateSkip(&tt.skip)
+		if tt.wantErr && err == nil {
+			t.Errorf("%s: expected validation error, got nil", tt.name)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: expected no validation error, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestStreakEngineSkips(t *testing.T) {
+	vacation := []Skip{{StartDate: date(2024, time.January, 3, 0), EndDate: date(2024, time.January, 5, 0)}}
+
+	tests := []struct {
+		name        string
+		schedule    Schedule
+		completions []time.Time
+		now         time.Time
+		wantStreak  int
+	}{
+		{
+			name:     "skipped days bridge a daily streak",
+			schedule: Schedule{Frequency: Daily},
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 2, 8),
+				date(2024, time.January, 6, 8),
+			},
+			now:        date(2024, time.January, 6, 9),
+			wantStreak: 3,
+		},
+		{
+			name:        "streak survives while on vacation",
+			schedule:    Schedule{Frequency: Daily},
+			completions: []time.Time{date(2024, time.January, 2, 8)},
+			now:         date(2024, time.January, 5, 9),
+			wantStreak:  1,
+		},
+		{
+			name:        "streak breaks after vacation ends",
+			schedule:    Schedule{Frequency: Daily},
+			completions: []time.Time{date(2024, time.January, 2, 8)},
+			now:         date(2024, time.January, 7, 9),
+			wantStreak:  0,
+		},
+		{
+			name:     "partly skipped week still has to be completed",
+			schedule: Schedule{Frequency: Weekly},
+			completions: []time.Time{
+				date(2023, time.December, 27, 8),
+				date(2024, time.January, 10, 8),
+			},
+			now:        date(2024, time.January, 10, 9),
+			wantStreak: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewStreakEngine(tt.schedule, DefaultSettings())
+			engine.Skips = vacation
+
+			result := engine.Calculate(completionsAt(tt.completions...), tt.now)
+			if result.CurrentStreak != tt.wantStreak {
+				t.Errorf("Expected CurrentStreak to be %d, got %d", tt.wantStreak, result.CurrentStreak)
+			}
+		})
+	}
+}
+
+func TestStreakEngineNextDueSkipsVacation(t *testing.T) {
+	engine := NewStreakEngine(Schedule{Frequency: Daily}, DefaultSettings())
+	engine.Skips = []Skip{{StartDate: date(2024, time.January, 3, 0), EndDate: date(2024, time.January, 5, 0)}}
+
+	due, _ := engine.NextDue(nil, date(2024, time.January, 3, 9))
+	if !due.Equal(date(2024, time.January, 6, 0)) {
+		t.Errorf("Expected habit to be due after the vacation, got %v", due)
+	}
+
+	occurrences := engine.Occurrences(date(2024, time.January, 2, 9), 2)
+	if len(occurrences) != 2 || !occurrences[0].Equal(date(2024, time.January, 6, 0)) {
+		t.Errorf("Expected occurrences to skip the vacation, got %v", occurrences)
+	}
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// StatsWindow is a span of time, ending now, that completion statistics cover.
+type StatsWindow string
+
+const (
+	Window7Days   StatsWindow = "7d"
+	Window30Days  StatsWindow = "30d"
+	Window90Days  StatsWindow = "90d"
+	Window365Days StatsWindow = "365d"
+	WindowWeek    StatsWindow = "week"  // since the start of the current week
+	WindowMonth   StatsWindow = "month" // since the start of the current month
+)
+
+var StatsWindows = []StatsWindow{Window7Days, Window30Days, Window90Days, Window365Days, WindowWeek, WindowMonth}
+
+func IsValidStatsWindow(window StatsWindow) bool {
+	for _, valid := range StatsWindows {
+		if window == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// PeriodStats summarises how well a habit was kept over a window. A period is
+// due once it has ended or been completed, so the current period only counts
+// towards the rate after it is completed. Skipped periods are never due.
+type PeriodStats struct {
+	Window           StatsWindow
+	From             time.Time
+	To               time.Time
+	DuePeriods       int
+	CompletedPeriods int
+	Completions      int
+	CompletionRate   float64 // percentage of due periods completed
+	AveragePerPeriod float64 // completions per due period
+}
+
+// Add totals other into stats, e.g. to combine the stats of several habits.
+func (stats *PeriodStats) Add(other PeriodStats) {
+	stats.DuePeriods += other.DuePeriods
+	stats.CompletedPeriods += other.CompletedPeriods
+	stats.Completions += other.Completions
+	stats.calculateRates()
+}
+
+func (stats *PeriodStats) calculateRates() {
+	stats.CompletionRate, stats.AveragePerPeriod = 0, 0
+	if stats.DuePeriods > 0 {
+		stats.CompletionRate = math.Round(float64(stats.CompletedPeriods)/float64(stats.DuePeriods)*1000) / 10
+		stats.AveragePerPeriod = math.Round(float64(stats.Completions)/float64(stats.DuePeriods)*100) / 100
+	}
+}
+
+// WindowStart returns the start of the window ending at now, as the start of a day.
+func (engine StreakEngine) WindowStart(window StatsWindow, now time.Time) (time.Time, error) {
+	local := engine.localDay(now)
+	year, month, day := local.Date()
+
+	switch window {
+	case Window7Days:
+		return engine.dayStart(year, month, day-6), nil
+	case Window30Days:
+		return engine.dayStart(year, month, day-29), nil
+	case Window90Days:
+		return engine.dayStart(year, month, day-89), nil
+	case Window365Days:
+		return engine.dayStart(year, month, day-364), nil
+	case WindowWeek:
+		offset := (int(local.Weekday()) - int(engine.WeekStart) + 7) % 7
+		return engine.dayStart(year, month, day-offset), nil
+	case WindowMonth:
+		return engine.dayStart(year, month, 1), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid stats window: %s", window)
+	}
+}
+
+// Stats counts the due and completed periods between since and now, starting
+// with the period that contains since.
+func (engine StreakEngine) Stats(completions []HabitCompletion, since, now time.Time) PeriodStats {
+	stats := PeriodStats{From: since, To: now}
+	if engine.RRule != nil && civilDate(engine.localDay(since)).Before(engine.ruleStart) {
+		since = engine.dayStart(engine.ruleStart.Date())
+	}
+
+	totals := map[int64]float64{}
+	counts := map[int64]int{}
+	for _, completion := range completions {
+		if completion.CompletedAt.After(now) {
+			continue
+		}
+		period := engine.PeriodStart(completion.CompletedAt).Unix()
+		totals[period] += engine.Progress(completion)
+		counts[period]++
+	}
+
+	for start := engine.PeriodStart(since); !start.IsZero() && !start.After(now); start = engine.NextPeriodStart(start) {
+		if engine.IsSkipped(start) {
+			continue
+		}
+
+		completed := totals[start.Unix()] >= engine.RequiredProgress()
+		// The last occurrence of a recurrence rule lasts a day
+		next := engine.NextPeriodStart(start)
+		if next.IsZero() {
+			next = start.AddDate(0, 0, 1)
+		}
+		ended := !next.After(now)
+		if !completed && !ended {
+			continue
+		}
+
+		stats.DuePeriods++
+		stats.Completions += counts[start.Unix()]
+		if completed {
+			stats.CompletedPeriods++
+		}
+	}
+
+	stats.calculateRates()
+	return stats
+}
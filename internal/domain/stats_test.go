@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreakEngineWindowStart(t *testing.T) {
+	engine := NewStreakEngine(Schedule{Frequency: Daily}, DefaultSettings())
+	now := date(2024, time.January, 17, 9) // Wednesday
+
+	tests := []struct {
+		window  StatsWindow
+		want    time.Time
+		wantErr bool
+	}{
+		{Window7Days, date(2024, time.January, 11, 0), false},
+		{Window30Days, date(2023, time.December, 19, 0), false},
+		{WindowWeek, date(2024, time.January, 15, 0), false},
+		{WindowMonth, date(2024, time.January, 1, 0), false},
+		{StatsWindow("fortnight"), time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.window), func(t *testing.T) {
+			got, err := engine.WindowStart(tt.window, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Expected window to start at %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStreakEngineStats(t *testing.T) {
+	now := date(2024, time.January, 14, 9)
+
+	tests := []struct {
+		name          string
+		schedule      Schedule
+		skips         []Skip
+		completions   []time.Time
+		since         time.Time
+		now           time.Time
+		wantDue       int
+		wantCompleted int
+		wantRate      float64
+		wantAverage   float64
+	}{
+		{
+			name:     "daily habit over a week",
+			schedule: Schedule{Frequency: Daily},
+			completions: []time.Time{
+				date(2024, time.January, 8, 8),
+				date(2024, time.January, 9, 8),
+				date(2024, time.January, 9, 20),
+				date(2024, time.January, 11, 8),
+				date(2024, time.January, 12, 8),
+			},
+			since:         date(2024, time.January, 8, 0),
+			wantDue:       6, // today is not due until it is completed
+			wantCompleted: 4,
+			wantRate:      66.7,
+			wantAverage:   0.83,
+		},
+		{
+			name:          "completed current period counts",
+			schedule:      Schedule{Frequency: Daily},
+			completions:   []time.Time{date(2024, time.January, 13, 8), date(2024, time.January, 14, 8)},
+			since:         date(2024, time.January, 13, 0),
+			wantDue:       2,
+			wantCompleted: 2,
+			wantRate:      100,
+			wantAverage:   1,
+		},
+		{
+			name:          "skipped days are not due",
+			schedule:      Schedule{Frequency: Daily},
+			skips:         []Skip{{StartDate: date(2024, time.January, 10, 0), EndDate: date(2024, time.January, 13, 0)}},
+			completions:   []time.Time{date(2024, time.January, 8, 8)},
+			since:         date(2024, time.January, 8, 0),
+			wantDue:       2,
+			wantCompleted: 1,
+			wantRate:      50,
+			wantAverage:   0.5,
+		},
+		{
+			name:     "weekly habit needs its times per period",
+			schedule: Schedule{Frequency: Weekly, TimesPerPeriod: 2},
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 3, 8),
+				date(2024, time.January, 9, 8),
+			},
+			since:         date(2024, time.January, 1, 0),
+			now:           date(2024, time.January, 16, 9),
+			wantDue:       2,
+			wantCompleted: 1,
+			wantRate:      50,
+			wantAverage:   1.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewStreakEngine(tt.schedule, DefaultSettings())
+			engine.Skips = tt.skips
+			if tt.now.IsZero() {
+				tt.now = now
+			}
+
+			stats := engine.Stats(completionsAt(tt.completions...), tt.since, tt.now)
+			if stats.DuePeriods != tt.wantDue {
+				t.Errorf("Expected DuePeriods to be %d, got %d", tt.wantDue, stats.DuePeriods)
+			}
+			if stats.CompletedPeriods != tt.wantCompleted {
+				t.Errorf("Expected CompletedPeriods to be %d, got %d", tt.wantCompleted, stats.CompletedPeriods)
+			}
+			if stats.CompletionRate != tt.wantRate {
+				t.Errorf("Expected CompletionRate to be %v, got %v", tt.wantRate, stats.CompletionRate)
+			}
+			if stats.AveragePerPeriod != tt.wantAverage {
+				t.Errorf("Expected AveragePerPeriod to be %v, got %v", tt.wantAverage, stats.AveragePerPeriod)
+			}
+		})
+	}
+}
+
+func TestStreakEngineStatsRRuleStart(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Timezone = "America/New_York"
+	settings.DayEndHour = 3
+
+	rule, _ := ParseRRule("FREQ=DAILY")
+	engine := NewStreakEngine(Schedule{Frequency: Daily, RRule: rule, Anchor: date(2024, time.January, 10, 17)}, settings)
+
+	stats := engine.Stats(nil, date(2024, time.January, 1, 17), date(2024, time.January, 14, 17))
+	if stats.DuePeriods != 4 {
+		t.Errorf("Expected the days before the rule started not to be due, got %d due", stats.DuePeriods)
+	}
+}
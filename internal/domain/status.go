@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// PeriodStatus describes where a habit stands in its current period.
+type PeriodStatus string
+
+const (
+	StatusNotDue    PeriodStatus = "not_due" // no period in progress, e.g. a recurrence rule that has not started or has ended
+	StatusDue       PeriodStatus = "due"
+	StatusCompleted PeriodStatus = "completed"
+	StatusSkipped   PeriodStatus = "skipped"
+	StatusOverdue   PeriodStatus = "overdue" // due, and the previous period was missed too
+)
+
+// Status returns the status of the period containing at. A period is overdue when
+// it still needs completions and the previous period that was not skipped ended
+// without being completed. Periods before since, when the habit started being
+// tracked, are never missed.
+func (engine StreakEngine) Status(completions []HabitCompletion, since, at time.Time) PeriodStatus {
+	current := engine.PeriodStart(at)
+	if engine.RRule != nil {
+		today := civilDate(engine.localDay(at))
+		if !engine.ruleEnd.IsZero() && today.After(engine.ruleEnd) {
+			return StatusNotDue
+		}
+		if _, ok := engine.previousOccurrence(today); !ok {
+			return StatusNotDue
+		}
+	}
+
+	if engine.IsSkipped(current) {
+		return StatusSkipped
+	}
+	if engine.PeriodProgress(completions, at) >= engine.RequiredProgress() {
+		return StatusCompleted
+	}
+
+	previous := engine.PeriodStart(current.Add(-time.Nanosecond))
+	for i := 0; i < maxSkipDays && engine.IsSkipped(previous); i++ {
+		previous = engine.PeriodStart(previous.Add(-time.Nanosecond))
+	}
+	if previous.Before(engine.PeriodStart(since)) {
+		return StatusDue
+	}
+	if engine.RRule != nil && civilDate(engine.localDay(previous)).Before(engine.ruleStart) {
+		return StatusDue
+	}
+	if engine.PeriodProgress(completions, previous) < engine.RequiredProgress() {
+		return StatusOverdue
+	}
+	return StatusDue
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreakEngineStatus(t *testing.T) {
+	since := date(2024, time.January, 1, 8)
+	now := date(2024, time.January, 10, 9) // Wednesday
+
+	tests := []struct {
+		name        string
+		schedule    Schedule
+		skips       []Skip
+		since       time.Time
+		completions []time.Time
+		want        PeriodStatus
+	}{
+		{
+			name:        "completed today",
+			schedule:    Schedule{Frequency: Daily},
+			completions: []time.Time{date(2024, time.January, 10, 8)},
+			want:        StatusCompleted,
+		},
+		{
+			name:        "due after completing yesterday",
+			schedule:    Schedule{Frequency: Daily},
+			completions: []time.Time{date(2024, time.January, 9, 8)},
+			want:        StatusDue,
+		},
+		{
+			name:        "overdue after missing yesterday",
+			schedule:    Schedule{Frequency: Daily},
+			completions: []time.Time{date(2024, time.January, 8, 8)},
+			want:        StatusOverdue,
+		},
+		{
+			name:        "skipped days are not missed",
+			schedule:    Schedule{Frequency: Daily},
+			skips:       []Skip{{StartDate: date(2024, time.January, 9, 0), EndDate: date(2024, time.January, 9, 0)}},
+			completions: []time.Time{date(2024, time.January, 8, 8)},
+			want:        StatusDue,
+		},
+		{
+			name:     "skipped today",
+			schedule: Schedule{Frequency: Daily},
+			skips:    []Skip{{StartDate: date(2024, time.January, 10, 0), EndDate: date(2024, time.January, 10, 0)}},
+			want:     StatusSkipped,
+		},
+		{
+			name:     "new habit is due rather than overdue",
+			schedule: Schedule{Frequency: Daily},
+			since:    date(2024, time.January, 10, 7),
+			want:     StatusDue,
+		},
+		{
+			name:        "weekly habit partly done this week",
+			schedule:    Schedule{Frequency: Weekly, TimesPerPeriod: 3},
+			completions: []time.Time{date(2024, time.January, 2, 8), date(2024, time.January, 3, 8), date(2024, time.January, 4, 8), date(2024, time.January, 9, 8)},
+			want:        StatusDue,
+		},
+		{
+			name:     "recurrence rule has ended",
+			schedule: Schedule{Frequency: Daily, RRule: &RRule{Frequency: Daily, Interval: 1, Count: 3}, Anchor: since},
+			want:     StatusNotDue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewStreakEngine(tt.schedule, DefaultSettings())
+			engine.Skips = tt.skips
+			if tt.since.IsZero() {
+				tt.since = since
+			}
+
+			status := engine.Status(completionsAt(tt.completions...), tt.since, now)
+			if status != tt.want {
+				t.Errorf("Expected status to be %s, got %s", tt.want, status)
+			}
+		})
+	}
+}
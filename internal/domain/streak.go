@@ -0,0 +1,491 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// StreakEngine evaluates a habit's completion history against calendar periods:
+// calendar days for daily habits, weeks starting on WeekStart for weekly habits
+// and calendar months for monthly habits, refined by the habit's Schedule.
+// Days start at DayEndHour in Location.
+//
+// Every FreezeEarnInterval consecutive completed periods earn a streak freeze, up
+// to MaxFreezes, and a freeze is spent automatically to bridge a single missed period.
+type StreakEngine struct {
+	Schedule
+	Location           *time.Location
+	WeekStart          time.Weekday
+	DayEndHour         int
+	FreezeEarnInterval int
+	MaxFreezes         int
+	Skips              []Skip // skipped days, whose periods are neutral
+
+	ruleStart time.Time // civil date the recurrence rule starts on
+	ruleEnd   time.Time // civil date of the rule's last occurrence, zero if unbounded
+}
+
+// StreakResult is the outcome of replaying a completion history.
+type StreakResult struct {
+	CurrentStreak    int
+	TotalCompletions int
+	LastCompletedAt  *time.Time
+	LongestStreak    int
+	Runs             []StreakRun // streaks that have ended, oldest first
+	Freezes          int         // freezes left
+	FreezeHistory    []FreezeEvent
+}
+
+func NewStreakEngine(schedule Schedule, settings Settings) StreakEngine {
+	engine := StreakEngine{
+		Schedule:           schedule,
+		Location:           settings.Location(),
+		WeekStart:          settings.WeekStart,
+		DayEndHour:         settings.DayEndHour,
+		FreezeEarnInterval: settings.FreezeEarnInterval,
+		MaxFreezes:         settings.MaxStreakFreezes,
+	}
+
+	if schedule.RRule != nil {
+		engine.ruleStart = civilDate(engine.localDay(schedule.Anchor))
+		engine.ruleEnd = engine.lastOccurrence()
+	}
+	return engine
+}
+
+// PeriodStart returns the start of the period that contains t.
+func (engine StreakEngine) PeriodStart(t time.Time) time.Time {
+	local := engine.localDay(t)
+	year, month, day := local.Date()
+
+	if engine.RRule != nil {
+		if occurrence, ok := engine.previousOccurrence(civilDate(local)); ok {
+			return engine.dayStart(occurrence.Date())
+		}
+		return engine.dayStart(year, month, day)
+	}
+
+	switch engine.Frequency {
+	case Weekly:
+		if len(engine.Weekdays) > 0 {
+			for offset := 0; offset < 7; offset++ {
+				if engine.isScheduledWeekday((int(local.Weekday()) - offset + 7) % 7) {
+					return engine.dayStart(year, month, day-offset)
+				}
+			}
+		}
+		offset := (int(local.Weekday()) - int(engine.WeekStart) + 7) % 7 // days since the week started
+		return engine.dayStart(year, month, day-offset)
+	case Monthly:
+		if len(engine.MonthDays) > 0 {
+			if start := engine.lastMonthDay(year, month, day); start > 0 {
+				return engine.dayStart(year, month, start)
+			}
+			return engine.dayStart(year, month-1, engine.lastMonthDay(year, month-1, 31))
+		}
+		return engine.dayStart(year, month, 1)
+	default:
+		if engine.IntervalDays > 1 {
+			anchorYear, anchorMonth, anchorDay := engine.localDay(engine.Anchor).Date()
+			days := civilDays(year, month, day) - civilDays(anchorYear, anchorMonth, anchorDay)
+			offset := ((days % engine.IntervalDays) + engine.IntervalDays) % engine.IntervalDays
+			return engine.dayStart(year, month, day-offset)
+		}
+		return engine.dayStart(year, month, day)
+	}
+}
+
+// NextPeriodStart returns the start of the period following the one that starts at
+// start, or the zero time if the habit's recurrence rule has no further occurrences.
+func (engine StreakEngine) NextPeriodStart(start time.Time) time.Time {
+	local := engine.localDay(start)
+	year, month, day := local.Date()
+
+	if engine.RRule != nil {
+		occurrence, ok := engine.nextOccurrence(civilDate(local))
+		if !ok {
+			return time.Time{}
+		}
+		return engine.dayStart(occurrence.Date())
+	}
+
+	switch engine.Frequency {
+	case Weekly:
+		if len(engine.Weekdays) > 0 {
+			for offset := 1; offset <= 7; offset++ {
+				if engine.isScheduledWeekday((int(local.Weekday()) + offset) % 7) {
+					return engine.dayStart(year, month, day+offset)
+				}
+			}
+		}
+		return engine.dayStart(year, month, day+7)
+	case Monthly:
+		if len(engine.MonthDays) > 0 {
+			for _, monthDay := range engine.monthDays(year, month) {
+				if monthDay > day {
+					return engine.dayStart(year, month, monthDay)
+				}
+			}
+			return engine.dayStart(year, month+1, engine.monthDays(year, month+1)[0])
+		}
+		return engine.dayStart(year, month+1, 1)
+	default:
+		if engine.IntervalDays > 1 {
+			return engine.dayStart(year, month, day+engine.IntervalDays)
+		}
+		return engine.dayStart(year, month, day+1)
+	}
+}
+
+// Calculate replays the completions and returns the streak as of now. A period
+// counts towards the streak once it has the schedule's required progress, and the
+// streak is only still current if the latest completed period is the current or
+// the immediately preceding one, or a freeze bridges the one period in between.
+// A bridged period keeps the streak going without adding to it.
+func (engine StreakEngine) Calculate(completions []HabitCompletion, now time.Time) StreakResult {
+	result := StreakResult{TotalCompletions: len(completions)}
+	if len(completions) == 0 {
+		return result
+	}
+
+	sorted := make([]HabitCompletion, len(completions))
+	copy(sorted, completions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].CompletedAt.Before(sorted[j].CompletedAt) })
+
+	last := sorted[len(sorted)-1].CompletedAt
+	result.LastCompletedAt = &last
+
+	// Total the progress per period, keeping periods in chronological order
+	var periods []time.Time
+	totals := map[int64]float64{}
+	for _, completion := range sorted {
+		period := engine.PeriodStart(completion.CompletedAt)
+		if len(periods) == 0 || !periods[len(periods)-1].Equal(period) {
+			periods = append(periods, period)
+		}
+		totals[period.Unix()] += engine.Progress(completion)
+	}
+
+	streak, run := 0, 0
+	var previous, runStart time.Time
+	for _, period := range periods {
+		if totals[period.Unix()] < engine.RequiredProgress() {
+			continue
+		}
+
+		switch {
+		case streak > 0 && !engine.nextCountedPeriod(previous).Before(period):
+			streak++
+		case streak > 0 && engine.freeze(&result, previous, period):
+			streak++
+		default:
+			if streak > 0 {
+				result.endRun(runStart, previous, streak)
+			}
+			streak, run, runStart = 1, 0, period
+		}
+		previous = period
+
+		// Earn a freeze at the end of every run of FreezeEarnInterval completed periods
+		run++
+		if engine.FreezeEarnInterval > 0 && run == engine.FreezeEarnInterval {
+			run = 0
+			if result.Freezes < engine.MaxFreezes {
+				result.Freezes++
+				result.FreezeHistory = append(result.FreezeHistory, FreezeEvent{Type: FreezeEarned, PeriodStart: period})
+			}
+		}
+	}
+
+	// Skipped periods between the last completed period and now keep the streak alive
+	current := engine.PeriodStart(now)
+	if streak > 0 && (previous.Equal(current) || !engine.nextCountedPeriod(previous).Before(current) ||
+		engine.freeze(&result, previous, current)) {
+		result.CurrentStreak = streak
+		if streak > result.LongestStreak {
+			result.LongestStreak = streak
+		}
+	} else if streak > 0 {
+		result.endRun(runStart, previous, streak)
+	}
+	return result
+}
+
+// endRun records a streak that ended after the period starting at end.
+func (result *StreakResult) endRun(start, end time.Time, length int) {
+	result.Runs = append(result.Runs, StreakRun{StartDate: start, EndDate: end, Length: length})
+	if length > result.LongestStreak {
+		result.LongestStreak = length
+	}
+}
+
+// freeze spends one of the result's freezes if exactly one period that is not
+// skipped was missed between the completed period previous and period.
+func (engine StreakEngine) freeze(result *StreakResult, previous, period time.Time) bool {
+	if result.Freezes == 0 {
+		return false
+	}
+
+	missed := engine.nextCountedPeriod(previous)
+	if missed.IsZero() {
+		return false
+	}
+	after := engine.nextCountedPeriod(missed)
+	if after.IsZero() || after.Before(period) {
+		return false
+	}
+
+	result.Freezes--
+	result.FreezeHistory = append(result.FreezeHistory, FreezeEvent{Type: FreezeUsed, PeriodStart: missed})
+	return true
+}
+
+// IsSkipped reports whether every day of the period starting at start is skipped.
+func (engine StreakEngine) IsSkipped(start time.Time) bool {
+	if len(engine.Skips) == 0 {
+		return false
+	}
+
+	first := civilDate(engine.localDay(start))
+	last := first
+	if next := engine.NextPeriodStart(start); !next.IsZero() {
+		last = civilDate(engine.localDay(next)).AddDate(0, 0, -1)
+	}
+
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if !engine.isSkippedDay(day) {
+			return false
+		}
+	}
+	return true
+}
+
+// nextCountedPeriod returns the start of the first period after the one starting
+// at start that is not skipped, or the zero time if there is none.
+func (engine StreakEngine) nextCountedPeriod(start time.Time) time.Time {
+	next := engine.NextPeriodStart(start)
+	for i := 0; i < maxSkipDays && !next.IsZero() && engine.IsSkipped(next); i++ {
+		next = engine.NextPeriodStart(next)
+	}
+	return next
+}
+
+func (engine StreakEngine) isSkippedDay(day time.Time) bool {
+	for _, skip := range engine.Skips {
+		if skip.Covers(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// PeriodProgress returns the progress logged in the period containing now.
+func (engine StreakEngine) PeriodProgress(completions []HabitCompletion, now time.Time) float64 {
+	current := engine.PeriodStart(now)
+
+	total := 0.0
+	for _, completion := range completions {
+		if engine.PeriodStart(completion.CompletedAt).Equal(current) {
+			total += engine.Progress(completion)
+		}
+	}
+	return total
+}
+
+// NextDue returns the start of the earliest period, from the current one onwards,
+// that still needs completions. It reports false once a recurrence rule has ended.
+func (engine StreakEngine) NextDue(completions []HabitCompletion, now time.Time) (time.Time, bool) {
+	current := engine.PeriodStart(now)
+
+	// A recurrence rule may have ended or not had its first occurrence yet
+	if engine.RRule != nil {
+		today := civilDate(engine.localDay(now))
+		if !engine.ruleEnd.IsZero() && today.After(engine.ruleEnd) {
+			return time.Time{}, false
+		}
+		if _, ok := engine.previousOccurrence(today); !ok {
+			next := engine.NextPeriodStart(current)
+			if !next.IsZero() && engine.IsSkipped(next) {
+				next = engine.nextCountedPeriod(next)
+			}
+			return next, !next.IsZero()
+		}
+	}
+
+	if engine.IsSkipped(current) || engine.PeriodProgress(completions, now) >= engine.RequiredProgress() {
+		next := engine.nextCountedPeriod(current)
+		return next, !next.IsZero()
+	}
+	return current, true
+}
+
+// DaysClean returns the number of whole days between since and now, the streak
+// of a quit habit whose last relapse (or creation) was at since.
+func (engine StreakEngine) DaysClean(since, now time.Time) int {
+	days := civilDays(engine.localDay(now).Date()) - civilDays(engine.localDay(since).Date())
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// CleanRuns returns the runs of days clean of a quit habit tracked since since
+// that were ended by the relapses, oldest first. Relapses on the same day as the
+// previous one do not start a new run.
+func (engine StreakEngine) CleanRuns(since time.Time, relapses []Relapse) []StreakRun {
+	sorted := make([]Relapse, len(relapses))
+	copy(sorted, relapses)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].OccurredAt.Before(sorted[j].OccurredAt) })
+
+	runs := []StreakRun{}
+	for _, relapse := range sorted {
+		if relapse.OccurredAt.Before(since) {
+			continue
+		}
+		if days := engine.DaysClean(since, relapse.OccurredAt); days > 0 {
+			runs = append(runs, StreakRun{StartDate: since, EndDate: relapse.OccurredAt, Length: days})
+		}
+		since = relapse.OccurredAt
+	}
+	return runs
+}
+
+// Occurrences returns the start of up to n periods that begin after now and are not skipped.
+func (engine StreakEngine) Occurrences(now time.Time, n int) []time.Time {
+	occurrences := []time.Time{}
+
+	start := engine.PeriodStart(now)
+	for len(occurrences) < n {
+		start = engine.nextCountedPeriod(start)
+		if start.IsZero() {
+			break
+		}
+		if start.After(now) {
+			occurrences = append(occurrences, start)
+		}
+	}
+	return occurrences
+}
+
+// previousOccurrence returns the latest occurrence of the recurrence rule on or before day.
+func (engine StreakEngine) previousOccurrence(day time.Time) (time.Time, bool) {
+	if !engine.ruleEnd.IsZero() && day.After(engine.ruleEnd) {
+		day = engine.ruleEnd
+	}
+
+	occurrence, _, ok := engine.RRule.search(engine.ruleStart, day, engine.WeekStart, false, maxRuleSearchDays)
+	return occurrence, ok
+}
+
+// nextOccurrence returns the earliest occurrence of the recurrence rule after day.
+func (engine StreakEngine) nextOccurrence(day time.Time) (time.Time, bool) {
+	day = day.AddDate(0, 0, 1)
+	if !engine.ruleEnd.IsZero() && day.After(engine.ruleEnd) {
+		return time.Time{}, false
+	}
+
+	occurrence, _, ok := engine.RRule.search(engine.ruleStart, day, engine.WeekStart, true, maxRuleSearchDays)
+	if !ok || (!engine.ruleEnd.IsZero() && occurrence.After(engine.ruleEnd)) {
+		return time.Time{}, false
+	}
+	return occurrence, true
+}
+
+// lastOccurrence returns the civil date of the final occurrence allowed by the
+// rule's COUNT or UNTIL, or the zero time if the rule repeats forever. A rule
+// that never occurs ends the day before it starts, and the search for the last
+// occurrence of a COUNT rule stops after maxRuleSearchDays in total.
+// UNTIL is compared by its UTC calendar date.
+func (engine StreakEngine) lastOccurrence() time.Time {
+	before := engine.ruleStart.AddDate(0, 0, -1)
+
+	first, examined, ok := engine.RRule.search(engine.ruleStart, engine.ruleStart, engine.WeekStart, true, maxRuleSearchDays)
+	if !ok {
+		return before
+	}
+	if engine.RRule.Until != nil {
+		until := civilDate(engine.RRule.Until.UTC())
+		if until.Before(first) {
+			return before
+		}
+		return until
+	}
+	if engine.RRule.Count == 0 {
+		return time.Time{}
+	}
+
+	last, budget := first, maxRuleSearchDays-examined
+	for found := 1; found < engine.RRule.Count; found++ {
+		occurrence, examined, ok := engine.RRule.search(engine.ruleStart, last.AddDate(0, 0, 1), engine.WeekStart, true, budget)
+		if !ok {
+			break
+		}
+		last, budget = occurrence, budget-examined
+	}
+	return last
+}
+
+// localDay shifts t into the engine's location so that its calendar date is the
+// day it counts towards, i.e. times before DayEndHour belong to the previous day.
+func (engine StreakEngine) localDay(t time.Time) time.Time {
+	return t.In(engine.location()).Add(-time.Duration(engine.DayEndHour) * time.Hour)
+}
+
+func (engine StreakEngine) dayStart(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, engine.DayEndHour, 0, 0, 0, engine.location())
+}
+
+func (engine StreakEngine) location() *time.Location {
+	if engine.Location == nil {
+		return time.UTC
+	}
+	return engine.Location
+}
+
+func (engine StreakEngine) isScheduledWeekday(weekday int) bool {
+	for _, scheduled := range engine.Weekdays {
+		if int(scheduled) == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// monthDays returns the scheduled days that fall in the given month, with days
+// past its end moved to its last day.
+func (engine StreakEngine) monthDays(year int, month time.Month) []int {
+	length := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	var days []int
+	for _, day := range engine.MonthDays {
+		if day > length {
+			day = length
+		}
+		if len(days) == 0 || days[len(days)-1] != day {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// lastMonthDay returns the latest scheduled day of the month on or before day, or 0 if there is none.
+func (engine StreakEngine) lastMonthDay(year int, month time.Month, day int) int {
+	days := engine.monthDays(year, month)
+	for i := len(days) - 1; i >= 0; i-- {
+		if days[i] <= day {
+			return days[i]
+		}
+	}
+	return 0
+}
+
+// civilDate returns t's calendar date as midnight UTC.
+func civilDate(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// civilDays returns the number of days between the Unix epoch and the given date.
+func civilDays(year int, month time.Month, day int) int {
+	return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
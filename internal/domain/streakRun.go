@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// StreakRun is a streak that has ended. For build habits it runs from the start
+// of its first completed period to the start of its last, and Length counts the
+// completed periods. For quit habits it runs from the start of a clean stretch to
+// the relapse that ended it, and Length counts the days clean.
+type StreakRun struct {
+	ID        uint      `gorm:"primaryKey"`
+	HabitID   uint      `gorm:"not null;index"`
+	StartDate time.Time `gorm:"not null"`
+	EndDate   time.Time `gorm:"not null"`
+	Length    int       `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// LongestRun returns the length of the longest of the runs.
+func LongestRun(runs []StreakRun) int {
+	longest := 0
+	for _, run := range runs {
+		if run.Length > longest {
+			longest = run.Length
+		}
+	}
+	return longest
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreakEngineRuns(t *testing.T) {
+	engine := NewStreakEngine(Schedule{Frequency: Daily}, Settings{Timezone: "UTC"})
+	completions := completionsAt(
+		date(2024, time.January, 1, 8),
+		date(2024, time.January, 2, 8),
+		date(2024, time.January, 3, 8),
+		date(2024, time.January, 6, 8),
+		date(2024, time.January, 9, 8),
+		date(2024, time.January, 10, 8),
+	)
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		wantStreak  int
+		wantLongest int
+		wantRuns    []StreakRun
+	}{
+		{
+			name:        "current streak is not a past run",
+			now:         date(2024, time.January, 10, 9),
+			wantStreak:  2,
+			wantLongest: 3,
+			wantRuns: []StreakRun{
+				{StartDate: date(2024, time.January, 1, 0), EndDate: date(2024, time.January, 3, 0), Length: 3},
+				{StartDate: date(2024, time.January, 6, 0), EndDate: date(2024, time.January, 6, 0), Length: 1},
+			},
+		},
+		{
+			name:        "broken streak becomes a past run",
+			now:         date(2024, time.January, 12, 9),
+			wantStreak:  0,
+			wantLongest: 3,
+			wantRuns: []StreakRun{
+				{StartDate: date(2024, time.January, 1, 0), EndDate: date(2024, time.January, 3, 0), Length: 3},
+				{StartDate: date(2024, time.January, 6, 0), EndDate: date(2024, time.January, 6, 0), Length: 1},
+				{StartDate: date(2024, time.January, 9, 0), EndDate: date(2024, time.January, 10, 0), Length: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := engine.Calculate(completions, tt.now)
+			if result.CurrentStreak != tt.wantStreak {
+				t.Errorf("Expected CurrentStreak to be %d, got %d", tt.wantStreak, result.CurrentStreak)
+			}
+			if result.LongestStreak != tt.wantLongest {
+				t.Errorf("Expected LongestStreak to be %d, got %d", tt.wantLongest, result.LongestStreak)
+			}
+			if len(result.Runs) != len(tt.wantRuns) {
+				t.Fatalf("Expected %d runs, got %v", len(tt.wantRuns), result.Runs)
+			}
+			for i, run := range tt.wantRuns {
+				got := result.Runs[i]
+				if !got.StartDate.Equal(run.StartDate) || !got.EndDate.Equal(run.EndDate) || got.Length != run.Length {
+					t.Errorf("Expected run %d to be %v, got %v", i, run, got)
+				}
+			}
+		})
+	}
+}
+
+func TestStreakEngineCleanRuns(t *testing.T) {
+	engine := NewStreakEngine(Schedule{Frequency: Daily}, DefaultSettings())
+	relapses := []Relapse{
+		{OccurredAt: date(2024, time.January, 20, 22)},
+		{OccurredAt: date(2024, time.January, 11, 9)},
+		{OccurredAt: date(2024, time.January, 11, 21)},
+	}
+
+	runs := engine.CleanRuns(date(2024, time.January, 1, 12), relapses)
+	if len(runs) != 2 {
+		t.Fatalf("Expected 2 runs, got %v", runs)
+	}
+	if runs[0].Length != 10 || !runs[0].EndDate.Equal(date(2024, time.January, 11, 9)) {
+		t.Errorf("Expected first run of 10 days ending at the first relapse, got %v", runs[0])
+	}
+	if runs[1].Length != 9 || !runs[1].StartDate.Equal(date(2024, time.January, 11, 21)) {
+		t.Errorf("Expected second run of 9 days after the last relapse that day, got %v", runs[1])
+	}
+	if LongestRun(runs) != 10 {
+		t.Errorf("Expected longest run to be 10, got %d", LongestRun(runs))
+	}
+}
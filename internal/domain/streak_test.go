@@ -0,0 +1,520 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func completionsAt(times ...time.Time) []HabitCompletion {
+	completions := make([]HabitCompletion, len(times))
+	for i, completedAt := range times {
+		completions[i] = HabitCompletion{CompletedAt: completedAt, Quantity: 1}
+	}
+	return completions
+}
+
+func TestStreakEnginePeriodStart(t *testing.T) {
+	// Wednesday 2024-01-17 15:00
+	at := date(2024, time.January, 17, 15)
+
+	tests := []struct {
+		frequency Frequency
+		want      time.Time
+	}{
+		{Daily, date(2024, time.January, 17, 0)},
+		{Weekly, date(2024, time.January, 15, 0)},
+		{Monthly, date(2024, time.January, 1, 0)},
+	}
+
+	for _, tt := range tests {
+		got := NewStreakEngine(Schedule{Frequency: tt.frequency}, DefaultSettings()).PeriodStart(at)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: expected period start %v, got %v", tt.frequency, tt.want, got)
+		}
+	}
+
+	// Sunday belongs to the ISO week that started the previous Monday
+	got := NewStreakEngine(Schedule{Frequency: Weekly}, DefaultSettings()).PeriodStart(date(2024, time.January, 21, 23))
+	if want := date(2024, time.January, 15, 0); !got.Equal(want) {
+		t.Errorf("expected Sunday to start week %v, got %v", want, got)
+	}
+}
+
+func TestStreakEngineCalculate(t *testing.T) {
+	tests := []struct {
+		name        string
+		frequency   Frequency
+		completions []time.Time
+		now         time.Time
+		wantStreak  int
+		wantTotal   int
+	}{
+		{
+			name:       "no completions",
+			frequency:  Daily,
+			now:        date(2024, time.January, 10, 12),
+			wantStreak: 0,
+		},
+		{
+			name:      "consecutive calendar days less than 24h apart",
+			frequency: Daily,
+			completions: []time.Time{
+				date(2024, time.January, 8, 23),
+				date(2024, time.January, 9, 1),
+				date(2024, time.January, 10, 6),
+			},
+			now:        date(2024, time.January, 10, 12),
+			wantStreak: 3,
+			wantTotal:  3,
+		},
+		{
+			name:      "consecutive calendar days more than 24h apart",
+			frequency: Daily,
+			completions: []time.Time{
+				date(2024, time.January, 9, 6),
+				date(2024, time.January, 10, 22),
+			},
+			now:        date(2024, time.January, 10, 23),
+			wantStreak: 2,
+			wantTotal:  2,
+		},
+		{
+			name:      "duplicate completions on the same day",
+			frequency: Daily,
+			completions: []time.Time{
+				date(2024, time.January, 10, 8),
+				date(2024, time.January, 10, 9),
+				date(2024, time.January, 10, 20),
+			},
+			now:        date(2024, time.January, 10, 21),
+			wantStreak: 1,
+			wantTotal:  3,
+		},
+		{
+			name:      "missed day resets streak",
+			frequency: Daily,
+			completions: []time.Time{
+				date(2024, time.January, 7, 8),
+				date(2024, time.January, 8, 8),
+				date(2024, time.January, 10, 8),
+			},
+			now:        date(2024, time.January, 10, 9),
+			wantStreak: 1,
+			wantTotal:  3,
+		},
+		{
+			name:      "streak still alive until the current day ends",
+			frequency: Daily,
+			completions: []time.Time{
+				date(2024, time.January, 8, 8),
+				date(2024, time.January, 9, 8),
+			},
+			now:        date(2024, time.January, 10, 23),
+			wantStreak: 2,
+			wantTotal:  2,
+		},
+		{
+			name:      "lapsed streak",
+			frequency: Daily,
+			completions: []time.Time{
+				date(2024, time.January, 7, 8),
+				date(2024, time.January, 8, 8),
+			},
+			now:        date(2024, time.January, 10, 8),
+			wantStreak: 0,
+			wantTotal:  2,
+		},
+		{
+			name:      "consecutive ISO weeks",
+			frequency: Weekly,
+			completions: []time.Time{
+				date(2024, time.January, 7, 8),  // Sunday, week of Jan 1
+				date(2024, time.January, 8, 8),  // Monday, week of Jan 8
+				date(2024, time.January, 20, 8), // Saturday, week of Jan 15
+			},
+			now:        date(2024, time.January, 22, 8),
+			wantStreak: 3,
+			wantTotal:  3,
+		},
+		{
+			name:      "missed ISO week",
+			frequency: Weekly,
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 15, 8),
+			},
+			now:        date(2024, time.January, 16, 8),
+			wantStreak: 1,
+			wantTotal:  2,
+		},
+		{
+			name:      "consecutive calendar months",
+			frequency: Monthly,
+			completions: []time.Time{
+				date(2024, time.January, 31, 8),
+				date(2024, time.February, 1, 8),
+				date(2024, time.March, 15, 8),
+			},
+			now:        date(2024, time.April, 2, 8),
+			wantStreak: 3,
+			wantTotal:  3,
+		},
+		{
+			name:      "missed calendar month",
+			frequency: Monthly,
+			completions: []time.Time{
+				date(2024, time.January, 5, 8),
+				date(2024, time.March, 5, 8),
+			},
+			now:        date(2024, time.March, 6, 8),
+			wantStreak: 1,
+			wantTotal:  2,
+		},
+		{
+			name:      "unordered completions",
+			frequency: Daily,
+			completions: []time.Time{
+				date(2024, time.January, 10, 8),
+				date(2024, time.January, 8, 8),
+				date(2024, time.January, 9, 8),
+			},
+			now:        date(2024, time.January, 10, 9),
+			wantStreak: 3,
+			wantTotal:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewStreakEngine(Schedule{Frequency: tt.frequency}, DefaultSettings()).Calculate(completionsAt(tt.completions...), tt.now)
+
+			if result.CurrentStreak != tt.wantStreak {
+				t.Errorf("Expected CurrentStreak to be %d, got %d", tt.wantStreak, result.CurrentStreak)
+			}
+			if result.TotalCompletions != tt.wantTotal {
+				t.Errorf("Expected TotalCompletions to be %d, got %d", tt.wantTotal, result.TotalCompletions)
+			}
+		})
+	}
+}
+
+func TestStreakEngineSettings(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+
+	tests := []struct {
+		name        string
+		frequency   Frequency
+		settings    Settings
+		completions []time.Time
+		now         time.Time
+		wantStreak  int
+	}{
+		{
+			// 2024-01-09 16:00 UTC is 2024-01-10 01:00 in Tokyo
+			name:      "days follow the configured timezone",
+			frequency: Daily,
+			settings:  Settings{Timezone: "Asia/Tokyo", WeekStart: time.Monday},
+			completions: []time.Time{
+				date(2024, time.January, 9, 2),
+				date(2024, time.January, 9, 16),
+			},
+			now:        date(2024, time.January, 9, 17),
+			wantStreak: 2,
+		},
+		{
+			name:      "completions before the day end hour count towards the previous day",
+			frequency: Daily,
+			settings:  Settings{Timezone: "UTC", WeekStart: time.Monday, DayEndHour: 3},
+			completions: []time.Time{
+				date(2024, time.January, 8, 22),
+				date(2024, time.January, 10, 2), // still January 9th for a night owl
+			},
+			now:        date(2024, time.January, 10, 2),
+			wantStreak: 2,
+		},
+		{
+			name:      "day end hour rolls the current day over late",
+			frequency: Daily,
+			settings:  Settings{Timezone: "UTC", WeekStart: time.Monday, DayEndHour: 3},
+			completions: []time.Time{
+				date(2024, time.January, 8, 22),
+			},
+			now:        date(2024, time.January, 10, 2),
+			wantStreak: 1,
+		},
+		{
+			name:      "weeks start on the configured day",
+			frequency: Weekly,
+			settings:  Settings{Timezone: "UTC", WeekStart: time.Sunday},
+			completions: []time.Time{
+				date(2024, time.January, 6, 8), // Saturday, week of Dec 31
+				date(2024, time.January, 7, 8), // Sunday, week of Jan 7
+			},
+			now:        date(2024, time.January, 8, 8),
+			wantStreak: 2,
+		},
+		{
+			name:      "Monday weeks put Saturday and Sunday together",
+			frequency: Weekly,
+			settings:  Settings{Timezone: "UTC", WeekStart: time.Monday},
+			completions: []time.Time{
+				date(2024, time.January, 6, 8),
+				date(2024, time.January, 7, 8),
+			},
+			now:        date(2024, time.January, 8, 8),
+			wantStreak: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewStreakEngine(Schedule{Frequency: tt.frequency}, tt.settings).Calculate(completionsAt(tt.completions...), tt.now)
+
+			if result.CurrentStreak != tt.wantStreak {
+				t.Errorf("Expected CurrentStreak to be %d, got %d", tt.wantStreak, result.CurrentStreak)
+			}
+		})
+	}
+
+	start := NewStreakEngine(Schedule{Frequency: Daily}, Settings{Timezone: "Asia/Tokyo"}).PeriodStart(date(2024, time.January, 9, 16))
+	if want := time.Date(2024, time.January, 10, 0, 0, 0, 0, tokyo); !start.Equal(want) {
+		t.Errorf("Expected period to start at %v, got %v", want, start)
+	}
+}
+
+func TestStreakEngineSchedules(t *testing.T) {
+	tests := []struct {
+		name        string
+		schedule    Schedule
+		completions []time.Time
+		now         time.Time
+		wantStreak  int
+	}{
+		{
+			name:     "three times per week",
+			schedule: Schedule{Frequency: Weekly, TimesPerPeriod: 3},
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 3, 8),
+				date(2024, time.January, 5, 8),
+				date(2024, time.January, 8, 8),
+				date(2024, time.January, 9, 8),
+				date(2024, time.January, 9, 20),
+			},
+			now:        date(2024, time.January, 10, 8),
+			wantStreak: 2,
+		},
+		{
+			name:     "week short of its target breaks the streak",
+			schedule: Schedule{Frequency: Weekly, TimesPerPeriod: 3},
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 2, 8),
+				date(2024, time.January, 3, 8),
+				date(2024, time.January, 8, 8),
+				date(2024, time.January, 9, 8),
+				date(2024, time.January, 15, 8),
+				date(2024, time.January, 16, 8),
+				date(2024, time.January, 17, 8),
+			},
+			now:        date(2024, time.January, 17, 9),
+			wantStreak: 1,
+		},
+		{
+			name:     "Monday, Wednesday and Friday",
+			schedule: Schedule{Frequency: Weekly, Weekdays: []time.Weekday{time.Monday, time.Wednesday, time.Friday}},
+			completions: []time.Time{
+				date(2024, time.January, 1, 8), // Monday
+				date(2024, time.January, 4, 8), // Thursday counts for Wednesday
+				date(2024, time.January, 7, 8), // Sunday counts for Friday
+				date(2024, time.January, 8, 8), // Monday
+			},
+			now:        date(2024, time.January, 9, 8),
+			wantStreak: 4,
+		},
+		{
+			name:     "missed Wednesday",
+			schedule: Schedule{Frequency: Weekly, Weekdays: []time.Weekday{time.Monday, time.Wednesday, time.Friday}},
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 2, 8),
+				date(2024, time.January, 5, 8),
+			},
+			now:        date(2024, time.January, 5, 9),
+			wantStreak: 1,
+		},
+		{
+			name:     "every two days",
+			schedule: Schedule{Frequency: Daily, IntervalDays: 2, Anchor: date(2024, time.January, 1, 10)},
+			completions: []time.Time{
+				date(2024, time.January, 2, 8), // period Jan 1-2
+				date(2024, time.January, 3, 8), // period Jan 3-4
+				date(2024, time.January, 5, 8), // period Jan 5-6
+			},
+			now:        date(2024, time.January, 8, 8),
+			wantStreak: 3,
+		},
+		{
+			name:     "every two days missed a period",
+			schedule: Schedule{Frequency: Daily, IntervalDays: 2, Anchor: date(2024, time.January, 1, 10)},
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 5, 8),
+			},
+			now:        date(2024, time.January, 6, 8),
+			wantStreak: 1,
+		},
+		{
+			name:     "1st and 15th of the month",
+			schedule: Schedule{Frequency: Monthly, MonthDays: []int{1, 15}},
+			completions: []time.Time{
+				date(2024, time.January, 20, 8), // period Jan 15-31
+				date(2024, time.February, 3, 8), // period Feb 1-14
+				date(2024, time.February, 15, 8),
+				date(2024, time.March, 1, 8),
+			},
+			now:        date(2024, time.March, 14, 8),
+			wantStreak: 4,
+		},
+		{
+			name:     "31st falls on the last day of February",
+			schedule: Schedule{Frequency: Monthly, MonthDays: []int{31}},
+			completions: []time.Time{
+				date(2024, time.January, 31, 8),
+				date(2024, time.February, 29, 8),
+				date(2024, time.March, 31, 8),
+			},
+			now:        date(2024, time.April, 2, 8),
+			wantStreak: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewStreakEngine(tt.schedule, DefaultSettings()).Calculate(completionsAt(tt.completions...), tt.now)
+
+			if result.CurrentStreak != tt.wantStreak {
+				t.Errorf("Expected CurrentStreak to be %d, got %d", tt.wantStreak, result.CurrentStreak)
+			}
+		})
+	}
+}
+
+func TestStreakEngineNextDue(t *testing.T) {
+	mwf := Schedule{Frequency: Weekly, Weekdays: []time.Weekday{time.Monday, time.Wednesday, time.Friday}}
+	mondays, _ := ParseRRule("FREQ=WEEKLY;BYDAY=MO")
+	threeDays, _ := ParseRRule("FREQ=DAILY;COUNT=3")
+
+	tests := []struct {
+		name        string
+		schedule    Schedule
+		completions []time.Time
+		now         time.Time
+		want        time.Time
+		wantEnded   bool
+	}{
+		{
+			name:     "due today when not yet completed",
+			schedule: Schedule{Frequency: Daily},
+			now:      date(2024, time.January, 10, 8),
+			want:     date(2024, time.January, 10, 0),
+		},
+		{
+			name:        "due tomorrow once completed",
+			schedule:    Schedule{Frequency: Daily},
+			completions: []time.Time{date(2024, time.January, 10, 7)},
+			now:         date(2024, time.January, 10, 8),
+			want:        date(2024, time.January, 11, 0),
+		},
+		{
+			name:        "next scheduled weekday",
+			schedule:    mwf,
+			completions: []time.Time{date(2024, time.January, 5, 7)}, // Friday
+			now:         date(2024, time.January, 6, 8),
+			want:        date(2024, time.January, 8, 0),
+		},
+		{
+			name:        "weekly target not yet reached",
+			schedule:    Schedule{Frequency: Weekly, TimesPerPeriod: 2},
+			completions: []time.Time{date(2024, time.January, 9, 7)},
+			now:         date(2024, time.January, 10, 8),
+			want:        date(2024, time.January, 8, 0),
+		},
+		{
+			name:     "first occurrence of a recurrence rule",
+			schedule: Schedule{Frequency: Weekly, RRule: mondays, Anchor: date(2024, time.January, 10, 8)}, // Wednesday
+			now:      date(2024, time.January, 11, 8),
+			want:     date(2024, time.January, 15, 0),
+		},
+		{
+			name:        "recurrence rule has ended",
+			schedule:    Schedule{Frequency: Daily, RRule: threeDays, Anchor: date(2024, time.January, 1, 8)},
+			completions: []time.Time{date(2024, time.January, 3, 7)},
+			now:         date(2024, time.January, 10, 8),
+			wantEnded:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NewStreakEngine(tt.schedule, DefaultSettings()).NextDue(completionsAt(tt.completions...), tt.now)
+
+			if ok == tt.wantEnded {
+				t.Fatalf("Expected ended to be %v, got next due %v", tt.wantEnded, got)
+			}
+			if !tt.wantEnded && !got.Equal(tt.want) {
+				t.Errorf("Expected next due %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStreakEngineMeasurable(t *testing.T) {
+	glasses := Schedule{Frequency: Daily, TargetValue: 8}
+
+	completions := []HabitCompletion{
+		{CompletedAt: date(2024, time.January, 8, 9), Quantity: 8},
+		{CompletedAt: date(2024, time.January, 9, 9), Quantity: 5},
+		{CompletedAt: date(2024, time.January, 9, 18), Quantity: 3},
+		{CompletedAt: date(2024, time.January, 10, 9), Quantity: 4},
+	}
+	engine := NewStreakEngine(glasses, DefaultSettings())
+	now := date(2024, time.January, 10, 12)
+
+	result := engine.Calculate(completions, now)
+	if result.CurrentStreak != 2 {
+		t.Errorf("Expected CurrentStreak to be 2, got %d", result.CurrentStreak)
+	}
+	if result.TotalCompletions != 4 {
+		t.Errorf("Expected TotalCompletions to be 4, got %d", result.TotalCompletions)
+	}
+
+	if progress := engine.PeriodProgress(completions, now); progress != 4 {
+		t.Errorf("Expected progress of 4, got %v", progress)
+	}
+
+	due, _ := engine.NextDue(completions, now)
+	if !due.Equal(date(2024, time.January, 10, 0)) {
+		t.Errorf("Expected habit to still be due today, got %v", due)
+	}
+
+	completions = append(completions, HabitCompletion{CompletedAt: date(2024, time.January, 10, 20), Quantity: 4})
+	if result := engine.Calculate(completions, now); result.CurrentStreak != 3 {
+		t.Errorf("Expected CurrentStreak to be 3 once the target is reached, got %d", result.CurrentStreak)
+	}
+}
+
+func TestStreakEngineDaysClean(t *testing.T) {
+	engine := NewStreakEngine(Schedule{Frequency: Daily}, DefaultSettings())
+
+	if got := engine.DaysClean(date(2024, time.January, 1, 22), date(2024, time.January, 11, 8)); got != 10 {
+		t.Errorf("Expected 10 days clean, got %d", got)
+	}
+	if got := engine.DaysClean(date(2024, time.January, 11, 7), date(2024, time.January, 11, 8)); got != 0 {
+		t.Errorf("Expected 0 days clean on the day of a relapse, got %d", got)
+	}
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// strengthHalfLife is the number of days a daily habit's strength takes to halve
+// when it is no longer completed.
+const strengthHalfLife = 13.0
+
+// maxStrengthPeriods is the number of most recent periods replayed. Each period
+// keeps at most 0.5^(1/13) of the score before it, so older periods weigh less
+// than 0.5^40 and are left out.
+const maxStrengthPeriods = 520
+
+// StrengthPoint is a habit's strength at the end of a period.
+type StrengthPoint struct {
+	PeriodStart time.Time
+	Strength    float64
+}
+
+// Strength scores how well the habit is established, from 0 to 100, in the way
+// of Loop Habit Tracker: each period moves the score towards the share of the
+// period's required progress that was logged, weighted by an exponential decay.
+// A period of L days that needs R completions keeps 0.5^(sqrt(R*L)/13) of the
+// previous score, so a miss costs a daily habit as much as one of its days and
+// habits done less often decay more slowly per day. Skipped periods leave the
+// score unchanged and the current period only counts once it is completed. Only
+// the last maxStrengthPeriods periods are replayed.
+// It returns the current strength and the strength after each period, oldest first.
+func (engine StreakEngine) Strength(completions []HabitCompletion, now time.Time) (float64, []StrengthPoint) {
+	points := []StrengthPoint{}
+	if len(completions) == 0 {
+		return 0, points
+	}
+
+	first := completions[0].CompletedAt
+	totals := map[int64]float64{}
+	for _, completion := range completions {
+		if completion.CompletedAt.Before(first) {
+			first = completion.CompletedAt
+		}
+		totals[engine.PeriodStart(completion.CompletedAt).Unix()] += engine.Progress(completion)
+	}
+
+	required := float64(engine.RequiredCompletions())
+	if engine.IsMeasurable() {
+		required = 1
+	}
+
+	from := engine.PeriodStart(first)
+	bound := engine.PeriodStart(now)
+	for i := 1; i < maxStrengthPeriods && bound.After(from); i++ {
+		bound = engine.PeriodStart(bound.Add(-time.Nanosecond))
+	}
+	if bound.After(from) {
+		from = bound
+	}
+
+	score := 0.0
+	for start := from; !start.IsZero() && !start.After(now); {
+		next := engine.NextPeriodStart(start)
+		days := 1
+		if !next.IsZero() {
+			days = civilDays(engine.localDay(next).Date()) - civilDays(engine.localDay(start).Date())
+		}
+
+		value := math.Min(totals[start.Unix()]/engine.RequiredProgress(), 1)
+		current := next.IsZero() || next.After(now)
+		if current && value < 1 {
+			break
+		}
+
+		if !engine.IsSkipped(start) {
+			multiplier := math.Pow(0.5, math.Sqrt(required*float64(days))/strengthHalfLife)
+			score = score*multiplier + value*(1-multiplier)
+			points = append(points, StrengthPoint{PeriodStart: start, Strength: roundStrength(score)})
+		}
+
+		if current {
+			break
+		}
+		start = next
+	}
+	return roundStrength(score), points
+}
+
+// StrengthAt returns the strength after the period starting at start.
+func StrengthAt(points []StrengthPoint, start time.Time) (float64, bool) {
+	for i := len(points) - 1; i >= 0; i-- {
+		if points[i].PeriodStart.Equal(start) {
+			return points[i].Strength, true
+		}
+	}
+	return 0, false
+}
+
+func roundStrength(score float64) float64 {
+	return math.Round(score*1000) / 10
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreakEngineStrength(t *testing.T) {
+	var everyDay []time.Time
+	for day := 1; day <= 30; day++ {
+		everyDay = append(everyDay, date(2024, time.January, day, 8))
+	}
+
+	tests := []struct {
+		name        string
+		schedule    Schedule
+		skips       []Skip
+		completions []time.Time
+		now         time.Time
+		want        float64
+		wantPoints  int
+	}{
+		{
+			name:     "no completions",
+			schedule: Schedule{Frequency: Daily},
+			now:      date(2024, time.January, 30, 9),
+			want:     0,
+		},
+		{
+			name:        "one completion",
+			schedule:    Schedule{Frequency: Daily},
+			completions: []time.Time{date(2024, time.January, 1, 8)},
+			now:         date(2024, time.January, 1, 9),
+			want:        5.2,
+			wantPoints:  1,
+		},
+		{
+			name:        "a month of daily completions",
+			schedule:    Schedule{Frequency: Daily},
+			completions: everyDay,
+			now:         date(2024, time.January, 30, 9),
+			want:        79.8,
+			wantPoints:  30,
+		},
+		{
+			name:        "completions long ago are not replayed",
+			schedule:    Schedule{Frequency: Daily},
+			completions: append([]time.Time{date(1, time.January, 1, 8)}, everyDay...),
+			now:         date(2024, time.January, 30, 9),
+			want:        79.8,
+			wantPoints:  maxStrengthPeriods,
+		},
+		{
+			name:        "a miss costs one day's worth of strength",
+			schedule:    Schedule{Frequency: Daily},
+			completions: everyDay[:29],
+			now:         date(2024, time.January, 31, 9),
+			want:        74.6,
+			wantPoints:  30, // today is not counted until it is completed
+		},
+		{
+			name:        "skipped days leave strength unchanged",
+			schedule:    Schedule{Frequency: Daily},
+			skips:       []Skip{{StartDate: date(2024, time.January, 30, 0), EndDate: date(2024, time.January, 31, 0)}},
+			completions: everyDay[:29],
+			now:         date(2024, time.January, 31, 9),
+			want:        78.7,
+			wantPoints:  29,
+		},
+		{
+			name:     "weekly habit decays more slowly per day",
+			schedule: Schedule{Frequency: Weekly},
+			completions: []time.Time{
+				date(2024, time.January, 1, 8),
+				date(2024, time.January, 8, 8),
+				date(2024, time.January, 15, 8),
+				date(2024, time.January, 22, 8),
+			},
+			now:        date(2024, time.January, 22, 9),
+			want:       43.1,
+			wantPoints: 4,
+		},
+		{
+			name:        "partial progress counts towards strength",
+			schedule:    Schedule{Frequency: Daily, TargetValue: 8},
+			completions: []time.Time{date(2024, time.January, 1, 8)},
+			now:         date(2024, time.January, 2, 9),
+			want:        0.6,
+			wantPoints:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewStreakEngine(tt.schedule, DefaultSettings())
+			engine.Skips = tt.skips
+
+			strength, points := engine.Strength(completionsAt(tt.completions...), tt.now)
+			if strength != tt.want {
+				t.Errorf("Expected strength to be %v, got %v", tt.want, strength)
+			}
+			if len(points) != tt.wantPoints {
+				t.Errorf("Expected %d strength points, got %d", tt.wantPoints, len(points))
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// TimerUnit is the unit of timed habits, whose target is a number of minutes
+// tracked with timer sessions, e.g. "meditate 20 minutes".
+const TimerUnit = "minutes"
+
+type TimerStatus string
+
+const (
+	TimerRunning TimerStatus = "running"
+	TimerPaused  TimerStatus = "paused"
+	TimerStopped TimerStatus = "stopped"
+)
+
+// TimerSession tracks time spent on a timed habit. A session runs in segments
+// between pauses; ElapsedSeconds holds the time of the finished segments and
+// ResumedAt the start of the segment that is running, if any.
+type TimerSession struct {
+	ID             uint        `gorm:"primaryKey"`
+	HabitID        uint        `gorm:"not null;index"`
+	Status         TimerStatus `gorm:"not null"`
+	StartedAt      time.Time   `gorm:"not null"`
+	ResumedAt      *time.Time
+	ElapsedSeconds int64 `gorm:"not null;default:0"`
+	StoppedAt      *time.Time
+	CompletionID   *uint     // completion recorded when the session stopped
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+// IsTimed reports whether the habit is tracked with timer sessions.
+func (habit Habit) IsTimed() bool {
+	return habit.TargetValue > 0 && strings.EqualFold(habit.Unit, TimerUnit)
+}
+
+// Elapsed returns the time accumulated by the session as of now.
+func (session TimerSession) Elapsed(now time.Time) time.Duration {
+	elapsed := time.Duration(session.ElapsedSeconds) * time.Second
+	if session.Status == TimerRunning && session.ResumedAt != nil && now.After(*session.ResumedAt) {
+		elapsed += now.Sub(*session.ResumedAt)
+	}
+	return elapsed
+}
+
+// Minutes returns the time accumulated by the finished segments in minutes.
+func (session TimerSession) Minutes() float64 {
+	return float64(session.ElapsedSeconds) / 60
+}
+
+// Pause ends the running segment at now.
+func (session *TimerSession) Pause(now time.Time) {
+	session.ElapsedSeconds = int64(session.Elapsed(now).Round(time.Second) / time.Second)
+	session.ResumedAt = nil
+	session.Status = TimerPaused
+}
+
+// Resume starts a new segment at now.
+func (session *TimerSession) Resume(now time.Time) {
+	session.ResumedAt = &now
+	session.Status = TimerRunning
+}
+
+// Stop ends the session at now.
+func (session *TimerSession) Stop(now time.Time) {
+	session.ElapsedSeconds = int64(session.Elapsed(now).Round(time.Second) / time.Second)
+	session.ResumedAt = nil
+	session.StoppedAt = &now
+	session.Status = TimerStopped
+}
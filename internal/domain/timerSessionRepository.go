@@ -0,0 +1,8 @@
+package domain
+
+type TimerSessionRepository interface {
+	GetActive(habitID uint) (*TimerSession, error)
+	GetRunning() ([]TimerSession, error)
+	Save(s *TimerSession) error
+	Complete(s *TimerSession, h *Habit, c *HabitCompletion) error
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimerSession(t *testing.T) {
+	start := date(2024, time.January, 10, 8)
+	session := TimerSession{StartedAt: start}
+
+	session.Resume(start)
+	if got := session.Elapsed(start.Add(5 * time.Minute)); got != 5*time.Minute {
+		t.Errorf("Expected 5m elapsed while running, got %v", got)
+	}
+
+	session.Pause(start.Add(5 * time.Minute))
+	if got := session.Elapsed(start.Add(time.Hour)); got != 5*time.Minute {
+		t.Errorf("Expected paused time not to count, got %v", got)
+	}
+
+	session.Resume(start.Add(time.Hour))
+	session.Stop(start.Add(time.Hour + 15*time.Minute))
+	if session.Status != TimerStopped {
+		t.Errorf("Expected session to be stopped, got %s", session.Status)
+	}
+	if session.Minutes() != 20 {
+		t.Errorf("Expected 20 minutes, got %v", session.Minutes())
+	}
+}
+
+func TestHabitIsTimed(t *testing.T) {
+	if !(Habit{TargetValue: 20, Unit: "Minutes"}).IsTimed() {
+		t.Errorf("Expected habit with a target in minutes to be timed")
+	}
+	if (Habit{TargetValue: 8, Unit: "glasses"}).IsTimed() {
+		t.Errorf("Expected habit with a target in glasses not to be timed")
+	}
+	if (Habit{Unit: "minutes"}).IsTimed() {
+		t.Errorf("Expected habit without a target not to be timed")
+	}
+}
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTP parameters, the defaults every authenticator app supports (RFC 6238)
+	totpDigits = 6
+	totpPeriod = 30 // seconds
+	// totpSkew is how many time steps either side of now a code is accepted, to
+	// allow for clock drift and slow typing
+	totpSkew = 1
+
+	// RecoveryCodeCount is how many recovery codes a user gets when enabling 2FA
+	RecoveryCodeCount = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// RecoveryCode lets a user log in once without their authenticator. Only a hash
+// is stored, like session tokens.
+type RecoveryCode struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	CodeHash  string    `gorm:"not null" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// LoginChallenge is a login that checked out the password but still needs a
+// second factor. Attempts counts wrong codes, so they cannot be brute forced.
+type LoginChallenge struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"not null;uniqueIndex" json:"-"`
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// IsExpired reports whether the challenge can no longer be answered at the given time.
+func (c LoginChallenge) IsExpired(at time.Time) bool {
+	return !at.Before(c.ExpiresAt)
+}
+
+// NewTOTPSecret returns a random 160-bit secret, base32 encoded as
+// authenticator apps expect.
+func NewTOTPSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// TOTPURI returns the otpauth URI authenticator apps scan as a QR code.
+func TOTPURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// TOTPCode returns the code for a time step, see TOTPCounter.
+func TOTPCode(secret string, counter int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid totp secret")
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000), nil // 10^totpDigits
+}
+
+// TOTPCounter returns the time step a time falls in.
+func TOTPCounter(at time.Time) int64 {
+	return at.Unix() / totpPeriod
+}
+
+// MatchTOTP checks a code against the time steps around the given time and
+// returns the step it matched, which the caller should not accept again.
+func MatchTOTP(secret, code string, at time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	now := TOTPCounter(at)
+	for counter := now - totpSkew; counter <= now+totpSkew; counter++ {
+		want, err := TOTPCode(secret, counter)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// NewRecoveryCode returns a random recovery code formatted for reading out, such
+// as "k3v9q-2mxa7", along with the hash to store for it.
+func NewRecoveryCode() (code, hash string, err error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	raw := strings.ToLower(totpEncoding.EncodeToString(buf))[:10]
+	code = raw[:5] + "-" + raw[5:]
+	return code, HashRecoveryCode(code), nil
+}
+
+// HashRecoveryCode returns the hash a recovery code is stored by. Case, spaces
+// and dashes are ignored, as users type codes back in by hand.
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return HashToken(code)
+}
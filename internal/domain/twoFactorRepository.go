@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+type TwoFactorRepository interface {
+	// SetPendingSecret stores the secret of an enrolment that is not enforced yet
+	SetPendingSecret(userID uint, secret string) error
+	// Enable enforces the pending secret and replaces the user's recovery codes
+	Enable(userID uint, counter int64, codes []RecoveryCode) error
+	// Reset turns 2FA off and removes the secret and recovery codes
+	Reset(userID uint) error
+	// UseCounter records a time step as used, reporting false if it or a later
+	// one was used already
+	UseCounter(userID uint, counter int64) (bool, error)
+	// UseRecoveryCode deletes a matching recovery code, reporting false if the
+	// user has none
+	UseRecoveryCode(userID uint, codeHash string) (bool, error)
+
+	CreateChallenge(c *LoginChallenge) error
+	GetChallengeByTokenHash(hash string) (*LoginChallenge, error)
+	AddChallengeAttempt(id uint) error
+	DeleteChallenge(id uint) error
+	DeleteExpiredChallenges(before time.Time) error
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 secret from RFC 6238, appendix B, "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		// The RFC lists 8 digit codes, these are their last 6 digits
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfcSecret, TOTPCounter(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("at %d: expected %q, got %q", tt.unix, tt.want, got)
+		}
+	}
+
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Errorf("expected an error for an invalid secret")
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	at := time.Unix(1111111109, 0)
+	counter := TOTPCounter(at)
+
+	tests := []struct {
+		name      string
+		code      string
+		wantMatch bool
+		wantStep  int64
+	}{
+		{name: "current code", code: "081804", wantMatch: true, wantStep: counter},
+		{name: "surrounding spaces", code: " 081804 ", wantMatch: true, wantStep: counter},
+		{name: "previous code", code: mustCode(t, counter-1), wantMatch: true, wantStep: counter - 1},
+		{name: "next code", code: mustCode(t, counter+1), wantMatch: true, wantStep: counter + 1},
+		{name: "too old", code: mustCode(t, counter-2), wantMatch: false},
+		{name: "wrong code", code: "123456", wantMatch: false},
+		{name: "wrong length", code: "81804", wantMatch: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := MatchTOTP(rfcSecret, tt.code, at)
+			if ok != tt.wantMatch {
+				t.Fatalf("expected match %v, got %v", tt.wantMatch, ok)
+			}
+			if ok && step != tt.wantStep {
+				t.Errorf("expected step %d, got %d", tt.wantStep, step)
+			}
+		})
+	}
+}
+
+func mustCode(t *testing.T, counter int64) string {
+	code, err := TOTPCode(rfcSecret, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestNewTOTPSecret(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("expected a 32 character secret, got %q", secret)
+	}
+	if _, err := TOTPCode(secret, 1); err != nil {
+		t.Errorf("expected the secret to be usable, got %v", err)
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Habit Tracker", "alice@example.com", "SECRET")
+	want := "otpauth://totp/Habit%20Tracker:alice@example.com?algorithm=SHA1&digits=6&issuer=Habit+Tracker&period=30&secret=SECRET"
+	if uri != want {
+		t.Errorf("expected %q, got %q", want, uri)
+	}
+}
+
+func TestNewRecoveryCode(t *testing.T) {
+	code, hash, err := NewRecoveryCode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(code) != 11 || code[5] != '-' {
+		t.Errorf("expected a code like xxxxx-xxxxx, got %q", code)
+	}
+	if hash != HashRecoveryCode(code) {
+		t.Errorf("expected the hash of the code, got %q", hash)
+	}
+
+	// Codes typed back in by hand still match
+	typed := strings.ToUpper(strings.Replace(code, "-", " ", 1))
+	if HashRecoveryCode(typed) != hash {
+		t.Errorf("expected %q to match %q", typed, code)
+	}
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt ignores anything past 72 bytes
+)
+
+// User is an account that owns habits. Emails are stored normalised, see
+// NormalizeEmail, and passwords only as a bcrypt hash. TOTPSecret is set once
+// the user starts enrolling in two-factor authentication, which is only
+// enforced after TOTPEnabled is set. Admins can reset other users' 2FA.
+type User struct {
+	ID              uint      `gorm:"primaryKey"`
+	Email           string    `gorm:"not null;uniqueIndex"`
+	PasswordHash    string    `gorm:"not null" json:"-"`
+	TOTPSecret      string    `gorm:"not null;default:''" json:"-"`
+	TOTPEnabled     bool      `gorm:"not null;default:false"`
+	TOTPLastCounter int64     `gorm:"not null;default:0" json:"-"` // last time step used, so codes cannot be replayed
+	IsAdmin         bool      `gorm:"not null;default:false"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+// NormalizeEmail validates a bare email address and returns it trimmed and
+// lower-cased, so the same address always maps to the same account.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", fmt.Errorf("invalid email address")
+	}
+	return email, nil
+}
+
+// ValidatePassword checks a new password is long enough to be worth hashing and
+// short enough for bcrypt to use all of it.
+func ValidatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("password must be at most %d bytes", maxPasswordLength)
+	}
+	return nil
+}
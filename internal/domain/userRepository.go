@@ -0,0 +1,11 @@
+package domain
+
+type UserRepository interface {
+	Create(u *User) error
+	GetByID(id uint) (*User, error)
+	GetByEmail(email string) (*User, error)
+	GetByIdentity(issuer, subject string) (*User, error)
+	CreateWithIdentity(u *User, identity *UserIdentity) error
+	AddIdentity(identity *UserIdentity) error
+	Promote(id uint) error
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email   string
+		want    string
+		wantErr bool
+	}{
+		{"alice@example.com", "alice@example.com", false},
+		{"  Alice@Example.COM ", "alice@example.com", false},
+		{"", "", true},
+		{"alice", "", true},
+		{"Alice <alice@example.com>", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeEmail(tt.email)
+		if tt.wantErr && err == nil {
+			t.Errorf("%q: expected validation error, got nil", tt.email)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%q: expected no validation error, got %v", tt.email, err)
+		}
+		if got != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.email, tt.want, got)
+		}
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{"long enough", "correct horse", false},
+		{"too short", "hunter2", true},
+		{"too long for bcrypt", strings.Repeat("a", 73), true},
+	}
+
+	for _, tt := range tests {
+		err := ValidatePassword(tt.password)
+		if tt.wantErr && err == nil {
+			t.Errorf("%s: expected validation error, got nil", tt.name)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: expected no validation error, got %v", tt.name, err)
+		}
+	}
+}
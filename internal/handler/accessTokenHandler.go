@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+)
+
+type AccessTokenHandler struct {
+	Usecase *usecase.AccessTokenUsecase
+}
+
+// CreateAccessTokenApi creates a personal access token. The token is only
+// included in this response.
+func (handler *AccessTokenHandler) CreateAccessTokenApi(c *gin.Context) {
+	var input usecase.AccessTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to create access token: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to create access token"})
+		return
+	}
+
+	token, err := handler.Usecase.CreateAccessToken(currentUserID(c), input)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "token name") || strings.HasPrefix(err.Error(), "invalid scope") ||
+			err.Error() == "at least one scope is required" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		log.Printf("Error creating access token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create access token. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+func (handler *AccessTokenHandler) GetAccessTokensApi(c *gin.Context) {
+	tokens, err := handler.Usecase.GetAccessTokens(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve access tokens. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (handler *AccessTokenHandler) RevokeAccessTokenApi(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Printf("Error converting access token ID URL query: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid access token ID"})
+		return
+	}
+
+	if err := handler.Usecase.RevokeAccessToken(currentUserID(c), uint(id)); err != nil {
+		if err.Error() == "access token not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Access token not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke access token. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Access token revoked"})
+}
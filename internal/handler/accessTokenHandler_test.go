@@ -0,0 +1,73 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessTokenApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	body := `{"name": "cron", "scopes": ["habits:read", "completions:write"]}`
+	resp, err := ts.Client.Post(ts.URL+"/api/tokens", "application/json", bytes.NewBufferString(body))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var created struct {
+		ID    uint
+		Token string
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	resp.Body.Close()
+
+	request := func(method, path, body string) int {
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+created.Token)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{"Read Habits", http.MethodGet, "/api/habits", "", http.StatusOK},
+		{"Check In", http.MethodPatch, "/api/habits/1/mark_complete", "", http.StatusOK},
+		{"Create Habit Without habits:write", http.MethodPost, "/api/habits", `{"Name": "Run", "Frequency": "daily"}`, http.StatusForbidden},
+		{"Create Token With A Token", http.MethodPost, "/api/tokens", body, http.StatusForbidden},
+		{"Change Settings With A Token", http.MethodPut, "/api/settings", `{"timezone": "UTC"}`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantCode, request(tt.method, tt.path, tt.body))
+		})
+	}
+
+	// Listing shows when the token was last used, but never the token itself
+	_, listBody := ts.Get(t, "/api/tokens")
+	assert.Contains(t, string(listBody), `"LastUsedAt":"`)
+	assert.NotContains(t, string(listBody), created.Token)
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/tokens/%d", ts.URL, created.ID), nil)
+	assert.NoError(t, err)
+	resp, err = ts.Client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/habits", ""))
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+)
+
+const (
+	// userIDKey, sessionIDKey and accessTokenKey are the gin context keys holding
+	// the authenticated user's ID and the session or personal access token used
+	userIDKey      = "userID"
+	sessionIDKey   = "sessionID"
+	accessTokenKey = "accessToken"
+
+	// sessionCookie holds the session token for the browser UI
+	sessionCookie = "session"
+)
+
+// RequireUser authenticates the request and rejects it with 401 if that fails.
+// It accepts, in order, a personal access token or session token as a bearer
+// token (for scripts) or the session cookie (for the browser UI). Only access
+// tokens are limited to their scopes, see RequireScope.
+func RequireUser(uc *usecase.UserUsecase, tokenUc *usecase.AccessTokenUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user *domain.User
+		var session *domain.Session
+		var err error
+
+		header := c.GetHeader("Authorization")
+		switch {
+		case strings.HasPrefix(header, "Bearer "+domain.AccessTokenPrefix):
+			token, err := tokenUc.Authenticate(strings.TrimPrefix(header, "Bearer "))
+			if err != nil {
+				if err.Error() != "invalid access token" {
+					log.Printf("Error authenticating request: %v", err)
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate. Please try again later."})
+					return
+				}
+				unauthorized(c)
+				return
+			}
+
+			c.Set(userIDKey, token.UserID)
+			c.Set(accessTokenKey, token)
+			c.Next()
+			return
+		case strings.HasPrefix(header, "Bearer "):
+			user, session, err = uc.Authenticate(strings.TrimPrefix(header, "Bearer "))
+		default:
+			token, cookieErr := c.Cookie(sessionCookie)
+			if cookieErr != nil || token == "" {
+				unauthorized(c)
+				return
+			}
+			user, session, err = uc.Authenticate(token)
+		}
+
+		if err != nil {
+			if err.Error() != "invalid or expired session" {
+				log.Printf("Error authenticating request: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate. Please try again later."})
+				return
+			}
+			unauthorized(c)
+			return
+		}
+
+		c.Set(userIDKey, user.ID)
+		c.Set(sessionIDKey, session.ID)
+		c.Next()
+	}
+}
+
+// RequireScope lets a request authenticated with a personal access token through
+// only if the token has the scope. Other logins have every scope.
+func RequireScope(scope domain.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := currentAccessToken(c); token != nil && !token.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access token is missing the " + string(scope) + " scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// DenyAccessTokens keeps personal access tokens away from account management,
+// so a leaked token cannot be used to mint more tokens or change settings.
+func DenyAccessTokens() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if currentAccessToken(c) != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not available to access tokens, log in instead"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAdmin lets only admins through. The account registered with ADMIN_EMAIL is an admin.
+func RequireAdmin(uc *usecase.UserUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := uc.GetUser(currentUserID(c))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate. Please try again later."})
+			return
+		}
+		if !user.IsAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Bearer realm="habit-tracker"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+}
+
+// currentUserID returns the ID of the user authenticated by RequireUser
+func currentUserID(c *gin.Context) uint {
+	return c.GetUint(userIDKey)
+}
+
+// currentSessionID returns the ID of the session the request was authenticated
+// with, or 0 for an access token
+func currentSessionID(c *gin.Context) uint {
+	return c.GetUint(sessionIDKey)
+}
+
+// currentAccessToken returns the personal access token the request was
+// authenticated with, or nil for any other login
+func currentAccessToken(c *gin.Context) *domain.AccessToken {
+	token, ok := c.Get(accessTokenKey)
+	if !ok {
+		return nil
+	}
+	return token.(*domain.AccessToken)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+)
+
+type ChallengeHandler struct {
+	Usecase *usecase.ChallengeUsecase
+}
+
+func (handler *ChallengeHandler) CreateChallengeApi(c *gin.Context) {
+	var input usecase.CreateChallengeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to create challenge: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to create challenge"})
+		return
+	}
+
+	challenge, err := handler.Usecase.CreateChallenge(currentUserID(c), input)
+	if err != nil {
+		switch err.Error() {
+		case "failed to create challenge":
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create challenge. Please try again later."})
+		case "invalid challenge date":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid challenge date, expected YYYY-MM-DD"})
+		default:
+			// Everything else is a problem with the challenge or its habit definition
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, challenge)
+}
+
+func (handler *ChallengeHandler) GetChallengesApi(c *gin.Context) {
+	challenges, err := handler.Usecase.GetChallenges()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve challenges. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, challenges)
+}
+
+func (handler *ChallengeHandler) GetChallengeApi(c *gin.Context) {
+	id, ok := idParam(c, "id", "challenge")
+	if !ok {
+		return
+	}
+
+	challenge, err := handler.Usecase.GetChallenge(id)
+	if err != nil {
+		challengeError(c, err, "Failed to retrieve challenge. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, challenge)
+}
+
+func (handler *ChallengeHandler) DeleteChallengeApi(c *gin.Context) {
+	id, ok := idParam(c, "id", "challenge")
+	if !ok {
+		return
+	}
+
+	if err := handler.Usecase.DeleteChallenge(currentUserID(c), id); err != nil {
+		challengeError(c, err, "Failed to delete challenge. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Challenge deleted"})
+}
+
+// JoinChallengeApi joins the challenge and responds with the habit created to
+// track it, which is completed like any other habit.
+func (handler *ChallengeHandler) JoinChallengeApi(c *gin.Context) {
+	id, ok := idParam(c, "id", "challenge")
+	if !ok {
+		return
+	}
+
+	habit, err := handler.Usecase.JoinChallenge(currentUserID(c), id)
+	if err != nil {
+		challengeError(c, err, "Failed to join challenge. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusCreated, habit)
+}
+
+func (handler *ChallengeHandler) LeaveChallengeApi(c *gin.Context) {
+	id, ok := idParam(c, "id", "challenge")
+	if !ok {
+		return
+	}
+
+	if err := handler.Usecase.LeaveChallenge(currentUserID(c), id); err != nil {
+		challengeError(c, err, "Failed to leave challenge. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left challenge"})
+}
+
+// GetLeaderboardApi ranks the challenge's participants, by completed periods
+// unless ?sort=current or ?sort=longest ranks them by streak. Only participants
+// may see it.
+func (handler *ChallengeHandler) GetLeaderboardApi(c *gin.Context) {
+	id, ok := idParam(c, "id", "challenge")
+	if !ok {
+		return
+	}
+
+	entries, err := handler.Usecase.GetLeaderboard(currentUserID(c), id, c.Query("sort"))
+	if err != nil {
+		challengeError(c, err, "Failed to retrieve leaderboard. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// challengeError responds to an error of the challenge usecase, falling back to
+// a 500 with message for unexpected errors.
+func challengeError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "challenge not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+	case "not a participant":
+		c.JSON(http.StatusNotFound, gin.H{"error": "You have not joined this challenge"})
+	case "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the creator of a challenge can do this"})
+	case "already joined":
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already joined this challenge"})
+	case "challenge has ended":
+		c.JSON(http.StatusConflict, gin.H{"error": "Challenge has ended"})
+	case "invalid leaderboard sort":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid leaderboard sort, use completions, current or longest"})
+	default:
+		log.Printf("Error handling challenge: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,86 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChallengeApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	creator, other := ts.Client, ts.Login(t, testutils.OtherUserEmail, testutils.TestPassword)
+
+	request := func(t *testing.T, client *http.Client, method, path, body string, out interface{}) int {
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		if out != nil {
+			json.NewDecoder(resp.Body).Decode(out)
+		}
+		return resp.StatusCode
+	}
+
+	today := time.Now().UTC()
+	body := fmt.Sprintf(`{"name": "Walk 10k", "frequency": "daily", "target_value": 10000, "unit": "steps", "start_date": %q, "end_date": %q}`,
+		today.AddDate(0, 0, -7).Format("2006-01-02"), today.AddDate(0, 0, 7).Format("2006-01-02"))
+
+	var challenge struct{ ID uint }
+	assert.Equal(t, http.StatusCreated, request(t, creator, http.MethodPost, "/api/challenges", body, &challenge))
+	path := fmt.Sprintf("/api/challenges/%d", challenge.ID)
+
+	t.Run("Invalid Challenge", func(t *testing.T) {
+		invalid := `{"name": "Walk", "frequency": "daily", "start_date": "2026-11-30", "end_date": "2026-11-01"}`
+		assert.Equal(t, http.StatusBadRequest, request(t, creator, http.MethodPost, "/api/challenges", invalid, nil))
+		assert.Equal(t, http.StatusNotFound, request(t, creator, http.MethodGet, "/api/challenges/999", "", nil))
+	})
+
+	t.Run("Join And Complete", func(t *testing.T) {
+		var habit struct {
+			ID   uint
+			Unit string
+		}
+		assert.Equal(t, http.StatusCreated, request(t, other, http.MethodPost, path+"/join", "", &habit))
+		assert.Equal(t, "steps", habit.Unit)
+		assert.Equal(t, http.StatusConflict, request(t, other, http.MethodPost, path+"/join", "", nil))
+		assert.Equal(t, http.StatusCreated, request(t, creator, http.MethodPost, path+"/join", "", nil))
+
+		complete := fmt.Sprintf("/api/habits/%d/mark_complete", habit.ID)
+		assert.Equal(t, http.StatusOK, request(t, other, http.MethodPatch, complete, `{"quantity": 12000}`, nil))
+	})
+
+	t.Run("Leaderboard", func(t *testing.T) {
+		var entries []struct {
+			Rank        int
+			Email       string
+			Completions int
+		}
+		assert.Equal(t, http.StatusOK, request(t, creator, http.MethodGet, path+"/leaderboard", "", &entries))
+		assert.Equal(t, 2, len(entries))
+		assert.Equal(t, testutils.OtherUserEmail, entries[0].Email)
+		assert.Equal(t, 1, entries[0].Completions)
+		assert.Equal(t, 2, entries[1].Rank)
+
+		assert.Equal(t, http.StatusBadRequest, request(t, creator, http.MethodGet, path+"/leaderboard?sort=fastest", "", nil))
+	})
+
+	t.Run("Leave And Delete", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(t, other, http.MethodPost, path+"/leave", "", nil))
+		assert.Equal(t, http.StatusNotFound, request(t, other, http.MethodPost, path+"/leave", "", nil))
+		assert.Equal(t, http.StatusNotFound, request(t, other, http.MethodGet, path+"/leaderboard", "", nil))
+
+		assert.Equal(t, http.StatusForbidden, request(t, other, http.MethodDelete, path, "", nil))
+		assert.Equal(t, http.StatusOK, request(t, creator, http.MethodDelete, path, "", nil))
+		assert.Equal(t, http.StatusNotFound, request(t, creator, http.MethodGet, path, "", nil))
+	})
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jt00721/habit-tracker/internal/domain"
@@ -14,6 +16,36 @@ type HabitHandler struct {
 	Usecase *usecase.HabitUsecase
 }
 
+const (
+	defaultOccurrences = 5
+	maxOccurrences     = 50
+)
+
+// invalidHabitErrors are the prefixes of the habit validation errors the client can fix
+var invalidHabitErrors = []string{
+	"times per period",
+	"interval days",
+	"invalid weekday",
+	"invalid day of month",
+	"weekdays can only",
+	"month days can only",
+	"rrule ",
+	"invalid rrule",
+	"duplicate rrule",
+	"unsupported rrule",
+	"target value",
+	"unit requires",
+}
+
+func isInvalidHabitError(err error) bool {
+	for _, prefix := range invalidHabitErrors {
+		if strings.HasPrefix(err.Error(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func (handler *HabitHandler) CreateHabitApi(c *gin.Context) {
 	var habit domain.Habit
 	if err := c.ShouldBindJSON(&habit); err != nil {
@@ -22,8 +54,13 @@ func (handler *HabitHandler) CreateHabitApi(c *gin.Context) {
 		return
 	}
 
-	err := handler.Usecase.CreateHabit(&habit)
+	err := handler.Usecase.CreateHabit(currentUserID(c), &habit)
 	if err != nil {
+		if isInvalidHabitError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		log.Printf("Error creating habit: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create habit. Please try again later."})
 		return
@@ -33,7 +70,7 @@ func (handler *HabitHandler) CreateHabitApi(c *gin.Context) {
 }
 
 func (handler *HabitHandler) GetAllHabitsApi(c *gin.Context) {
-	habits, err := handler.Usecase.GetAllHabits()
+	habits, err := handler.Usecase.GetAllHabits(currentUserID(c))
 	if err != nil {
 		log.Printf("Error retrieving all habits: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -61,7 +98,16 @@ func (handler *HabitHandler) GetHabitByIDApi(c *gin.Context) {
 		return
 	}
 
-	habit, err := handler.Usecase.GetHabitByID(uint(id))
+	occurrences := defaultOccurrences
+	if value := c.Query("occurrences"); value != "" {
+		occurrences, err = strconv.Atoi(value)
+		if err != nil || occurrences < 0 || occurrences > maxOccurrences {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid number of occurrences"})
+			return
+		}
+	}
+
+	habit, err := handler.Usecase.GetHabitDetails(currentUserID(c), uint(id), occurrences)
 	if err != nil {
 		log.Printf("Error retrieving habit with ID(%d): %v", id, err)
 		c.JSON(http.StatusNotFound, gin.H{
@@ -91,8 +137,18 @@ func (handler *HabitHandler) UpdateHabitApi(c *gin.Context) {
 	}
 
 	habit.ID = uint(id)
-	err = handler.Usecase.UpdateHabit(&habit)
+	err = handler.Usecase.UpdateHabit(currentUserID(c), &habit)
 	if err != nil {
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to change this habit"})
+			return
+		}
+
+		if isInvalidHabitError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		log.Printf("Error updating habit with ID(%d): %v", id, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update habit. Please try again later."})
 		return
@@ -109,8 +165,13 @@ func (handler *HabitHandler) DeleteHabitApi(c *gin.Context) {
 		return
 	}
 
-	err = handler.Usecase.DeleteHabit(uint(id))
+	err = handler.Usecase.DeleteHabit(currentUserID(c), uint(id))
 	if err != nil {
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to change this habit"})
+			return
+		}
+
 		log.Printf("Error deleting habit with ID(%d): %v", id, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete habit. Please try again later."})
 		return
@@ -127,14 +188,51 @@ func (handler *HabitHandler) MarkHabitCompletedApi(c *gin.Context) {
 		return
 	}
 
-	err = handler.Usecase.MarkCompleted(uint(id))
+	var input usecase.CompletionInput
+	if err := c.ShouldBindJSON(&input); err != nil && err != io.EOF {
+		log.Printf("Error binding json request body to mark habit completed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to mark habit completed"})
+		return
+	}
+
+	err = handler.Usecase.MarkCompleted(currentUserID(c), uint(id), input)
 	if err != nil {
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to change this habit"})
+			return
+		}
+
 		if err.Error() == "habit not found" {
 			log.Printf("Error: Tried to complete non-existing habit with ID(%d)", id)
 			c.JSON(http.StatusNotFound, gin.H{"error": "Habit not found"})
 			return
 		}
 
+		if err.Error() == "completion time cannot be in the future" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Completion time cannot be in the future"})
+			return
+		}
+
+		if err.Error() == "completion time cannot be before the habit was created" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Completion time cannot be before the habit was created"})
+			return
+		}
+
+		if err.Error() == "invalid completion date" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid completion date, expected YYYY-MM-DD"})
+			return
+		}
+
+		if err.Error() == "quantity must be positive" || err.Error() == "quantity is required for measurable habits" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err.Error() == "quit habits cannot be completed, log a relapse instead" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Quit habits cannot be completed, log a relapse instead"})
+			return
+		}
+
 		log.Printf("Error marking habit with ID(%d) as complete: %v", id, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark habit as completed. Please try again later."})
 		return
@@ -143,9 +241,56 @@ func (handler *HabitHandler) MarkHabitCompletedApi(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Habit Completed"})
 }
 
+// MarkHabitsCompletedApi records a batch of completions given as a JSON list of
+// {"habit_id", "date", "quantity"} items and returns the outcome of each item.
+func (handler *HabitHandler) MarkHabitsCompletedApi(c *gin.Context) {
+	var inputs []usecase.BulkCompletionInput
+	if err := c.ShouldBindJSON(&inputs); err != nil {
+		log.Printf("Error binding json request body to mark habits completed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to mark habits completed"})
+		return
+	}
+
+	results, err := handler.Usecase.MarkCompletedBulk(currentUserID(c), inputs)
+	if err != nil {
+		if err.Error() == "no completions given" || strings.HasPrefix(err.Error(), "too many completions") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		log.Printf("Error marking habits as complete: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark habits as completed. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetTodayApi returns the checklist of habits due, overdue, completed and skipped
+// today, or on the day given as ?date=YYYY-MM-DD.
+func (handler *HabitHandler) GetTodayApi(c *gin.Context) {
+	today, err := handler.Usecase.GetToday(currentUserID(c), c.Query("date"))
+	if err != nil {
+		if err.Error() == "invalid date" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+			return
+		}
+
+		log.Printf("Error retrieving today's habits: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve today's habits. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, today)
+}
+
 func (handler *HabitHandler) GetStreaksApi(c *gin.Context) {
-	habits, err := handler.Usecase.GetStreaks()
+	habits, err := handler.Usecase.GetStreaks(currentUserID(c), c.Query("sort"))
 	if err != nil {
+		if err.Error() == "invalid streak sort" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid streak sort, use current or longest"})
+			return
+		}
 		log.Printf("Error retrieving all habits with streaks: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve all habits with streaks. Please try again later.",
@@ -163,3 +308,190 @@ func (handler *HabitHandler) GetStreaksApi(c *gin.Context) {
 
 	c.JSON(http.StatusOK, habits)
 }
+
+func (handler *HabitHandler) GetCompletionsApi(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Printf("Error converting habit ID URL query: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid habit ID"})
+		return
+	}
+
+	completions, err := handler.Usecase.GetCompletions(currentUserID(c), uint(id))
+	if err != nil {
+		if err.Error() == "habit not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Habit not found"})
+			return
+		}
+
+		log.Printf("Error retrieving completions for habit with ID(%d): %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve habit completions. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, completions)
+}
+
+func (handler *HabitHandler) RemoveCompletionApi(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Printf("Error converting habit ID URL query: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid habit ID"})
+		return
+	}
+
+	err = handler.Usecase.RemoveCompletion(currentUserID(c), uint(id), c.Param("date"))
+	if err != nil {
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to change this habit"})
+			return
+		}
+
+		if err.Error() == "habit not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Habit not found"})
+			return
+		}
+
+		if err.Error() == "invalid completion date" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid completion date, expected YYYY-MM-DD"})
+			return
+		}
+
+		if err.Error() == "completion not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No completion found on that date"})
+			return
+		}
+
+		log.Printf("Error removing completion from habit with ID(%d): %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove completion. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Completion removed"})
+}
+
+func (handler *HabitHandler) LogRelapseApi(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Printf("Error converting habit ID URL query: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid habit ID"})
+		return
+	}
+
+	var input usecase.RelapseInput
+	if err := c.ShouldBindJSON(&input); err != nil && err != io.EOF {
+		log.Printf("Error binding json request body to log relapse: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to log relapse"})
+		return
+	}
+
+	err = handler.Usecase.LogRelapse(currentUserID(c), uint(id), input)
+	if err != nil {
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to change this habit"})
+			return
+		}
+
+		if err.Error() == "habit not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Habit not found"})
+			return
+		}
+
+		if err.Error() == "relapses can only be logged for quit habits" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Relapses can only be logged for quit habits"})
+			return
+		}
+
+		if err.Error() == "completion time cannot be in the future" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Relapse time cannot be in the future"})
+			return
+		}
+
+		if err.Error() == "completion time cannot be before the habit was created" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Relapse time cannot be before the habit was created"})
+			return
+		}
+
+		if err.Error() == "invalid completion date" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid relapse date, expected YYYY-MM-DD"})
+			return
+		}
+
+		log.Printf("Error logging relapse for habit with ID(%d): %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log relapse. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Relapse logged"})
+}
+
+func (handler *HabitHandler) GetRelapsesApi(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Printf("Error converting habit ID URL query: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid habit ID"})
+		return
+	}
+
+	relapses, err := handler.Usecase.GetRelapses(currentUserID(c), uint(id))
+	if err != nil {
+		if err.Error() == "habit not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Habit not found"})
+			return
+		}
+
+		log.Printf("Error retrieving relapses for habit with ID(%d): %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve habit relapses. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, relapses)
+}
+
+func (handler *HabitHandler) StartTimerApi(c *gin.Context) {
+	handler.timerApi(c, handler.Usecase.StartTimer)
+}
+
+func (handler *HabitHandler) PauseTimerApi(c *gin.Context) {
+	handler.timerApi(c, handler.Usecase.PauseTimer)
+}
+
+func (handler *HabitHandler) StopTimerApi(c *gin.Context) {
+	handler.timerApi(c, handler.Usecase.StopTimer)
+}
+
+func (handler *HabitHandler) GetTimerApi(c *gin.Context) {
+	handler.timerApi(c, handler.Usecase.GetTimer)
+}
+
+// timerApi runs a timer action for the habit in the URL and responds with the resulting session.
+func (handler *HabitHandler) timerApi(c *gin.Context, action func(userID, id uint) (*domain.TimerSession, error)) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Printf("Error converting habit ID URL query: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid habit ID"})
+		return
+	}
+
+	session, err := action(currentUserID(c), uint(id))
+	if err != nil {
+		switch err.Error() {
+		case "habit not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Habit not found"})
+		case "permission denied":
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to change this habit"})
+		case "no active timer":
+			c.JSON(http.StatusNotFound, gin.H{"error": "No active timer for this habit"})
+		case "habit is not timed":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Habit is not timed, set a target in minutes to use the timer"})
+		case "timer already running", "no running timer":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Error updating timer for habit with ID(%d): %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update timer. Please try again later."})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
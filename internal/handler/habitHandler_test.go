@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	testutils "github.com/jt00721/habit-tracker/test"
 	"github.com/stretchr/testify/assert"
@@ -25,11 +27,61 @@ func TestCreateHabitApi(t *testing.T) {
 			body:     `{"name": "Read a book", "frequency": "daily"}`,
 			wantCode: http.StatusCreated,
 		},
+		{
+			name:     "Valid Create Habit With Schedule",
+			body:     `{"name": "Gym", "frequency": "weekly", "weekdays": "mon,wed,fri"}`,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "Valid Create Habit With RRule",
+			body:     `{"name": "Swim", "rrule": "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,SA"}`,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "Valid Create Measurable Habit",
+			body:     `{"name": "Drink water", "frequency": "daily", "targetvalue": 8, "unit": "glasses"}`,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "Invalid Habit RRule",
+			body:     `{"name": "Swim", "frequency": "weekly", "rrule": "FREQ=WEEKLY;BYHOUR=7"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Habit RRule Frequency Mismatch",
+			body:     `{"name": "Swim", "frequency": "daily", "rrule": "FREQ=WEEKLY;BYDAY=TU"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Habit Target Value",
+			body:     `{"name": "Drink water", "frequency": "daily", "targetvalue": -8}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Habit Unit Without Target",
+			body:     `{"name": "Drink water", "frequency": "daily", "unit": "glasses"}`,
+			wantCode: http.StatusBadRequest,
+		},
 		{
 			name:     "Invalid JSON",
 			body:     `{"name": "Missing quote, "frequency": "daily"}`,
 			wantCode: http.StatusBadRequest,
 		},
+		{
+			name:     "Invalid Habit Schedule",
+			body:     `{"name": "Gym", "frequency": "daily", "monthdays": "1,15"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Habit Interval Days",
+			body:     `{"name": "Stretch", "frequency": "daily", "intervaldays": 400}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Habit Times Per Period",
+			body:     `{"name": "Gym", "frequency": "weekly", "timesperperiod": -1}`,
+			wantCode: http.StatusBadRequest,
+		},
 		{
 			name:     "Invalid Habit Name",
 			body:     `{"name": "", "frequency": "daily"}`,
@@ -45,7 +97,7 @@ func TestCreateHabitApi(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// payloadBytes, _ := json.Marshal(tt.habitPayload)
-			resp, err := http.Post(ts.URL+"/api/habits", "application/json", bytes.NewBufferString(tt.body))
+			resp, err := ts.Client.Post(ts.URL+"/api/habits", "application/json", bytes.NewBufferString(tt.body))
 			assert.NoError(t, err)
 			assert.Equal(t, tt.wantCode, resp.StatusCode)
 
@@ -71,7 +123,7 @@ func TestGetAllApi(t *testing.T) {
 	ts, _, teardown := testutils.NewTestServer(t)
 	defer teardown()
 
-	resp, err := http.Get(ts.URL + "/api/habits")
+	resp, err := ts.Client.Get(ts.URL + "/api/habits")
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
@@ -90,6 +142,16 @@ func TestGetHabitByIDApi(t *testing.T) {
 			id:       "1",
 			wantCode: http.StatusOK,
 		},
+		{
+			name:     "Valid Get Habit With Occurrences",
+			id:       "1?occurrences=10",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Too Many Occurrences",
+			id:       "1?occurrences=500",
+			wantCode: http.StatusBadRequest,
+		},
 		{
 			name:     "No Habit ID",
 			id:       " ",
@@ -105,7 +167,7 @@ func TestGetHabitByIDApi(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// payloadBytes, _ := json.Marshal(tt.habitPayload)
-			resp, err := http.Get(ts.URL + "/api/habits/" + tt.id)
+			resp, err := ts.Client.Get(ts.URL + "/api/habits/" + tt.id)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.wantCode, resp.StatusCode)
 		})
@@ -130,7 +192,7 @@ func TestUpdateHabitApi(t *testing.T) {
 
 	createPayload := map[string]string{"name": "Initial", "frequency": "daily"}
 	createBytes, _ := json.Marshal(createPayload)
-	resp, err := http.Post(ts.URL+"/api/habits", "application/json", bytes.NewBuffer(createBytes))
+	resp, err := ts.Client.Post(ts.URL+"/api/habits", "application/json", bytes.NewBuffer(createBytes))
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 
@@ -169,6 +231,24 @@ func TestUpdateHabitApi(t *testing.T) {
 			body:       `{"name": "Ghost", "frequency": "daily"}`,
 			wantStatus: http.StatusInternalServerError,
 		},
+		{
+			name:       "Invalid Update Habit Schedule",
+			id:         fmt.Sprintf("%d", habitID),
+			body:       `{"name": "Updated", "frequency": "daily", "weekdays": "mon"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid Update Habit RRule",
+			id:         fmt.Sprintf("%d", habitID),
+			body:       `{"name": "Updated", "rrule": "FREQ=HOURLY"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid Update Habit Target Value",
+			id:         fmt.Sprintf("%d", habitID),
+			body:       `{"name": "Updated", "frequency": "weekly", "targetvalue": -1}`,
+			wantStatus: http.StatusBadRequest,
+		},
 		{
 			name:       "Invalid Update Habit Name",
 			id:         fmt.Sprintf("%d", habitID),
@@ -189,8 +269,7 @@ func TestUpdateHabitApi(t *testing.T) {
 			req, _ := http.NewRequest(http.MethodPut, url, bytes.NewBufferString(tt.body))
 			req.Header.Set("Content-Type", "application/json")
 
-			client := &http.Client{}
-			resp, err := client.Do(req)
+			resp, err := ts.Client.Do(req)
 			assert.NoError(t, err)
 			defer resp.Body.Close()
 
@@ -248,8 +327,7 @@ func TestDeleteHabitApi(t *testing.T) {
 			req, err := http.NewRequest(http.MethodDelete, ts.URL+"/api/habits/"+tt.id, nil)
 			assert.NoError(t, err)
 
-			client := &http.Client{}
-			resp, err := client.Do(req)
+			resp, err := ts.Client.Do(req)
 			assert.NoError(t, err)
 
 			assert.Equal(t, tt.wantCode, resp.StatusCode)
@@ -279,6 +357,7 @@ func TestMarkHabitCompletedApi(t *testing.T) {
 	tests := []struct {
 		name     string
 		id       string
+		body     string
 		wantCode int
 	}{
 		{
@@ -286,6 +365,42 @@ func TestMarkHabitCompletedApi(t *testing.T) {
 			id:       "1",
 			wantCode: http.StatusOK,
 		},
+		{
+			name:     "Backdated Completion Date",
+			id:       "1",
+			body:     `{"date": "2024-01-01"}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Backdated Completion Timestamp",
+			id:       "1",
+			body:     `{"completed_at": "2024-01-02T21:30:00Z"}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Future Completion",
+			id:       "1",
+			body:     `{"completed_at": "2999-01-01T00:00:00Z"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Completion Before Habit Was Created",
+			id:       "1",
+			body:     `{"date": "2023-01-01"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Completion Date",
+			id:       "1",
+			body:     `{"date": "01/01/2024"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Negative Quantity",
+			id:       "1",
+			body:     `{"quantity": -2}`,
+			wantCode: http.StatusBadRequest,
+		},
 		{
 			name:     "No Habit ID",
 			id:       " ",
@@ -301,11 +416,10 @@ func TestMarkHabitCompletedApi(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// payloadBytes, _ := json.Marshal(tt.habitPayload)
-			req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/habits/"+tt.id+"/mark_complete", nil)
+			req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/habits/"+tt.id+"/mark_complete", bytes.NewBufferString(tt.body))
 			assert.NoError(t, err)
 
-			client := &http.Client{}
-			resp, err := client.Do(req)
+			resp, err := ts.Client.Do(req)
 			assert.NoError(t, err)
 
 			assert.Equal(t, tt.wantCode, resp.StatusCode)
@@ -341,6 +455,55 @@ func TestMarkHabitCompletedApi(t *testing.T) {
 	// assert.Equal(t, float64(11), habit["TotalCompletions"], habit)
 }
 
+func TestMarkHabitsCompletedApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Valid Batch",
+			body:     `[{"habit_id": 1}, {"habit_id": 2, "date": "2024-01-01"}]`,
+			wantCode: http.StatusOK,
+			wantBody: `"Completed":true`,
+		},
+		{
+			name:     "Batch With Unknown Habit",
+			body:     `[{"habit_id": 1}, {"habit_id": 999}]`,
+			wantCode: http.StatusOK,
+			wantBody: `"Error":"habit not found"`,
+		},
+		{
+			name:     "Empty Batch",
+			body:     `[]`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Body",
+			body:     `{"habit_id": 1}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.Client.Post(ts.URL+"/api/habits/mark_complete", "application/json", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), tt.wantBody)
+		})
+	}
+}
+
 func TestGetStreaksApi(t *testing.T) {
 	ts, _, teardown := testutils.NewTestServer(t)
 	defer teardown()
@@ -351,21 +514,32 @@ func TestGetStreaksApi(t *testing.T) {
 		"current_streak": 0,
 	}
 	payload2, _ := json.Marshal(habitNoStreak)
-	http.Post(ts.URL+"/api/habits", "application/json", bytes.NewBuffer(payload2))
+	ts.Client.Post(ts.URL+"/api/habits", "application/json", bytes.NewBuffer(payload2))
 
 	tests := []struct {
 		name       string
+		query      string
 		wantStatus int
 	}{
 		{
 			name:       "Get streaks with results",
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "Get streaks sorted by longest streak",
+			query:      "?sort=longest",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Invalid sort",
+			query:      "?sort=name",
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resp, err := http.Get(ts.URL + "/api/habits/streaks")
+			resp, err := ts.Client.Get(ts.URL + "/api/habits/streaks" + tt.query)
 			assert.NoError(t, err)
 			defer resp.Body.Close()
 
@@ -383,3 +557,227 @@ func TestGetStreaksApi(t *testing.T) {
 	// assert.Equal(t, "Test Habit", habits[0]["Name"], habits)
 	// assert.Equal(t, "daily", habits[0]["Frequency"], habits)
 }
+
+func TestGetCompletionsApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "Valid Get Completions",
+			id:       "1",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "No Habit ID",
+			id:       " ",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Habit ID",
+			id:       "3",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.Client.Get(ts.URL + "/api/habits/" + tt.id + "/completions")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestRemoveCompletionApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	today := time.Now().Format("2006-01-02")
+
+	tests := []struct {
+		name     string
+		id       string
+		date     string
+		wantCode int
+	}{
+		{
+			name:     "Valid Remove Completion",
+			id:       "1",
+			date:     today,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Already Removed Completion",
+			id:       "1",
+			date:     today,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Invalid Date",
+			id:       "1",
+			date:     "yesterday",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Habit ID",
+			id:       "3",
+			date:     today,
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodDelete, ts.URL+"/api/habits/"+tt.id+"/completions/"+tt.date, nil)
+			assert.NoError(t, err)
+
+			resp, err := ts.Client.Do(req)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestTimerApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	// Habit 2 is a timed habit
+	resp, err := ts.Client.Post(ts.URL+"/api/habits", "application/json", bytes.NewBufferString(`{"name": "Meditate", "frequency": "daily", "targetvalue": 20, "unit": "minutes"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		wantCode int
+	}{
+		{"Habit Is Not Timed", http.MethodPost, "/api/habits/1/timer/start", http.StatusBadRequest},
+		{"No Active Timer", http.MethodGet, "/api/habits/2/timer", http.StatusNotFound},
+		{"Start Timer", http.MethodPost, "/api/habits/2/timer/start", http.StatusOK},
+		{"Timer Already Running", http.MethodPost, "/api/habits/2/timer/start", http.StatusConflict},
+		{"Get Active Timer", http.MethodGet, "/api/habits/2/timer", http.StatusOK},
+		{"Pause Timer", http.MethodPost, "/api/habits/2/timer/pause", http.StatusOK},
+		{"Timer Already Paused", http.MethodPost, "/api/habits/2/timer/pause", http.StatusConflict},
+		{"Resume Timer", http.MethodPost, "/api/habits/2/timer/start", http.StatusOK},
+		{"Stop Timer", http.MethodPost, "/api/habits/2/timer/stop", http.StatusOK},
+		{"Invalid Habit ID", http.MethodPost, "/api/habits/5/timer/start", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, ts.URL+tt.path, nil)
+			assert.NoError(t, err)
+
+			resp, err := ts.Client.Do(req)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestRelapseApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	// Habit 2 is a quit habit
+	resp, err := ts.Client.Post(ts.URL+"/api/habits", "application/json", bytes.NewBufferString(`{"name": "No smoking", "kind": "quit"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tests := []struct {
+		name     string
+		id       string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "Valid Log Relapse",
+			id:       "2",
+			body:     `{"note": "stressful day"}`,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "Relapse Before Habit Was Created",
+			id:       "2",
+			body:     `{"date": "2024-01-01"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Future Relapse",
+			id:       "2",
+			body:     `{"occurred_at": "2999-01-01T00:00:00Z"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Not A Quit Habit",
+			id:       "1",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Habit ID",
+			id:       "5",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.Client.Post(ts.URL+"/api/habits/"+tt.id+"/relapses", "application/json", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+
+	resp, err = ts.Client.Get(ts.URL + "/api/habits/2/relapses")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/habits/2/mark_complete", nil)
+	assert.NoError(t, err)
+	resp, err = ts.Client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGetTodayApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+	}{
+		{
+			name:     "Today",
+			path:     "/api/today",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Given Date",
+			path:     "/api/today?date=2024-01-01",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Invalid Date",
+			path:     "/api/today?date=yesterday",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := ts.Get(t, tt.path)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+)
+
+type JobHandler struct {
+	StreakResetUc *usecase.StreakResetUsecase
+}
+
+// ResetStreaksApi runs the streak reset job straight away instead of waiting for the scheduler.
+func (handler *JobHandler) ResetStreaksApi(c *gin.Context) {
+	result, err := handler.StreakResetUc.ResetLapsedStreaks()
+	if err != nil {
+		log.Printf("Error resetting lapsed streaks: %v", err)
+		if err.Error() == "streak reset already running" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Streak reset is already running"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset streaks. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
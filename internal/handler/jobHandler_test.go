@@ -0,0 +1,29 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResetStreaksApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	resp, err := ts.Client.Post(ts.URL+"/api/admin/jobs/streak-reset", "application/json", nil)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Only admins may run the job across every user's habits
+	other := ts.Login(t, testutils.OtherUserEmail, testutils.TestPassword)
+
+	resp, err = other.Post(ts.URL+"/api/admin/jobs/streak-reset", "application/json", nil)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
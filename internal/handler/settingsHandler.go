@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+)
+
+type SettingsHandler struct {
+	Usecase *usecase.SettingsUsecase
+}
+
+func (handler *SettingsHandler) GetSettingsApi(c *gin.Context) {
+	settings, err := handler.Usecase.GetSettings(currentUserID(c))
+	if err != nil {
+		log.Printf("Error retrieving settings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve settings. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (handler *SettingsHandler) UpdateSettingsApi(c *gin.Context) {
+	var input usecase.SettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to update settings: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to update settings"})
+		return
+	}
+
+	settings, err := handler.Usecase.UpdateSettings(currentUserID(c), input)
+	if err != nil {
+		switch err.Error() {
+		case "failed to retrieve existing settings", "failed to update settings":
+			log.Printf("Error updating settings: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings. Please try again later."})
+		default:
+			log.Printf("Invalid settings: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
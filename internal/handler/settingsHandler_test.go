@@ -0,0 +1,83 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSettingsApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	resp, err := ts.Client.Get(ts.URL + "/api/settings")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestUpdateSettingsApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "Valid Update Settings",
+			body:     `{"timezone": "America/New_York", "weekstart": 0, "dayendhour": 3}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Freeze Settings Only",
+			body:     `{"freezeearninterval": 5, "maxstreakfreezes": 3}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Invalid JSON",
+			body:     `{"timezone": "Missing quote}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Timezone",
+			body:     `{"timezone": "Not/A_Zone"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Day End Hour",
+			body:     `{"timezone": "UTC", "dayendhour": 20}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPut, ts.URL+"/api/settings", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := ts.Client.Do(req)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+
+	// Settings belong to the user who saved them
+	other := ts.Login(t, testutils.OtherUserEmail, testutils.TestPassword)
+
+	resp, err := other.Get(ts.URL + "/api/settings")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var settings domain.Settings
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&settings))
+	assert.Equal(t, "UTC", settings.Timezone)
+	assert.Equal(t, uint(2), settings.UserID)
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+)
+
+type SharingHandler struct {
+	Usecase *usecase.SharingUsecase
+}
+
+// ShareHabitApi invites another user to the habit as a viewer or co-owner.
+func (handler *SharingHandler) ShareHabitApi(c *gin.Context) {
+	id, ok := idParam(c, "id", "habit")
+	if !ok {
+		return
+	}
+
+	var input usecase.ShareInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to share habit: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to share habit"})
+		return
+	}
+
+	partner, err := handler.Usecase.ShareHabit(currentUserID(c), id, input)
+	if err != nil {
+		sharingError(c, err, "Failed to share habit. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusCreated, partner)
+}
+
+func (handler *SharingHandler) GetPartnersApi(c *gin.Context) {
+	id, ok := idParam(c, "id", "habit")
+	if !ok {
+		return
+	}
+
+	partners, err := handler.Usecase.GetPartners(currentUserID(c), id)
+	if err != nil {
+		sharingError(c, err, "Failed to retrieve partners. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, partners)
+}
+
+func (handler *SharingHandler) UpdatePartnerApi(c *gin.Context) {
+	id, ok := idParam(c, "id", "habit")
+	if !ok {
+		return
+	}
+	userID, ok := idParam(c, "user_id", "user")
+	if !ok {
+		return
+	}
+
+	var input usecase.RoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to update partner: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to update partner"})
+		return
+	}
+
+	if err := handler.Usecase.UpdatePartnerRole(currentUserID(c), id, userID, input); err != nil {
+		sharingError(c, err, "Failed to update partner. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Partner updated"})
+}
+
+// RemovePartnerApi removes a partner from the habit. Partners can use it with
+// their own user ID to leave.
+func (handler *SharingHandler) RemovePartnerApi(c *gin.Context) {
+	id, ok := idParam(c, "id", "habit")
+	if !ok {
+		return
+	}
+	userID, ok := idParam(c, "user_id", "user")
+	if !ok {
+		return
+	}
+
+	if err := handler.Usecase.RemovePartner(currentUserID(c), id, userID); err != nil {
+		sharingError(c, err, "Failed to remove partner. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Partner removed"})
+}
+
+func (handler *SharingHandler) ReactApi(c *gin.Context) {
+	id, ok := idParam(c, "completion_id", "completion")
+	if !ok {
+		return
+	}
+
+	var input usecase.ReactionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to react to completion: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to react to completion"})
+		return
+	}
+
+	reaction, err := handler.Usecase.React(currentUserID(c), id, input)
+	if err != nil {
+		sharingError(c, err, "Failed to add reaction. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusCreated, reaction)
+}
+
+func (handler *SharingHandler) RemoveReactionApi(c *gin.Context) {
+	id, ok := idParam(c, "completion_id", "completion")
+	if !ok {
+		return
+	}
+
+	if err := handler.Usecase.RemoveReaction(currentUserID(c), id, c.Param("emoji")); err != nil {
+		sharingError(c, err, "Failed to remove reaction. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reaction removed"})
+}
+
+func (handler *SharingHandler) CommentApi(c *gin.Context) {
+	id, ok := idParam(c, "completion_id", "completion")
+	if !ok {
+		return
+	}
+
+	var input usecase.CommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to comment on completion: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to comment on completion"})
+		return
+	}
+
+	comment, err := handler.Usecase.Comment(currentUserID(c), id, input)
+	if err != nil {
+		sharingError(c, err, "Failed to add comment. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (handler *SharingHandler) DeleteCommentApi(c *gin.Context) {
+	id, ok := idParam(c, "id", "comment")
+	if !ok {
+		return
+	}
+
+	if err := handler.Usecase.DeleteComment(currentUserID(c), id); err != nil {
+		sharingError(c, err, "Failed to delete comment. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+}
+
+// GetFeedbackApi returns the reactions and comments left on a completion.
+func (handler *SharingHandler) GetFeedbackApi(c *gin.Context) {
+	id, ok := idParam(c, "completion_id", "completion")
+	if !ok {
+		return
+	}
+
+	feedback, err := handler.Usecase.GetFeedback(currentUserID(c), id)
+	if err != nil {
+		sharingError(c, err, "Failed to retrieve feedback. Please try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+// idParam parses the named ID from the URL, responding with 400 if it is invalid
+func idParam(c *gin.Context, param, name string) (uint, bool) {
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil || id <= 0 {
+		log.Printf("Error converting %s ID URL query: %v", name, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + " ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// sharingError responds to an error of the sharing usecase, falling back to a
+// 500 with message for unexpected errors.
+func sharingError(c *gin.Context, err error, message string) {
+	switch msg := err.Error(); {
+	case strings.HasSuffix(msg, " not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": strings.ToUpper(msg[:1]) + msg[1:]})
+	case msg == "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to do this"})
+	case msg == "user is already a member":
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a partner on this habit"})
+	case msg == "invalid role":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role, use viewer or co-owner"})
+	case msg == "invalid email address", msg == "invalid reaction", msg == "cannot share a habit with its owner",
+		strings.HasPrefix(msg, "comment "):
+		c.JSON(http.StatusBadRequest, gin.H{"error": strings.ToUpper(msg[:1]) + msg[1:]})
+	default:
+		log.Printf("Error sharing habit: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,101 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharingApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	owner, partner := ts.Client, ts.Login(t, testutils.OtherUserEmail, testutils.TestPassword)
+
+	request := func(t *testing.T, client *http.Client, method, path, body string, out interface{}) int {
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		if out != nil {
+			json.NewDecoder(resp.Body).Decode(out)
+		}
+		return resp.StatusCode
+	}
+
+	t.Run("Only The Owner Shares", func(t *testing.T) {
+		body := `{"email": "` + testutils.TestUserEmail + `"}`
+		assert.Equal(t, http.StatusNotFound, request(t, partner, http.MethodPost, "/api/habits/1/members", body, nil))
+		assert.Equal(t, http.StatusBadRequest, request(t, owner, http.MethodPost, "/api/habits/1/members", body, nil))
+		assert.Equal(t, http.StatusNotFound, request(t, owner, http.MethodPost, "/api/habits/1/members", `{"email": "nobody@example.com"}`, nil))
+	})
+
+	t.Run("Viewer Follows But Cannot Change", func(t *testing.T) {
+		body := `{"email": "` + testutils.OtherUserEmail + `"}`
+		assert.Equal(t, http.StatusCreated, request(t, owner, http.MethodPost, "/api/habits/1/members", body, nil))
+		assert.Equal(t, http.StatusConflict, request(t, owner, http.MethodPost, "/api/habits/1/members", body, nil))
+
+		var habits []struct {
+			ID   uint
+			Role string
+		}
+		assert.Equal(t, http.StatusOK, request(t, partner, http.MethodGet, "/api/habits", "", &habits))
+		assert.Equal(t, 1, len(habits))
+		assert.Equal(t, "viewer", habits[0].Role)
+		assert.Equal(t, http.StatusOK, request(t, partner, http.MethodGet, "/api/habits/1/completions", "", nil))
+		assert.Equal(t, http.StatusOK, request(t, partner, http.MethodGet, "/api/habits/1/stats", "", nil))
+		assert.Equal(t, http.StatusOK, request(t, partner, http.MethodGet, "/api/habits/1/heatmap", "", nil))
+
+		assert.Equal(t, http.StatusForbidden, request(t, partner, http.MethodPut, "/api/habits/1", `{"Name": "Renamed", "Frequency": "daily"}`, nil))
+		assert.Equal(t, http.StatusForbidden, request(t, partner, http.MethodPatch, "/api/habits/1/mark_complete", "", nil))
+		assert.Equal(t, http.StatusForbidden, request(t, partner, http.MethodDelete, "/api/habits/1", "", nil))
+	})
+
+	t.Run("Feedback On Completions", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, request(t, partner, http.MethodPost, "/api/completions/1/reactions", `{"emoji": "🔥"}`, nil))
+		assert.Equal(t, http.StatusBadRequest, request(t, partner, http.MethodPost, "/api/completions/1/reactions", `{"emoji": ""}`, nil))
+
+		var comment struct{ ID uint }
+		assert.Equal(t, http.StatusCreated, request(t, partner, http.MethodPost, "/api/completions/1/comments", `{"body": "Keep going!"}`, &comment))
+
+		var feedback struct {
+			Reactions []struct{ Emoji string }
+			Comments  []struct{ Body string }
+		}
+		assert.Equal(t, http.StatusOK, request(t, owner, http.MethodGet, "/api/completions/1/feedback", "", &feedback))
+		assert.Equal(t, "🔥", feedback.Reactions[0].Emoji)
+		assert.Equal(t, "Keep going!", feedback.Comments[0].Body)
+
+		// The owner can moderate comments on their habit
+		assert.Equal(t, http.StatusOK, request(t, owner, http.MethodDelete, "/api/comments/"+fmt.Sprint(comment.ID), "", nil))
+		assert.Equal(t, http.StatusNotFound, request(t, owner, http.MethodDelete, "/api/comments/"+fmt.Sprint(comment.ID), "", nil))
+	})
+
+	t.Run("Co-owner Completes", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, request(t, partner, http.MethodPut, "/api/habits/1/members/2", `{"role": "co-owner"}`, nil))
+		assert.Equal(t, http.StatusOK, request(t, owner, http.MethodPut, "/api/habits/1/members/2", `{"role": "co-owner"}`, nil))
+		assert.Equal(t, http.StatusOK, request(t, partner, http.MethodPatch, "/api/habits/1/mark_complete", "", nil))
+
+		var partners []struct {
+			Email string
+			Role  string
+		}
+		assert.Equal(t, http.StatusOK, request(t, partner, http.MethodGet, "/api/habits/1/members", "", &partners))
+		assert.Equal(t, 2, len(partners))
+		assert.Equal(t, "owner", partners[0].Role)
+		assert.Equal(t, "co-owner", partners[1].Role)
+	})
+
+	t.Run("Partner Leaves", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(t, partner, http.MethodDelete, "/api/habits/1/members/2", "", nil))
+		assert.Equal(t, http.StatusNotFound, request(t, partner, http.MethodGet, "/api/habits/1", "", nil))
+		assert.Equal(t, http.StatusNotFound, request(t, partner, http.MethodGet, "/api/completions/1/feedback", "", nil))
+	})
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+)
+
+type SkipHandler struct {
+	Usecase *usecase.SkipUsecase
+}
+
+func (handler *SkipHandler) CreateSkipApi(c *gin.Context) {
+	var input usecase.SkipInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to create skip: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to create skip"})
+		return
+	}
+
+	handler.createSkip(c, input)
+}
+
+// ScheduleVacationApi creates a vacation skip, for every habit the user owns
+// unless a habit_id is given.
+func (handler *SkipHandler) ScheduleVacationApi(c *gin.Context) {
+	var input usecase.SkipInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to schedule vacation: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to schedule vacation"})
+		return
+	}
+
+	input.Reason = string(domain.Vacation)
+	handler.createSkip(c, input)
+}
+
+func (handler *SkipHandler) createSkip(c *gin.Context, input usecase.SkipInput) {
+	skip, err := handler.Usecase.CreateSkip(currentUserID(c), input)
+	if err != nil {
+		if err.Error() == "habit not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Habit not found"})
+			return
+		}
+
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to change this habit"})
+			return
+		}
+
+		if err.Error() == "invalid skip date" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skip date, expected YYYY-MM-DD"})
+			return
+		}
+
+		if strings.HasPrefix(err.Error(), "skip ") || strings.HasPrefix(err.Error(), "invalid skip reason") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		log.Printf("Error creating skip: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create skip. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusCreated, skip)
+}
+
+func (handler *SkipHandler) GetSkipsApi(c *gin.Context) {
+	skips, err := handler.Usecase.GetSkips(currentUserID(c))
+	if err != nil {
+		log.Printf("Error retrieving skips: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve skips. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, skips)
+}
+
+func (handler *SkipHandler) DeleteSkipApi(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Printf("Error converting skip ID URL query: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skip ID"})
+		return
+	}
+
+	err = handler.Usecase.DeleteSkip(currentUserID(c), uint(id))
+	if err != nil {
+		if err.Error() == "skip not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Skip not found"})
+			return
+		}
+
+		log.Printf("Error deleting skip with ID(%d): %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete skip. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Skip deleted"})
+}
@@ -0,0 +1,118 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSkipApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "Valid Skip For One Habit",
+			path:     "/api/skips",
+			body:     `{"habit_id": 1, "start_date": "2024-01-01", "reason": "sick"}`,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "Valid Vacation For Every Habit",
+			path:     "/api/vacations",
+			body:     `{"start_date": "2999-07-01", "end_date": "2999-07-14"}`,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "Invalid JSON",
+			path:     "/api/skips",
+			body:     `{"start_date": "Missing quote}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Date",
+			path:     "/api/skips",
+			body:     `{"start_date": "01/07/2024"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "End Before Start",
+			path:     "/api/skips",
+			body:     `{"start_date": "2024-07-14", "end_date": "2024-07-01"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Habit ID",
+			path:     "/api/skips",
+			body:     `{"habit_id": 3, "start_date": "2024-07-01"}`,
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.Client.Post(ts.URL+tt.path, "application/json", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+
+	resp, err := ts.Client.Get(ts.URL + "/api/skips")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Other users cannot skip the habit or see the skips
+	other := ts.Login(t, testutils.OtherUserEmail, testutils.TestPassword)
+
+	resp, err = other.Post(ts.URL+"/api/skips", "application/json", bytes.NewBufferString(`{"habit_id": 1, "start_date": "2024-01-02"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = other.Get(ts.URL + "/api/skips")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var skips []domain.Skip
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&skips))
+	assert.Len(t, skips, 0)
+}
+
+func TestDeleteSkipApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	resp, err := ts.Client.Post(ts.URL+"/api/skips", "application/json", bytes.NewBufferString(`{"start_date": "2024-01-01"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{"Valid Delete Skip", "1", http.StatusOK},
+		{"Skip Not Found", "1", http.StatusNotFound},
+		{"Invalid Skip ID", "abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodDelete, ts.URL+"/api/skips/"+tt.id, nil)
+			assert.NoError(t, err)
+
+			resp, err := ts.Client.Do(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+)
+
+const (
+	// ssoCookie keeps the state, nonce and PKCE verifier of a login in progress
+	// at the identity provider, so the callback can tell it was started here
+	ssoCookie       = "sso_login"
+	ssoCookiePath   = "/api/oidc"
+	ssoCookieMaxAge = 10 * 60
+)
+
+type SSOHandler struct {
+	Usecase     *usecase.SSOUsecase
+	UserUc      *usecase.UserUsecase
+	TwoFactorUc *usecase.TwoFactorUsecase
+}
+
+// LoginApi sends the user to the identity provider to log in.
+func (handler *SSOHandler) LoginApi(c *gin.Context) {
+	login, err := handler.Usecase.StartLogin(c.Request.Context())
+	if err != nil {
+		if err.Error() == "single sign-on is not configured" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on. Please try again later."})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoCookie, strings.Join([]string{login.State, login.Nonce, login.CodeVerifier}, "."), ssoCookieMaxAge, ssoCookiePath, "", isHTTPS(c), true)
+	c.Redirect(http.StatusFound, login.URL)
+}
+
+// CallbackApi is where the identity provider sends the user back to. It checks
+// the login was started by this browser, then logs the user in like LoginApi.
+func (handler *SSOHandler) CallbackApi(c *gin.Context) {
+	if !handler.Usecase.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	value, _ := c.Cookie(ssoCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoCookie, "", -1, ssoCookiePath, "", isHTTPS(c), true)
+
+	if c.Query("error") != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login was refused by the identity provider"})
+		return
+	}
+
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(c.Query("state"))) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login expired or was not started here, please try again"})
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing authorization code"})
+		return
+	}
+
+	user, err := handler.Usecase.CompleteLogin(c.Request.Context(), code, parts[2], parts[1])
+	if err != nil {
+		switch err.Error() {
+		case "single sign-on failed":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Single sign-on failed"})
+		case "email is already registered":
+			c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists, log in with your password"})
+		case "identity provider did not share an email address":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Identity provider did not share an email address"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in. Please try again later."})
+		}
+		return
+	}
+
+	logIn(c, handler.UserUc, handler.TwoFactorUc, user)
+}
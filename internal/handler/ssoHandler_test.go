@@ -0,0 +1,85 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSOLoginApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	// login follows the redirects through the mock issuer back to the callback
+	// and returns the current user afterwards
+	login := func(t *testing.T, identity testutils.MockIdentity) (int, string) {
+		ts.Issuer.Identity = identity
+		jar, err := cookiejar.New(nil)
+		assert.NoError(t, err)
+		client := &http.Client{Jar: jar}
+
+		resp, err := client.Get(ts.URL + "/api/oidc/login")
+		assert.NoError(t, err)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, ""
+		}
+
+		resp, err = client.Get(ts.URL + "/api/me")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body := new(bytes.Buffer)
+		body.ReadFrom(resp.Body)
+		return resp.StatusCode, body.String()
+	}
+
+	tests := []struct {
+		name     string
+		identity testutils.MockIdentity
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "First Login Provisions User",
+			identity: testutils.MockIdentity{Subject: "new-user", Email: "sso@example.com", EmailVerified: true},
+			wantCode: http.StatusOK,
+			wantBody: "sso@example.com",
+		},
+		{
+			name:     "Returning User",
+			identity: testutils.MockIdentity{Subject: "new-user", Email: "renamed@example.com", EmailVerified: true},
+			wantCode: http.StatusOK,
+			wantBody: "sso@example.com",
+		},
+		{
+			name:     "Verified Email Links Local Account",
+			identity: testutils.MockIdentity{Subject: "test-user", Email: testutils.TestUserEmail, EmailVerified: true},
+			wantCode: http.StatusOK,
+			wantBody: testutils.TestUserEmail,
+		},
+		{
+			name:     "Unverified Email Does Not Link",
+			identity: testutils.MockIdentity{Subject: "mallory", Email: testutils.OtherUserEmail},
+			wantCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := login(t, tt.identity)
+			assert.Equal(t, tt.wantCode, code)
+			assert.Contains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("Callback Without Login Cookie", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/oidc/callback?code=code&state=state")
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+)
+
+type StatsHandler struct {
+	Usecase *usecase.StatsUsecase
+}
+
+// GetHabitStatsApi returns a habit's completion statistics for the windows in
+// ?window, e.g. "7d,month", or for every window.
+func (handler *StatsHandler) GetHabitStatsApi(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Printf("Error converting habit ID URL query: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid habit ID"})
+		return
+	}
+
+	windows, err := usecase.ParseStatsWindows(c.Query("window"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := handler.Usecase.GetHabitStats(currentUserID(c), uint(id), windows)
+	if err != nil {
+		switch err.Error() {
+		case "habit not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Habit not found"})
+			return
+		case "stats are not available for quit habits":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		log.Printf("Error retrieving stats for habit with ID(%d): %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve habit stats. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (handler *StatsHandler) GetStatsApi(c *gin.Context) {
+	windows, err := usecase.ParseStatsWindows(c.Query("window"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := handler.Usecase.GetStats(currentUserID(c), windows)
+	if err != nil {
+		log.Printf("Error retrieving stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stats. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetHabitHeatmapApi returns a habit's per-day heatmap for ?from to ?to, in the
+// timezone ?tz.
+func (handler *StatsHandler) GetHabitHeatmapApi(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Printf("Error converting habit ID URL query: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid habit ID"})
+		return
+	}
+
+	var input usecase.HeatmapInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid heatmap query"})
+		return
+	}
+
+	heatmap, err := handler.Usecase.GetHabitHeatmap(currentUserID(c), uint(id), input)
+	if err != nil {
+		if err.Error() == "habit not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Habit not found"})
+			return
+		}
+		handler.heatmapError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+func (handler *StatsHandler) GetHeatmapApi(c *gin.Context) {
+	var input usecase.HeatmapInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid heatmap query"})
+		return
+	}
+
+	heatmap, err := handler.Usecase.GetHeatmap(currentUserID(c), input)
+	if err != nil {
+		handler.heatmapError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+func (handler *StatsHandler) heatmapError(c *gin.Context, err error) {
+	if err.Error() == "invalid heatmap date" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid heatmap date, expected YYYY-MM-DD"})
+		return
+	}
+
+	if strings.HasPrefix(err.Error(), "heatmap ") || strings.HasPrefix(err.Error(), "invalid timezone") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("Error retrieving heatmap: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve heatmap. Please try again later."})
+}
@@ -0,0 +1,71 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	ts.Client.Post(ts.URL+"/api/habits", "application/json", bytes.NewBufferString(`{"name": "Read", "frequency": "daily"}`))
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+	}{
+		{
+			name:     "Habit Stats For Every Window",
+			path:     "/api/habits/1/stats",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Habit Stats For Chosen Windows",
+			path:     "/api/habits/1/stats?window=7d,month",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Invalid Window",
+			path:     "/api/habits/1/stats?window=forever",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Habit Not Found",
+			path:     "/api/habits/999/stats",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Habit Heatmap",
+			path:     "/api/habits/1/heatmap?from=2024-01-01&to=2024-12-31&tz=Europe/London",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Heatmap Range Too Long",
+			path:     "/api/habits/1/heatmap?from=2020-01-01&to=2024-12-31",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Heatmap For Every Habit",
+			path:     "/api/heatmap",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Stats For Every Habit",
+			path:     "/api/stats?window=week",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := ts.Get(t, tt.path)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+)
+
+type TwoFactorHandler struct {
+	Usecase *usecase.TwoFactorUsecase
+	UserUc  *usecase.UserUsecase
+}
+
+// EnrollApi starts enrolling the current user in two-factor authentication and
+// returns the secret for their authenticator app.
+func (handler *TwoFactorHandler) EnrollApi(c *gin.Context) {
+	enrolment, err := handler.Usecase.Enroll(currentUserID(c))
+	if err != nil {
+		if err.Error() == "two-factor authentication is already enabled" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enroll in two-factor authentication. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrolment)
+}
+
+// ConfirmApi turns two-factor authentication on once the user enters a code
+// from their app, and returns their recovery codes.
+func (handler *TwoFactorHandler) ConfirmApi(c *gin.Context) {
+	var input usecase.TOTPCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to confirm two-factor authentication: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to confirm two-factor authentication"})
+		return
+	}
+
+	codes, err := handler.Usecase.Confirm(currentUserID(c), currentSessionID(c), input)
+	if err != nil {
+		switch err.Error() {
+		case "invalid code":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		case "two-factor enrolment has not been started":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor enrolment has not been started"})
+		case "two-factor authentication is already enabled":
+			c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication. Please try again later."})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled", "recovery_codes": codes})
+}
+
+// DisableApi turns two-factor authentication off for the current user, who
+// confirms it with a current code from their app.
+func (handler *TwoFactorHandler) DisableApi(c *gin.Context) {
+	var input usecase.TOTPCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to disable two-factor authentication: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to disable two-factor authentication"})
+		return
+	}
+
+	if err := handler.Usecase.Disable(currentUserID(c), currentSessionID(c), input); err != nil {
+		switch err.Error() {
+		case "invalid code":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		case "two-factor authentication is not enabled":
+			c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication. Please try again later."})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// VerifyLoginApi is the second login step for users with two-factor
+// authentication. It takes the challenge from LoginApi and a code from the
+// authenticator app or a recovery code, and starts a session.
+func (handler *TwoFactorHandler) VerifyLoginApi(c *gin.Context) {
+	var input usecase.ChallengeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to verify login: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to verify login"})
+		return
+	}
+
+	user, err := handler.Usecase.VerifyChallenge(input)
+	if err != nil {
+		switch err.Error() {
+		case "invalid code":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		case "invalid or expired challenge":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Login expired, please log in again"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in. Please try again later."})
+		}
+		return
+	}
+
+	token, err := handler.UserUc.StartSession(user)
+	if err != nil {
+		log.Printf("Error starting session for user with ID(%d): %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in. Please try again later."})
+		return
+	}
+
+	setSessionCookie(c, token)
+	c.JSON(http.StatusOK, token)
+}
+
+// ResetApi lets an admin turn two-factor authentication off for a user who lost
+// their authenticator and recovery codes.
+func (handler *TwoFactorHandler) ResetApi(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Printf("Error converting user ID URL query: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := handler.Usecase.Reset(currentUserID(c), uint(id)); err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset two-factor authentication. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset"})
+}
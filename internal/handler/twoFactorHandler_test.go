@@ -0,0 +1,139 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	request := func(t *testing.T, method, url, token, body string, out interface{}) int {
+		req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		if out != nil {
+			json.NewDecoder(resp.Body).Decode(out)
+		}
+		return resp.StatusCode
+	}
+
+	// passwordLogin logs in with the test user's password, the token is only set without 2FA
+	passwordLogin := func(t *testing.T, email string) (token string, twoFactorRequired bool) {
+		var login struct {
+			TwoFactorRequired bool
+			ChallengeToken    string
+			Token             string
+		}
+		body := `{"email": "` + email + `", "password": "` + testutils.TestPassword + `"}`
+		assert.Equal(t, http.StatusOK, request(t, http.MethodPost, ts.URL+"/api/login", "", body, &login))
+		if login.TwoFactorRequired {
+			return login.ChallengeToken, true
+		}
+		return login.Token, false
+	}
+
+	current, _ := passwordLogin(t, testutils.TestUserEmail)
+
+	var enrolment struct{ Secret, URI string }
+	assert.Equal(t, http.StatusOK, request(t, http.MethodPost, ts.URL+"/api/2fa/enroll", current, "", &enrolment))
+	assert.Contains(t, enrolment.URI, "otpauth://totp/")
+
+	code, err := domain.TOTPCode(enrolment.Secret, domain.TOTPCounter(time.Now()))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, request(t, http.MethodPost, ts.URL+"/api/2fa/confirm", current, `{"code": "000000"}`, nil))
+
+	var confirmed struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	assert.Equal(t, http.StatusOK, request(t, http.MethodPost, ts.URL+"/api/2fa/confirm", current, `{"code": "`+code+`"}`, &confirmed))
+	assert.Len(t, confirmed.RecoveryCodes, domain.RecoveryCodeCount)
+
+	t.Run("Other Sessions Are Revoked", func(t *testing.T) {
+		resp, err := ts.Client.Get(ts.URL + "/api/habits")
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, ts.URL+"/api/habits", current, "", nil), "the confirming session is kept")
+	})
+
+	login := func(t *testing.T) string {
+		challenge, twoFactorRequired := passwordLogin(t, testutils.TestUserEmail)
+		assert.True(t, twoFactorRequired, "no session before the second step")
+		return challenge
+	}
+
+	var session struct{ Token string }
+	t.Run("Recovery Code Logs In Once", func(t *testing.T) {
+		challenge := login(t)
+		wrong := `{"challenge_token": "` + challenge + `", "code": "000000"}`
+		assert.Equal(t, http.StatusUnauthorized, request(t, http.MethodPost, ts.URL+"/api/login/2fa", "", wrong, nil))
+
+		right := `{"challenge_token": "` + challenge + `", "code": "` + confirmed.RecoveryCodes[0] + `"}`
+		assert.Equal(t, http.StatusOK, request(t, http.MethodPost, ts.URL+"/api/login/2fa", "", right, &session))
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, ts.URL+"/api/habits", session.Token, "", nil))
+
+		// Both the challenge and the recovery code are used up
+		assert.Equal(t, http.StatusUnauthorized, request(t, http.MethodPost, ts.URL+"/api/login/2fa", "", right, nil))
+		again := `{"challenge_token": "` + login(t) + `", "code": "` + confirmed.RecoveryCodes[0] + `"}`
+		assert.Equal(t, http.StatusUnauthorized, request(t, http.MethodPost, ts.URL+"/api/login/2fa", "", again, nil))
+	})
+
+	t.Run("Admin Reset", func(t *testing.T) {
+		// Only admins may reset
+		other, _ := passwordLogin(t, testutils.OtherUserEmail)
+		assert.Equal(t, http.StatusForbidden, request(t, http.MethodDelete, ts.URL+"/api/admin/users/1/2fa", other, "", nil))
+		assert.Equal(t, http.StatusNotFound, request(t, http.MethodDelete, ts.URL+"/api/admin/users/99/2fa", session.Token, "", nil))
+
+		assert.Equal(t, http.StatusOK, request(t, http.MethodDelete, ts.URL+"/api/admin/users/1/2fa", session.Token, "", nil))
+		assert.Equal(t, http.StatusUnauthorized, request(t, http.MethodGet, ts.URL+"/api/habits", session.Token, "", nil), "sessions are revoked")
+
+		current, twoFactorRequired := passwordLogin(t, testutils.TestUserEmail)
+		assert.False(t, twoFactorRequired, "the password alone logs in again")
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, ts.URL+"/api/habits", current, "", nil))
+	})
+
+	t.Run("Self-Service Disable", func(t *testing.T) {
+		current, _ := passwordLogin(t, testutils.TestUserEmail)
+		assert.Equal(t, http.StatusConflict, request(t, http.MethodPost, ts.URL+"/api/2fa/disable", current, `{"code": "000000"}`, nil))
+
+		var enrolment struct{ Secret string }
+		assert.Equal(t, http.StatusOK, request(t, http.MethodPost, ts.URL+"/api/2fa/enroll", current, "", &enrolment))
+		counter := domain.TOTPCounter(time.Now())
+		code, err := domain.TOTPCode(enrolment.Secret, counter)
+		assert.NoError(t, err)
+		var confirmed struct {
+			RecoveryCodes []string `json:"recovery_codes"`
+		}
+		assert.Equal(t, http.StatusOK, request(t, http.MethodPost, ts.URL+"/api/2fa/confirm", current, `{"code": "`+code+`"}`, &confirmed))
+
+		var session struct{ Token string }
+		right := `{"challenge_token": "` + login(t) + `", "code": "` + confirmed.RecoveryCodes[0] + `"}`
+		assert.Equal(t, http.StatusOK, request(t, http.MethodPost, ts.URL+"/api/login/2fa", "", right, &session))
+
+		// A code already used does not disable 2FA
+		assert.Equal(t, http.StatusBadRequest, request(t, http.MethodPost, ts.URL+"/api/2fa/disable", session.Token, `{"code": "`+code+`"}`, nil))
+
+		next, err := domain.TOTPCode(enrolment.Secret, counter+1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, request(t, http.MethodPost, ts.URL+"/api/2fa/disable", session.Token, `{"code": "`+next+`"}`, nil))
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, ts.URL+"/api/habits", session.Token, "", nil), "the disabling session is kept")
+		assert.Equal(t, http.StatusUnauthorized, request(t, http.MethodGet, ts.URL+"/api/habits", current, "", nil), "other sessions are revoked")
+		_, twoFactorRequired := passwordLogin(t, testutils.TestUserEmail)
+		assert.False(t, twoFactorRequired, "the password alone logs in again")
+	})
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+)
+
+type UserHandler struct {
+	Usecase     *usecase.UserUsecase
+	TwoFactorUc *usecase.TwoFactorUsecase
+}
+
+func (handler *UserHandler) RegisterApi(c *gin.Context) {
+	var input usecase.CredentialsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to register user: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to register"})
+		return
+	}
+
+	user, err := handler.Usecase.Register(input)
+	if err != nil {
+		if err.Error() == "email is already registered" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email is already registered"})
+			return
+		}
+
+		if err.Error() == "invalid email address" || strings.HasPrefix(err.Error(), "password must be") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		log.Printf("Error registering user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// LoginApi checks an email and password and logs the user in, see logIn.
+func (handler *UserHandler) LoginApi(c *gin.Context) {
+	var input usecase.CredentialsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding json request body to log in: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input to log in"})
+		return
+	}
+
+	user, err := handler.Usecase.Login(input)
+	if err != nil {
+		if err.Error() == "invalid email or password" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+			return
+		}
+
+		log.Printf("Error logging in: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in. Please try again later."})
+		return
+	}
+
+	logIn(c, handler.Usecase, handler.TwoFactorUc, user)
+}
+
+// logIn starts a session for a user who proved who they are. The session token
+// is returned for use as a bearer token and also set as the session cookie.
+// Users with two-factor authentication get a challenge to answer at
+// /api/login/2fa instead.
+func logIn(c *gin.Context, userUc *usecase.UserUsecase, twoFactorUc *usecase.TwoFactorUsecase, user *domain.User) {
+	if user.TOTPEnabled {
+		challenge, err := twoFactorUc.StartChallenge(user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in. Please try again later."})
+			return
+		}
+
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	token, err := userUc.StartSession(user)
+	if err != nil {
+		log.Printf("Error starting session for user with ID(%d): %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in. Please try again later."})
+		return
+	}
+
+	setSessionCookie(c, token)
+	c.JSON(http.StatusOK, token)
+}
+
+// RefreshApi swaps the current session for a new one with a fresh expiry.
+func (handler *UserHandler) RefreshApi(c *gin.Context) {
+	sessionID := currentSessionID(c)
+	token, err := handler.Usecase.RefreshSession(currentUserID(c), sessionID)
+	if err != nil {
+		log.Printf("Error refreshing session with ID(%d): %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session. Please try again later."})
+		return
+	}
+
+	setSessionCookie(c, token)
+	c.JSON(http.StatusOK, token)
+}
+
+// LogoutApi revokes the current session.
+func (handler *UserHandler) LogoutApi(c *gin.Context) {
+	sessionID := currentSessionID(c)
+	if err := handler.Usecase.Logout(sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out. Please try again later."})
+		return
+	}
+
+	clearSessionCookie(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// LogoutAllApi revokes every session of the current user.
+func (handler *UserHandler) LogoutAllApi(c *gin.Context) {
+	if err := handler.Usecase.LogoutAll(currentUserID(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out. Please try again later."})
+		return
+	}
+
+	clearSessionCookie(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions"})
+}
+
+func (handler *UserHandler) GetMeApi(c *gin.Context) {
+	user, err := handler.Usecase.GetUser(currentUserID(c))
+	if err != nil {
+		log.Printf("Error retrieving current user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user. Please try again later."})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// setSessionCookie stores the session token in an HTTP-only cookie. SameSite=Lax
+// keeps other sites from making state-changing requests with it.
+func setSessionCookie(c *gin.Context, token *usecase.SessionToken) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(sessionCookie, token.Token, int(time.Until(token.ExpiresAt).Seconds()), "/", "", isHTTPS(c), true)
+}
+
+func clearSessionCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(sessionCookie, "", -1, "/", "", isHTTPS(c), true)
+}
+
+// isHTTPS reports whether the client connected over HTTPS, directly or through
+// a proxy that terminates TLS
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,230 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"testing"
+
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "Valid Registration",
+			body:     `{"email": "new@example.com", "password": "correct horse"}`,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "Email Already Registered",
+			body:     `{"email": "Test@Example.com", "password": "correct horse"}`,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "Short Password",
+			body:     `{"email": "short@example.com", "password": "hunter2"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid Email",
+			body:     `{"email": "nobody", "password": "correct horse"}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(ts.URL+"/api/register", "application/json", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestLoginApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "Valid Login",
+			body:     `{"email": "test@example.com", "password": "password123"}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Wrong Password",
+			body:     `{"email": "test@example.com", "password": "password124"}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Unknown Email",
+			body:     `{"email": "nobody@example.com", "password": "password123"}`,
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(ts.URL+"/api/login", "application/json", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	other := ts.Login(t, testutils.OtherUserEmail, testutils.TestPassword)
+
+	basicURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	basicURL.User = url.UserPassword(testutils.TestUserEmail, testutils.TestPassword)
+
+	tests := []struct {
+		name     string
+		client   *http.Client
+		url      string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Unauthenticated",
+			client:   http.DefaultClient,
+			url:      ts.URL + "/api/habits",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Basic Auth Is Not Accepted",
+			client:   http.DefaultClient,
+			url:      basicURL.String() + "/api/habits",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Owner Sees Habit",
+			client:   ts.Client,
+			url:      ts.URL + "/api/habits/1",
+			wantCode: http.StatusOK,
+			wantBody: "Test Habit",
+		},
+		{
+			name:     "Other User Cannot See Habit",
+			client:   other,
+			url:      ts.URL + "/api/habits/1",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Other User Has No Habits",
+			client:   other,
+			url:      ts.URL + "/api/habits",
+			wantCode: http.StatusOK,
+			wantBody: "No habits found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.client.Get(tt.url)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			body := new(bytes.Buffer)
+			body.ReadFrom(resp.Body)
+			assert.Contains(t, body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestSessionApi(t *testing.T) {
+	ts, _, teardown := testutils.NewTestServer(t)
+	defer teardown()
+
+	login := func(t *testing.T, client *http.Client) string {
+		body := `{"email": "test@example.com", "password": "password123"}`
+		resp, err := client.Post(ts.URL+"/api/login", "application/json", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var token struct{ Token string }
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&token))
+		assert.NotEmpty(t, token.Token)
+		return token.Token
+	}
+
+	request := func(t *testing.T, client *http.Client, method, path, token string) int {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		assert.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("Bearer Token", func(t *testing.T) {
+		client := &http.Client{}
+		token := login(t, client)
+
+		assert.Equal(t, http.StatusOK, request(t, client, http.MethodGet, "/api/me", token))
+		assert.Equal(t, http.StatusOK, request(t, client, http.MethodGet, "/api/habits", token))
+		assert.Equal(t, http.StatusUnauthorized, request(t, client, http.MethodGet, "/api/habits", "not-a-token"))
+
+		// Logging out revokes the token
+		assert.Equal(t, http.StatusOK, request(t, client, http.MethodPost, "/api/logout", token))
+		assert.Equal(t, http.StatusUnauthorized, request(t, client, http.MethodGet, "/api/habits", token))
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		client := &http.Client{}
+		token := login(t, client)
+
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/refresh", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var refreshed struct{ Token string }
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&refreshed))
+		assert.NotEqual(t, token, refreshed.Token)
+
+		assert.Equal(t, http.StatusUnauthorized, request(t, client, http.MethodGet, "/api/me", token))
+		assert.Equal(t, http.StatusOK, request(t, client, http.MethodGet, "/api/me", refreshed.Token))
+	})
+
+	t.Run("Cookie Session", func(t *testing.T) {
+		jar, err := cookiejar.New(nil)
+		assert.NoError(t, err)
+		client := &http.Client{Jar: jar}
+		login(t, client)
+
+		assert.Equal(t, http.StatusOK, request(t, client, http.MethodGet, "/api/habits", ""))
+
+		// Logging out of every session clears the cookie too
+		assert.Equal(t, http.StatusOK, request(t, client, http.MethodDelete, "/api/sessions", ""))
+		assert.Equal(t, http.StatusUnauthorized, request(t, client, http.MethodGet, "/api/habits", ""))
+	})
+}
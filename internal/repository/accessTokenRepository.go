@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+type AccessTokenRepository struct {
+	DB *gorm.DB
+}
+
+func (repo *AccessTokenRepository) Create(token *domain.AccessToken) error {
+	return repo.DB.Create(token).Error
+}
+
+func (repo *AccessTokenRepository) GetByID(id uint) (*domain.AccessToken, error) {
+	var token domain.AccessToken
+	err := repo.DB.First(&token, id).Error
+	return &token, err
+}
+
+func (repo *AccessTokenRepository) GetByTokenHash(hash string) (*domain.AccessToken, error) {
+	var token domain.AccessToken
+	err := repo.DB.Where("token_hash = ?", hash).First(&token).Error
+	return &token, err
+}
+
+func (repo *AccessTokenRepository) GetForUser(userID uint) ([]domain.AccessToken, error) {
+	var tokens []domain.AccessToken
+	err := repo.DB.Where("user_id = ?", userID).Order("created_at").Find(&tokens).Error
+	return tokens, err
+}
+
+// UpdateLastUsed records when the token was last used without touching the rest
+// of the row.
+func (repo *AccessTokenRepository) UpdateLastUsed(id uint, at time.Time) error {
+	return repo.DB.Model(&domain.AccessToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+func (repo *AccessTokenRepository) Delete(id uint) error {
+	return repo.DB.Delete(&domain.AccessToken{}, id).Error
+}
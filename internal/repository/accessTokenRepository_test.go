@@ -0,0 +1,42 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/repository"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessTokens(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.AccessTokenRepository{DB: db}
+
+	token := domain.AccessToken{UserID: 1, Name: "cron", TokenHash: domain.HashToken("htp_cron"), Scopes: "completions:write"}
+	assert.NoError(t, repo.Create(&token))
+	assert.NoError(t, repo.Create(&domain.AccessToken{UserID: 2, Name: "other", TokenHash: domain.HashToken("htp_other"), Scopes: "habits:read"}))
+
+	found, err := repo.GetByTokenHash(domain.HashToken("htp_cron"))
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	assert.Nil(t, found.LastUsedAt)
+
+	usedAt := time.Now()
+	assert.NoError(t, repo.UpdateLastUsed(token.ID, usedAt))
+	found, err = repo.GetByID(token.ID)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, usedAt, *found.LastUsedAt, time.Second)
+	assert.Equal(t, "cron", found.Name)
+
+	tokens, err := repo.GetForUser(1)
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 1)
+
+	assert.NoError(t, repo.Delete(token.ID))
+	_, err = repo.GetByTokenHash(token.TokenHash)
+	assert.Error(t, err)
+}
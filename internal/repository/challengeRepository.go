@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+type ChallengeRepository struct {
+	DB *gorm.DB
+}
+
+func (repo *ChallengeRepository) Create(challenge *domain.Challenge) error {
+	return repo.DB.Create(challenge).Error
+}
+
+// GetAll returns every challenge, the latest to start first.
+func (repo *ChallengeRepository) GetAll() ([]domain.Challenge, error) {
+	var challenges []domain.Challenge
+	err := repo.DB.Order("start_date DESC, id DESC").Find(&challenges).Error
+	return challenges, err
+}
+
+func (repo *ChallengeRepository) GetByID(id uint) (*domain.Challenge, error) {
+	var challenge domain.Challenge
+	err := repo.DB.First(&challenge, id).Error
+	return &challenge, err
+}
+
+// Delete removes the challenge and its participants. The habits participants
+// tracked it with are theirs to keep.
+func (repo *ChallengeRepository) Delete(id uint) error {
+	tx := repo.DB.Begin()
+	if err := tx.Where("challenge_id = ?", id).Delete(&domain.ChallengeParticipant{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Delete(&domain.Challenge{}, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// Join creates the participant's habit and their participation in a single
+// transaction, so a failed join leaves no habit behind.
+func (repo *ChallengeRepository) Join(participant *domain.ChallengeParticipant, habit *domain.Habit) error {
+	tx := repo.DB.Begin()
+	if err := tx.Create(habit).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	participant.HabitID = habit.ID
+	if err := tx.Create(participant).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func (repo *ChallengeRepository) Leave(challengeID, userID uint) error {
+	return repo.DB.Where("challenge_id = ? AND user_id = ?", challengeID, userID).Delete(&domain.ChallengeParticipant{}).Error
+}
+
+func (repo *ChallengeRepository) GetParticipant(challengeID, userID uint) (*domain.ChallengeParticipant, error) {
+	var participant domain.ChallengeParticipant
+	err := repo.DB.Where("challenge_id = ? AND user_id = ?", challengeID, userID).First(&participant).Error
+	return &participant, err
+}
+
+func (repo *ChallengeRepository) GetParticipants(challengeID uint) ([]domain.ChallengeParticipant, error) {
+	var participants []domain.ChallengeParticipant
+	err := repo.DB.Where("challenge_id = ?", challengeID).Order("created_at ASC").Find(&participants).Error
+	return participants, err
+}
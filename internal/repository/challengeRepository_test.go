@@ -0,0 +1,70 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/repository"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestChallenges(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.ChallengeRepository{DB: db}
+	habitRepo := &repository.HabitRepository{DB: db}
+
+	challenge := &domain.Challenge{
+		CreatorID: 1,
+		Name:      "Walk 10k",
+		Frequency: "daily",
+		StartDate: time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, time.November, 30, 0, 0, 0, 0, time.UTC),
+	}
+	err := repo.Create(challenge)
+	assert.NoError(t, err)
+
+	stored, err := repo.GetByID(challenge.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-11-30", stored.EndDate.Format("2006-01-02"))
+
+	// Joining creates the participant's habit along with the participation
+	habit := challenge.Habit(2)
+	participant := &domain.ChallengeParticipant{ChallengeID: challenge.ID, UserID: 2}
+	err = repo.Join(participant, &habit)
+	assert.NoError(t, err)
+	assert.NotZero(t, habit.ID)
+	assert.Equal(t, habit.ID, participant.HabitID)
+
+	owned, err := habitRepo.GetAllForUser(2)
+	assert.NoError(t, err)
+	assert.Len(t, owned, 1)
+
+	again := challenge.Habit(2)
+	err = repo.Join(&domain.ChallengeParticipant{ChallengeID: challenge.ID, UserID: 2}, &again)
+	assert.Error(t, err, "a user joins once")
+	owned, err = habitRepo.GetAllForUser(2)
+	assert.NoError(t, err)
+	assert.Len(t, owned, 1, "a failed join leaves no habit behind")
+
+	participants, err := repo.GetParticipants(challenge.ID)
+	assert.NoError(t, err)
+	assert.Len(t, participants, 1)
+
+	err = repo.Leave(challenge.ID, 2)
+	assert.NoError(t, err)
+	_, err = repo.GetParticipant(challenge.ID, 2)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = habitRepo.GetByID(habit.ID)
+	assert.NoError(t, err, "the habit is kept after leaving")
+
+	err = repo.Delete(challenge.ID)
+	assert.NoError(t, err)
+	challenges, err := repo.GetAll()
+	assert.NoError(t, err)
+	assert.Empty(t, challenges)
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/jt00721/habit-tracker/internal/domain"
 	"gorm.io/gorm"
 )
@@ -19,18 +21,123 @@ func (repo *HabitRepository) GetByID(id uint) (*domain.Habit, error) {
 	return &habit, err
 }
 
+// GetByIDForUser returns the habit only if it belongs to the user, and
+// gorm.ErrRecordNotFound otherwise.
+func (repo *HabitRepository) GetByIDForUser(id, userID uint) (*domain.Habit, error) {
+	var habit domain.Habit
+	err := repo.DB.Where("user_id = ?", userID).First(&habit, id).Error
+	return &habit, err
+}
+
+// GetByIDForMember returns the habit if the user owns it or is one of its
+// partners, along with the user's role, and gorm.ErrRecordNotFound otherwise.
+func (repo *HabitRepository) GetByIDForMember(id, userID uint) (*domain.Habit, domain.HabitRole, error) {
+	var habit domain.Habit
+	if err := repo.DB.First(&habit, id).Error; err != nil {
+		return &habit, "", err
+	}
+	if habit.UserID == userID {
+		return &habit, domain.RoleOwner, nil
+	}
+
+	var member domain.HabitMember
+	if err := repo.DB.Where("habit_id = ? AND user_id = ?", id, userID).First(&member).Error; err != nil {
+		return &habit, "", err
+	}
+	return &habit, member.Role, nil
+}
+
 func (repo *HabitRepository) GetAll() ([]domain.Habit, error) {
 	var habits []domain.Habit
 	err := repo.DB.Find(&habits).Error
 	return habits, err
 }
 
+func (repo *HabitRepository) GetAllForUser(userID uint) ([]domain.Habit, error) {
+	var habits []domain.Habit
+	err := repo.DB.Where("user_id = ?", userID).Find(&habits).Error
+	return habits, err
+}
+
+// GetAllSharedWithUser returns the habits other users shared with the user, with
+// Role set to the user's role in each.
+func (repo *HabitRepository) GetAllSharedWithUser(userID uint) ([]domain.Habit, error) {
+	var members []domain.HabitMember
+	if err := repo.DB.Where("user_id = ?", userID).Find(&members).Error; err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	roles := make(map[uint]domain.HabitRole, len(members))
+	ids := make([]uint, len(members))
+	for i, member := range members {
+		roles[member.HabitID] = member.Role
+		ids[i] = member.HabitID
+	}
+
+	var habits []domain.Habit
+	if err := repo.DB.Where("id IN ?", ids).Find(&habits).Error; err != nil {
+		return nil, err
+	}
+	for i := range habits {
+		habits[i].Role = roles[habits[i].ID]
+	}
+	return habits, nil
+}
+
+// Update saves the habit, and replaces its streak history in the same
+// transaction when StreakRuns is set.
 func (repo *HabitRepository) Update(habit *domain.Habit) error {
-	return repo.DB.Save(habit).Error
+	tx := repo.DB.Begin()
+	if err := tx.Save(habit).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := saveStreakRuns(tx, habit); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
 }
 
 func (repo *HabitRepository) Delete(id uint) error {
-	return repo.DB.Delete(&domain.Habit{}, id).Error
+	tx := repo.DB.Begin()
+	if err := tx.Where("habit_id = ?", id).Delete(&domain.StreakRun{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("habit_id = ?", id).Delete(&domain.Skip{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("habit_id = ?", id).Delete(&domain.Relapse{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("habit_id = ?", id).Delete(&domain.TimerSession{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("habit_id = ?", id).Delete(&domain.HabitMember{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	completions := tx.Model(&domain.HabitCompletion{}).Select("id").Where("habit_id = ?", id)
+	if err := deleteFeedback(tx, completions); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("habit_id = ?", id).Delete(&domain.HabitCompletion{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Delete(&domain.Habit{}, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
 }
 
 func (repo *HabitRepository) SafeUpdate(habit *domain.Habit) error {
@@ -44,6 +151,152 @@ func (repo *HabitRepository) SafeUpdate(habit *domain.Habit) error {
 
 func (repo *HabitRepository) GetStreaks() ([]domain.Habit, error) {
 	var habits []domain.Habit
-	err := repo.DB.Where("current_streak > ?", 0).Order("current_streak DESC").Find(&habits).Error
+	err := repo.DB.Where("current_streak > ? OR kind = ?", 0, domain.Quit).Order("current_streak DESC").Find(&habits).Error
+	return habits, err
+}
+
+func (repo *HabitRepository) GetStreaksForUser(userID uint) ([]domain.Habit, error) {
+	var habits []domain.Habit
+	err := repo.DB.Where("user_id = ?", userID).Where("current_streak > ? OR kind = ?", 0, domain.Quit).Order("current_streak DESC").Find(&habits).Error
 	return habits, err
 }
+
+// AddCompletion stores a completion event, the updated habit counters and its
+// streak history in a single transaction.
+func (repo *HabitRepository) AddCompletion(habit *domain.Habit, completion *domain.HabitCompletion) error {
+	tx := repo.DB.Begin()
+	if err := tx.Create(completion).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Save(habit).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := saveStreakRuns(tx, habit); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// RemoveCompletions deletes the habit's completions in [from, to) and saves the
+// recalculated habit counters and streak history in a single transaction.
+func (repo *HabitRepository) RemoveCompletions(habit *domain.Habit, from, to time.Time) error {
+	tx := repo.DB.Begin()
+	completions := tx.Model(&domain.HabitCompletion{}).Select("id").
+		Where("habit_id = ? AND completed_at >= ? AND completed_at < ?", habit.ID, from, to)
+	if err := deleteFeedback(tx, completions); err != nil {
+		tx.Rollback()
+		return err
+	}
+	err := tx.Where("habit_id = ? AND completed_at >= ? AND completed_at < ?", habit.ID, from, to).
+		Delete(&domain.HabitCompletion{}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Save(habit).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := saveStreakRuns(tx, habit); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// AddCompletions stores a batch of completion events and the updated counters and
+// streak histories of the habits they belong to in a single transaction.
+func (repo *HabitRepository) AddCompletions(habits []*domain.Habit, completions []*domain.HabitCompletion) error {
+	tx := repo.DB.Begin()
+	for _, completion := range completions {
+		if err := tx.Create(completion).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, habit := range habits {
+		if err := tx.Save(habit).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := saveStreakRuns(tx, habit); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
+func (repo *HabitRepository) GetCompletions(habitID uint) ([]domain.HabitCompletion, error) {
+	var completions []domain.HabitCompletion
+	err := repo.DB.Where("habit_id = ?", habitID).Order("completed_at ASC").Find(&completions).Error
+	return completions, err
+}
+
+func (repo *HabitRepository) GetCompletion(id uint) (*domain.HabitCompletion, error) {
+	var completion domain.HabitCompletion
+	err := repo.DB.First(&completion, id).Error
+	return &completion, err
+}
+
+// deleteFeedback removes the reactions and comments on the completions selected
+// by the subquery, before the completions themselves are removed
+func deleteFeedback(tx *gorm.DB, completions *gorm.DB) error {
+	if err := tx.Where("completion_id IN (?)", completions).Delete(&domain.CompletionReaction{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("completion_id IN (?)", completions).Delete(&domain.CompletionComment{}).Error
+}
+
+// AddRelapse stores a relapse, the updated habit and its streak history in a
+// single transaction.
+func (repo *HabitRepository) AddRelapse(habit *domain.Habit, relapse *domain.Relapse) error {
+	tx := repo.DB.Begin()
+	if err := tx.Create(relapse).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Save(habit).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := saveStreakRuns(tx, habit); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func (repo *HabitRepository) GetRelapses(habitID uint) ([]domain.Relapse, error) {
+	var relapses []domain.Relapse
+	err := repo.DB.Where("habit_id = ?", habitID).Order("occurred_at ASC").Find(&relapses).Error
+	return relapses, err
+}
+
+func (repo *HabitRepository) GetStreakRuns(habitID uint) ([]domain.StreakRun, error) {
+	var runs []domain.StreakRun
+	err := repo.DB.Where("habit_id = ?", habitID).Order("start_date ASC").Find(&runs).Error
+	return runs, err
+}
+
+// saveStreakRuns replaces the habit's streak history with its StreakRuns within
+// the transaction. A nil StreakRuns leaves the history as it is.
+func saveStreakRuns(tx *gorm.DB, habit *domain.Habit) error {
+	if habit.StreakRuns == nil {
+		return nil
+	}
+	if err := tx.Where("habit_id = ?", habit.ID).Delete(&domain.StreakRun{}).Error; err != nil {
+		return err
+	}
+	for i := range habit.StreakRuns {
+		habit.StreakRuns[i].ID = 0
+		habit.StreakRuns[i].HabitID = habit.ID
+		if err := tx.Create(&habit.StreakRuns[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,11 +2,13 @@ package repository_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/jt00721/habit-tracker/internal/domain"
 	"github.com/jt00721/habit-tracker/internal/repository"
 	testutils "github.com/jt00721/habit-tracker/test"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestGetHabitByID(t *testing.T) {
@@ -32,6 +34,7 @@ func TestCreate(t *testing.T) {
 	repo := &repository.HabitRepository{DB: db}
 
 	habit := domain.Habit{
+		UserID:    1,
 		Name:      "Test Create Habit",
 		Frequency: "weekly",
 	}
@@ -56,11 +59,13 @@ func TestGetAll(t *testing.T) {
 	repo := &repository.HabitRepository{DB: db}
 
 	repo.Create(&domain.Habit{
+		UserID:    1,
 		Name:      "Test Habit 2",
 		Frequency: "weekly",
 	})
 
 	repo.Create(&domain.Habit{
+		UserID:    1,
 		Name:      "Test Habit 3",
 		Frequency: "daily",
 	})
@@ -71,6 +76,34 @@ func TestGetAll(t *testing.T) {
 	assert.Len(t, habits, 3)
 }
 
+func TestUserScopedQueries(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.HabitRepository{DB: db}
+
+	err := repo.Create(&domain.Habit{UserID: 2, Name: "Other Habit", Frequency: "daily", CurrentStreak: 2})
+	assert.NoError(t, err)
+
+	habit, err := repo.GetByIDForUser(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Habit", habit.Name)
+
+	// Another user's habit is not found
+	_, err = repo.GetByIDForUser(1, 2)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	habits, err := repo.GetAllForUser(2)
+	assert.NoError(t, err)
+	assert.Len(t, habits, 1)
+	assert.Equal(t, "Other Habit", habits[0].Name)
+
+	habits, err = repo.GetStreaksForUser(1)
+	assert.NoError(t, err)
+	assert.Len(t, habits, 1)
+	assert.Equal(t, uint(1), habits[0].UserID)
+}
+
 func TestUpdate(t *testing.T) {
 	// Set up test DB
 	db, teardown := testutils.NewTestDB(t)
@@ -111,3 +144,177 @@ func TestDelete(t *testing.T) {
 	_, err = repo.GetByID(1)
 	assert.Error(t, err)
 }
+
+func TestAddCompletion(t *testing.T) {
+	// Set up test DB
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.HabitRepository{DB: db}
+
+	habit, err := repo.GetByID(1)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	habit.CurrentStreak = 6
+	habit.TotalCompletions = 11
+	habit.LastCompletedAt = &now
+
+	err = repo.AddCompletion(habit, &domain.HabitCompletion{HabitID: habit.ID, CompletedAt: now})
+	assert.NoError(t, err)
+
+	updatedHabit, err := repo.GetByID(habit.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, updatedHabit.CurrentStreak)
+	assert.Equal(t, 11, updatedHabit.TotalCompletions)
+
+	completions, err := repo.GetCompletions(habit.ID)
+	assert.NoError(t, err)
+	assert.Len(t, completions, 2)
+}
+
+func TestAddCompletions(t *testing.T) {
+	// Set up test DB
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.HabitRepository{DB: db}
+
+	habit, err := repo.GetByID(1)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	habit.TotalCompletions = 13
+	habit.LastCompletedAt = &now
+
+	err = repo.AddCompletions([]*domain.Habit{habit}, []*domain.HabitCompletion{
+		{HabitID: habit.ID, CompletedAt: now.AddDate(0, 0, -1)},
+		{HabitID: habit.ID, CompletedAt: now},
+	})
+	assert.NoError(t, err)
+
+	updatedHabit, err := repo.GetByID(habit.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 13, updatedHabit.TotalCompletions)
+
+	completions, err := repo.GetCompletions(habit.ID)
+	assert.NoError(t, err)
+	assert.Len(t, completions, 3)
+}
+
+func TestGetCompletions(t *testing.T) {
+	// Set up test DB
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.HabitRepository{DB: db}
+
+	completions, err := repo.GetCompletions(1)
+	assert.NoError(t, err)
+	assert.Len(t, completions, 1)
+	assert.Equal(t, uint(1), completions[0].HabitID)
+
+	// Deleting a habit removes its completion history
+	err = repo.Delete(1)
+	assert.NoError(t, err)
+
+	completions, err = repo.GetCompletions(1)
+	assert.NoError(t, err)
+	assert.Len(t, completions, 0)
+}
+
+func TestRemoveCompletions(t *testing.T) {
+	// Set up test DB
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.HabitRepository{DB: db}
+
+	habit, err := repo.GetByID(1)
+	assert.NoError(t, err)
+
+	habit.CurrentStreak = 0
+	habit.TotalCompletions = 0
+	habit.LastCompletedAt = nil
+
+	now := time.Now()
+	err = repo.RemoveCompletions(habit, now.Add(-time.Hour), now.Add(time.Hour))
+	assert.NoError(t, err)
+
+	completions, err := repo.GetCompletions(habit.ID)
+	assert.NoError(t, err)
+	assert.Len(t, completions, 0)
+
+	updatedHabit, err := repo.GetByID(habit.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, updatedHabit.CurrentStreak)
+	assert.Nil(t, updatedHabit.LastCompletedAt)
+}
+
+func TestAddRelapse(t *testing.T) {
+	// Set up test DB
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.HabitRepository{DB: db}
+
+	habit := &domain.Habit{UserID: 1, Name: "No sugar", Frequency: "daily", Kind: "quit"}
+	err := repo.Create(habit)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	habit.LastRelapseAt = &now
+	err = repo.AddRelapse(habit, &domain.Relapse{HabitID: habit.ID, OccurredAt: now, Note: "cake"})
+	assert.NoError(t, err)
+
+	updatedHabit, err := repo.GetByID(habit.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, updatedHabit.LastRelapseAt)
+
+	relapses, err := repo.GetRelapses(habit.ID)
+	assert.NoError(t, err)
+	assert.Len(t, relapses, 1)
+	assert.Equal(t, "cake", relapses[0].Note)
+
+	// Quit habits are listed with the streaks even before their first clean day
+	habits, err := repo.GetStreaks()
+	assert.NoError(t, err)
+	assert.Len(t, habits, 2)
+}
+
+func TestSaveStreakRuns(t *testing.T) {
+	// Set up test DB
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.HabitRepository{DB: db}
+
+	habit := &domain.Habit{UserID: 1, Name: "Read", Frequency: "daily"}
+	err := repo.Create(habit)
+	assert.NoError(t, err)
+
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	habit.StreakRuns = []domain.StreakRun{
+		{StartDate: start, EndDate: start.AddDate(0, 0, 2), Length: 3},
+		{StartDate: start.AddDate(0, 0, 5), EndDate: start.AddDate(0, 0, 5), Length: 1},
+	}
+	err = repo.Update(habit)
+	assert.NoError(t, err)
+
+	// Saving again with the completion replaces the history
+	habit.StreakRuns = habit.StreakRuns[:1]
+	err = repo.AddCompletion(habit, &domain.HabitCompletion{HabitID: habit.ID, CompletedAt: start.AddDate(0, 0, 7)})
+	assert.NoError(t, err)
+
+	// A habit saved without its runs loaded keeps them
+	loaded, err := repo.GetByID(habit.ID)
+	assert.NoError(t, err)
+	err = repo.Update(loaded)
+	assert.NoError(t, err)
+
+	savedRuns, err := repo.GetStreakRuns(habit.ID)
+	assert.NoError(t, err)
+	assert.Len(t, savedRuns, 1)
+	assert.Equal(t, 3, savedRuns[0].Length)
+	assert.Equal(t, habit.ID, savedRuns[0].HabitID)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import "gorm.io/gorm"
+
+type JobLockRepository struct {
+	DB *gorm.DB
+}
+
+// TryRun holds a Postgres transaction-level advisory lock while fn runs, so the
+// lock is released when the transaction ends even if the instance dies.
+func (repo *JobLockRepository) TryRun(key int64, fn func() error) (bool, error) {
+	tx := repo.DB.Begin()
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+
+	var locked bool
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", key).Row().Scan(&locked); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if !locked {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := fn(); err != nil {
+		tx.Rollback()
+		return true, err
+	}
+	return true, tx.Commit().Error
+}
@@ -0,0 +1,32 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/jt00721/habit-tracker/internal/repository"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobLock(t *testing.T) {
+	// Set up test DB
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.JobLockRepository{DB: db}
+
+	ran, err := repo.TryRun(1, func() error {
+		// A second instance cannot take the lock while it is held
+		nestedRan, err := repo.TryRun(1, func() error { return nil })
+		assert.NoError(t, err)
+		assert.False(t, nestedRan)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, ran)
+
+	// The lock is released once the job finishes
+	ran, err = repo.TryRun(1, func() error { return nil })
+	assert.NoError(t, err)
+	assert.True(t, ran)
+}
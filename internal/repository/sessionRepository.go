@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	DB *gorm.DB
+}
+
+func (repo *SessionRepository) Create(session *domain.Session) error {
+	return repo.DB.Create(session).Error
+}
+
+func (repo *SessionRepository) GetByTokenHash(hash string) (*domain.Session, error) {
+	var session domain.Session
+	err := repo.DB.Where("token_hash = ?", hash).First(&session).Error
+	return &session, err
+}
+
+func (repo *SessionRepository) Delete(id uint) error {
+	return repo.DB.Delete(&domain.Session{}, id).Error
+}
+
+// DeleteForUser revokes every session of the user but the one with exceptID,
+// which is 0 to revoke them all.
+func (repo *SessionRepository) DeleteForUser(userID, exceptID uint) error {
+	return repo.DB.Where("user_id = ? AND id <> ?", userID, exceptID).Delete(&domain.Session{}).Error
+}
+
+func (repo *SessionRepository) DeleteExpired(before time.Time) error {
+	return repo.DB.Where("expires_at <= ?", before).Delete(&domain.Session{}).Error
+}
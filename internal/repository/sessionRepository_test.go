@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/repository"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessions(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.SessionRepository{DB: db}
+	now := time.Now()
+
+	active := domain.Session{UserID: 1, TokenHash: domain.HashToken("active"), ExpiresAt: now.Add(time.Hour)}
+	expired := domain.Session{UserID: 1, TokenHash: domain.HashToken("expired"), ExpiresAt: now.Add(-time.Hour)}
+	other := domain.Session{UserID: 2, TokenHash: domain.HashToken("other"), ExpiresAt: now.Add(time.Hour)}
+	for _, session := range []*domain.Session{&active, &expired, &other} {
+		assert.NoError(t, repo.Create(session))
+	}
+
+	session, err := repo.GetByTokenHash(domain.HashToken("active"))
+	assert.NoError(t, err)
+	assert.Equal(t, active.ID, session.ID)
+
+	// Token hashes are unique
+	err = repo.Create(&domain.Session{UserID: 2, TokenHash: active.TokenHash, ExpiresAt: now})
+	assert.Error(t, err)
+
+	err = repo.DeleteExpired(now)
+	assert.NoError(t, err)
+	_, err = repo.GetByTokenHash(expired.TokenHash)
+	assert.Error(t, err)
+
+	// The session kept is still valid
+	kept := domain.Session{UserID: 1, TokenHash: domain.HashToken("kept"), ExpiresAt: now.Add(time.Hour)}
+	assert.NoError(t, repo.Create(&kept))
+	err = repo.DeleteForUser(1, kept.ID)
+	assert.NoError(t, err)
+	_, err = repo.GetByTokenHash(active.TokenHash)
+	assert.Error(t, err)
+	_, err = repo.GetByTokenHash(kept.TokenHash)
+	assert.NoError(t, err)
+
+	err = repo.DeleteForUser(1, 0)
+	assert.NoError(t, err)
+	_, err = repo.GetByTokenHash(kept.TokenHash)
+	assert.Error(t, err)
+
+	err = repo.Delete(other.ID)
+	assert.NoError(t, err)
+	_, err = repo.GetByTokenHash(other.TokenHash)
+	assert.Error(t, err)
+}
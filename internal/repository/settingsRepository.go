@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+type SettingsRepository struct {
+	DB *gorm.DB
+}
+
+func (repo *SettingsRepository) Get(userID uint) (*domain.Settings, error) {
+	var settings domain.Settings
+	err := repo.DB.Where("user_id = ?", userID).First(&settings).Error
+	return &settings, err
+}
+
+func (repo *SettingsRepository) Save(settings *domain.Settings) error {
+	return repo.DB.Save(settings).Error
+}
@@ -0,0 +1,37 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/repository"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveSettings(t *testing.T) {
+	// Set up test DB
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.SettingsRepository{DB: db}
+
+	// Nothing saved yet
+	_, err := repo.Get(1)
+	assert.Error(t, err)
+
+	settings := domain.Settings{UserID: 1, Timezone: "Europe/Berlin", WeekStart: time.Sunday, DayEndHour: 3}
+	err = repo.Save(&settings)
+	assert.NoError(t, err)
+
+	savedSettings, err := repo.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", savedSettings.Timezone)
+	assert.Equal(t, time.Sunday, savedSettings.WeekStart)
+	assert.Equal(t, 3, savedSettings.DayEndHour)
+
+	// Other users keep the defaults
+	_, err = repo.Get(2)
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SharingRepository struct {
+	DB *gorm.DB
+}
+
+func (repo *SharingRepository) CreateMember(member *domain.HabitMember) error {
+	return repo.DB.Create(member).Error
+}
+
+func (repo *SharingRepository) GetMember(habitID, userID uint) (*domain.HabitMember, error) {
+	var member domain.HabitMember
+	err := repo.DB.Where("habit_id = ? AND user_id = ?", habitID, userID).First(&member).Error
+	return &member, err
+}
+
+func (repo *SharingRepository) GetMembers(habitID uint) ([]domain.HabitMember, error) {
+	var members []domain.HabitMember
+	err := repo.DB.Where("habit_id = ?", habitID).Order("created_at ASC").Find(&members).Error
+	return members, err
+}
+
+func (repo *SharingRepository) UpdateMemberRole(habitID, userID uint, role domain.HabitRole) error {
+	return repo.DB.Model(&domain.HabitMember{}).Where("habit_id = ? AND user_id = ?", habitID, userID).
+		Update("role", role).Error
+}
+
+func (repo *SharingRepository) DeleteMember(habitID, userID uint) error {
+	return repo.DB.Where("habit_id = ? AND user_id = ?", habitID, userID).Delete(&domain.HabitMember{}).Error
+}
+
+// AddReaction stores a reaction, doing nothing if the user already reacted to
+// the completion with the same emoji.
+func (repo *SharingRepository) AddReaction(reaction *domain.CompletionReaction) error {
+	return repo.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction).Error
+}
+
+func (repo *SharingRepository) RemoveReaction(completionID, userID uint, emoji string) error {
+	return repo.DB.Where("completion_id = ? AND user_id = ? AND emoji = ?", completionID, userID, emoji).
+		Delete(&domain.CompletionReaction{}).Error
+}
+
+func (repo *SharingRepository) GetReactions(completionID uint) ([]domain.CompletionReaction, error) {
+	var reactions []domain.CompletionReaction
+	err := repo.DB.Where("completion_id = ?", completionID).Order("created_at ASC").Find(&reactions).Error
+	return reactions, err
+}
+
+func (repo *SharingRepository) AddComment(comment *domain.CompletionComment) error {
+	return repo.DB.Create(comment).Error
+}
+
+func (repo *SharingRepository) GetComment(id uint) (*domain.CompletionComment, error) {
+	var comment domain.CompletionComment
+	err := repo.DB.First(&comment, id).Error
+	return &comment, err
+}
+
+func (repo *SharingRepository) GetComments(completionID uint) ([]domain.CompletionComment, error) {
+	var comments []domain.CompletionComment
+	err := repo.DB.Where("completion_id = ?", completionID).Order("created_at ASC").Find(&comments).Error
+	return comments, err
+}
+
+func (repo *SharingRepository) DeleteComment(id uint) error {
+	return repo.DB.Delete(&domain.CompletionComment{}, id).Error
+}
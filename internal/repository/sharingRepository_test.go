@@ -0,0 +1,113 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/repository"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestHabitMembers(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.SharingRepository{DB: db}
+	habitRepo := &repository.HabitRepository{DB: db}
+
+	// Before sharing only the owner has access
+	_, role, err := habitRepo.GetByIDForMember(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleOwner, role)
+	_, _, err = habitRepo.GetByIDForMember(1, 2)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	err = repo.CreateMember(&domain.HabitMember{HabitID: 1, UserID: 2, Role: domain.RoleViewer})
+	assert.NoError(t, err)
+	err = repo.CreateMember(&domain.HabitMember{HabitID: 1, UserID: 2, Role: domain.RoleCoOwner})
+	assert.Error(t, err, "a user is a member once")
+
+	habit, role, err := habitRepo.GetByIDForMember(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Habit", habit.Name)
+	assert.Equal(t, domain.RoleViewer, role)
+
+	shared, err := habitRepo.GetAllSharedWithUser(2)
+	assert.NoError(t, err)
+	assert.Len(t, shared, 1)
+	assert.Equal(t, domain.RoleViewer, shared[0].Role)
+	owned, err := habitRepo.GetAllForUser(2)
+	assert.NoError(t, err)
+	assert.Empty(t, owned, "shared habits are not owned")
+
+	err = repo.UpdateMemberRole(1, 2, domain.RoleCoOwner)
+	assert.NoError(t, err)
+	member, err := repo.GetMember(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleCoOwner, member.Role)
+
+	members, err := repo.GetMembers(1)
+	assert.NoError(t, err)
+	assert.Len(t, members, 1)
+
+	err = repo.DeleteMember(1, 2)
+	assert.NoError(t, err)
+	_, err = repo.GetMember(1, 2)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestCompletionFeedback(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.SharingRepository{DB: db}
+	habitRepo := &repository.HabitRepository{DB: db}
+
+	completions, err := habitRepo.GetCompletions(1)
+	assert.NoError(t, err)
+	assert.Len(t, completions, 1)
+	completionID := completions[0].ID
+
+	completion, err := habitRepo.GetCompletion(completionID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), completion.HabitID)
+
+	// Reacting twice with the same emoji keeps one reaction
+	for i := 0; i < 2; i++ {
+		err = repo.AddReaction(&domain.CompletionReaction{CompletionID: completionID, UserID: 2, Emoji: "🔥"})
+		assert.NoError(t, err)
+	}
+	err = repo.AddReaction(&domain.CompletionReaction{CompletionID: completionID, UserID: 2, Emoji: "👏"})
+	assert.NoError(t, err)
+	reactions, err := repo.GetReactions(completionID)
+	assert.NoError(t, err)
+	assert.Len(t, reactions, 2)
+
+	err = repo.RemoveReaction(completionID, 2, "👏")
+	assert.NoError(t, err)
+	reactions, err = repo.GetReactions(completionID)
+	assert.NoError(t, err)
+	assert.Len(t, reactions, 1)
+
+	comment := &domain.CompletionComment{CompletionID: completionID, UserID: 2, Body: "Nice!"}
+	err = repo.AddComment(comment)
+	assert.NoError(t, err)
+	stored, err := repo.GetComment(comment.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Nice!", stored.Body)
+
+	// Removing the completion removes what was left on it
+	habit, err := habitRepo.GetByID(1)
+	assert.NoError(t, err)
+	err = habitRepo.RemoveCompletions(habit, completion.CompletedAt, completion.CompletedAt.Add(time.Second))
+	assert.NoError(t, err)
+	reactions, err = repo.GetReactions(completionID)
+	assert.NoError(t, err)
+	assert.Empty(t, reactions)
+	comments, err := repo.GetComments(completionID)
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+}
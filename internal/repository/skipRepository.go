@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+type SkipRepository struct {
+	DB *gorm.DB
+}
+
+func (repo *SkipRepository) Create(skip *domain.Skip) error {
+	return repo.DB.Create(skip).Error
+}
+
+func (repo *SkipRepository) GetByID(id uint) (*domain.Skip, error) {
+	var skip domain.Skip
+	err := repo.DB.First(&skip, id).Error
+	return &skip, err
+}
+
+func (repo *SkipRepository) GetForUser(userID uint) ([]domain.Skip, error) {
+	var skips []domain.Skip
+	err := repo.DB.Where("user_id = ?", userID).Order("start_date ASC").Find(&skips).Error
+	return skips, err
+}
+
+// GetForHabit returns the skips for the habit and those its owner created for
+// every habit.
+func (repo *SkipRepository) GetForHabit(habitID, ownerID uint) ([]domain.Skip, error) {
+	var skips []domain.Skip
+	err := repo.DB.Where("habit_id = ? OR (habit_id IS NULL AND user_id = ?)", habitID, ownerID).Order("start_date ASC").Find(&skips).Error
+	return skips, err
+}
+
+func (repo *SkipRepository) Delete(id uint) error {
+	return repo.DB.Delete(&domain.Skip{}, id).Error
+}
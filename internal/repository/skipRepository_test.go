@@ -0,0 +1,47 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/repository"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkips(t *testing.T) {
+	// Set up test DB
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.SkipRepository{DB: db}
+
+	habitID := uint(1)
+	otherHabitID := uint(2)
+	start := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	skips := []domain.Skip{
+		{UserID: 1, StartDate: start, EndDate: start.AddDate(0, 0, 13), Reason: "vacation"},
+		{UserID: 1, HabitID: &habitID, StartDate: start.AddDate(0, 1, 0), EndDate: start.AddDate(0, 1, 0), Reason: "sick"},
+		{UserID: 1, HabitID: &otherHabitID, StartDate: start, EndDate: start, Reason: "skip"},
+		{UserID: 2, StartDate: start, EndDate: start.AddDate(0, 0, 6), Reason: "vacation"},
+	}
+	for i := range skips {
+		err := repo.Create(&skips[i])
+		assert.NoError(t, err)
+	}
+
+	// Another user's vacation does not apply to the habit
+	habitSkips, err := repo.GetForHabit(habitID, 1)
+	assert.NoError(t, err)
+	assert.Len(t, habitSkips, 2)
+	assert.Equal(t, start, habitSkips[0].StartDate.UTC())
+
+	err = repo.Delete(skips[0].ID)
+	assert.NoError(t, err)
+
+	userSkips, err := repo.GetForUser(1)
+	assert.NoError(t, err)
+	assert.Len(t, userSkips, 2)
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+type TimerSessionRepository struct {
+	DB *gorm.DB
+}
+
+// GetActive returns the habit's running or paused session.
+func (repo *TimerSessionRepository) GetActive(habitID uint) (*domain.TimerSession, error) {
+	var session domain.TimerSession
+	err := repo.DB.Where("habit_id = ? AND status <> ?", habitID, domain.TimerStopped).
+		Order("started_at DESC").
+		First(&session).Error
+	return &session, err
+}
+
+// GetRunning returns the running sessions of every habit.
+func (repo *TimerSessionRepository) GetRunning() ([]domain.TimerSession, error) {
+	var sessions []domain.TimerSession
+	err := repo.DB.Where("status = ?", domain.TimerRunning).Find(&sessions).Error
+	return sessions, err
+}
+
+func (repo *TimerSessionRepository) Save(session *domain.TimerSession) error {
+	return repo.DB.Save(session).Error
+}
+
+// Complete records the completion produced by a stopped session, together with
+// the habit's recalculated counters and streak history and the session itself.
+func (repo *TimerSessionRepository) Complete(session *domain.TimerSession, habit *domain.Habit, completion *domain.HabitCompletion) error {
+	tx := repo.DB.Begin()
+	if err := tx.Create(completion).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Save(habit).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := saveStreakRuns(tx, habit); err != nil {
+		tx.Rollback()
+		return err
+	}
+	session.CompletionID = &completion.ID
+	if err := tx.Save(session).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
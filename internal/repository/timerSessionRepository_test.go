@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/repository"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimerSessionLifecycle(t *testing.T) {
+	// Set up test DB
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.TimerSessionRepository{DB: db}
+	habitRepo := &repository.HabitRepository{DB: db}
+
+	// No session yet
+	_, err := repo.GetActive(1)
+	assert.Error(t, err)
+
+	now := time.Now()
+	session := domain.TimerSession{HabitID: 1, Status: domain.TimerRunning, StartedAt: now, ResumedAt: &now}
+	err = repo.Save(&session)
+	assert.NoError(t, err)
+
+	activeSession, err := repo.GetActive(1)
+	assert.NoError(t, err)
+	assert.Equal(t, session.ID, activeSession.ID)
+
+	running, err := repo.GetRunning()
+	assert.NoError(t, err)
+	assert.Len(t, running, 1)
+
+	habit, err := habitRepo.GetByID(1)
+	assert.NoError(t, err)
+
+	activeSession.Stop(now.Add(20 * time.Minute))
+	completion := domain.HabitCompletion{HabitID: 1, CompletedAt: *activeSession.StoppedAt, Quantity: 20}
+	err = repo.Complete(activeSession, habit, &completion)
+	assert.NoError(t, err)
+	assert.Equal(t, completion.ID, *activeSession.CompletionID)
+
+	// Stopped sessions are no longer active
+	_, err = repo.GetActive(1)
+	assert.Error(t, err)
+	running, err = repo.GetRunning()
+	assert.NoError(t, err)
+	assert.Len(t, running, 0)
+
+	completions, err := habitRepo.GetCompletions(1)
+	assert.NoError(t, err)
+	assert.Len(t, completions, 2)
+}
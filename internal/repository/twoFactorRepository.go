@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository struct {
+	DB *gorm.DB
+}
+
+func (repo *TwoFactorRepository) SetPendingSecret(userID uint, secret string) error {
+	return repo.DB.Model(&domain.User{}).Where("id = ? AND NOT totp_enabled", userID).
+		Updates(map[string]interface{}{"totp_secret": secret, "totp_last_counter": 0}).Error
+}
+
+// Enable turns 2FA on with the counter the enrolment was confirmed with, so the
+// same code cannot be used again to log in.
+func (repo *TwoFactorRepository) Enable(userID uint, counter int64, codes []domain.RecoveryCode) error {
+	tx := repo.DB.Begin()
+	if err := tx.Model(&domain.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"totp_enabled": true, "totp_last_counter": counter}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if len(codes) > 0 {
+		if err := tx.Create(&codes).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
+func (repo *TwoFactorRepository) Reset(userID uint) error {
+	tx := repo.DB.Begin()
+	if err := tx.Model(&domain.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"totp_enabled": false, "totp_secret": "", "totp_last_counter": 0}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.LoginChallenge{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// UseCounter only moves the counter forward, so of two requests racing with the
+// same code only one succeeds.
+func (repo *TwoFactorRepository) UseCounter(userID uint, counter int64) (bool, error) {
+	result := repo.DB.Model(&domain.User{}).Where("id = ? AND totp_last_counter < ?", userID, counter).
+		Update("totp_last_counter", counter)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (repo *TwoFactorRepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := repo.DB.Where("user_id = ? AND code_hash = ?", userID, codeHash).Delete(&domain.RecoveryCode{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (repo *TwoFactorRepository) CreateChallenge(challenge *domain.LoginChallenge) error {
+	return repo.DB.Create(challenge).Error
+}
+
+func (repo *TwoFactorRepository) GetChallengeByTokenHash(hash string) (*domain.LoginChallenge, error) {
+	var challenge domain.LoginChallenge
+	err := repo.DB.Where("token_hash = ?", hash).First(&challenge).Error
+	return &challenge, err
+}
+
+func (repo *TwoFactorRepository) AddChallengeAttempt(id uint) error {
+	return repo.DB.Model(&domain.LoginChallenge{}).Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+func (repo *TwoFactorRepository) DeleteChallenge(id uint) error {
+	return repo.DB.Delete(&domain.LoginChallenge{}, id).Error
+}
+
+func (repo *TwoFactorRepository) DeleteExpiredChallenges(before time.Time) error {
+	return repo.DB.Where("expires_at <= ?", before).Delete(&domain.LoginChallenge{}).Error
+}
@@ -0,0 +1,96 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/repository"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorEnrolment(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.TwoFactorRepository{DB: db}
+	userRepo := &repository.UserRepository{DB: db}
+
+	err := repo.SetPendingSecret(1, "SECRET")
+	assert.NoError(t, err)
+	user, err := userRepo.GetByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "SECRET", user.TOTPSecret)
+	assert.False(t, user.TOTPEnabled)
+
+	codes := []domain.RecoveryCode{{UserID: 1, CodeHash: "a"}, {UserID: 1, CodeHash: "b"}}
+	err = repo.Enable(1, 100, codes)
+	assert.NoError(t, err)
+	user, err = userRepo.GetByID(1)
+	assert.NoError(t, err)
+	assert.True(t, user.TOTPEnabled)
+	assert.Equal(t, int64(100), user.TOTPLastCounter)
+
+	// The secret cannot be swapped once enabled
+	err = repo.SetPendingSecret(1, "OTHER")
+	assert.NoError(t, err)
+	user, err = userRepo.GetByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "SECRET", user.TOTPSecret)
+
+	// Time steps only move forward
+	used, err := repo.UseCounter(1, 100)
+	assert.NoError(t, err)
+	assert.False(t, used)
+	used, err = repo.UseCounter(1, 101)
+	assert.NoError(t, err)
+	assert.True(t, used)
+
+	// Recovery codes work once, for their own user only
+	used, err = repo.UseRecoveryCode(2, "a")
+	assert.NoError(t, err)
+	assert.False(t, used)
+	used, err = repo.UseRecoveryCode(1, "a")
+	assert.NoError(t, err)
+	assert.True(t, used)
+	used, err = repo.UseRecoveryCode(1, "a")
+	assert.NoError(t, err)
+	assert.False(t, used)
+
+	err = repo.Reset(1)
+	assert.NoError(t, err)
+	user, err = userRepo.GetByID(1)
+	assert.NoError(t, err)
+	assert.False(t, user.TOTPEnabled)
+	assert.Empty(t, user.TOTPSecret)
+	used, err = repo.UseRecoveryCode(1, "b")
+	assert.NoError(t, err)
+	assert.False(t, used)
+}
+
+func TestLoginChallenges(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.TwoFactorRepository{DB: db}
+	now := time.Now()
+
+	challenge := &domain.LoginChallenge{UserID: 1, TokenHash: "hash", ExpiresAt: now.Add(time.Minute)}
+	assert.NoError(t, repo.CreateChallenge(challenge))
+	assert.NoError(t, repo.CreateChallenge(&domain.LoginChallenge{UserID: 1, TokenHash: "old", ExpiresAt: now.Add(-time.Minute)}))
+
+	assert.NoError(t, repo.AddChallengeAttempt(challenge.ID))
+	assert.NoError(t, repo.AddChallengeAttempt(challenge.ID))
+	found, err := repo.GetChallengeByTokenHash("hash")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, found.Attempts)
+
+	assert.NoError(t, repo.DeleteExpiredChallenges(now))
+	_, err = repo.GetChallengeByTokenHash("old")
+	assert.Error(t, err)
+
+	assert.NoError(t, repo.DeleteChallenge(challenge.ID))
+	_, err = repo.GetChallengeByTokenHash("hash")
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+type UserRepository struct {
+	DB *gorm.DB
+}
+
+// Create stores a new user. A new admin adopts the habits created before
+// accounts existed, see Promote.
+func (repo *UserRepository) Create(user *domain.User) error {
+	tx := repo.DB.Begin()
+	if err := createUser(tx, user); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func createUser(tx *gorm.DB, user *domain.User) error {
+	if err := tx.Create(user).Error; err != nil {
+		return err
+	}
+	if user.IsAdmin {
+		return adoptLegacyData(tx, user.ID)
+	}
+	return nil
+}
+
+// Promote makes the user an admin, who adopts the data created before accounts
+// existed so an upgraded install keeps it.
+func (repo *UserRepository) Promote(id uint) error {
+	tx := repo.DB.Begin()
+	if err := tx.Model(&domain.User{}).Where("id = ?", id).Update("is_admin", true).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := adoptLegacyData(tx, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// adoptLegacyData hands the habits and skips without an owner to the user, along
+// with the settings saved before accounts existed unless the user has their own.
+// The rows are updated under lock, so only one transaction can adopt each one.
+func adoptLegacyData(tx *gorm.DB, userID uint) error {
+	if err := tx.Model(&domain.Habit{}).Where("user_id IS NULL OR user_id = 0").Update("user_id", userID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&domain.Skip{}).Where("user_id = 0").Update("user_id", userID).Error; err != nil {
+		return err
+	}
+	return tx.Model(&domain.Settings{}).
+		Where("user_id = 0 AND NOT EXISTS (SELECT 1 FROM settings WHERE user_id = ?)", userID).
+		Update("user_id", userID).Error
+}
+
+func (repo *UserRepository) GetByID(id uint) (*domain.User, error) {
+	var user domain.User
+	err := repo.DB.First(&user, id).Error
+	return &user, err
+}
+
+func (repo *UserRepository) GetByEmail(email string) (*domain.User, error) {
+	var user domain.User
+	err := repo.DB.Where("email = ?", email).First(&user).Error
+	return &user, err
+}
+
+// GetByIdentity returns the user linked to the subject at an identity provider
+func (repo *UserRepository) GetByIdentity(issuer, subject string) (*domain.User, error) {
+	var user domain.User
+	err := repo.DB.Joins("JOIN user_identities ON user_identities.user_id = users.id").
+		Where("user_identities.issuer = ? AND user_identities.subject = ?", issuer, subject).
+		First(&user).Error
+	return &user, err
+}
+
+// CreateWithIdentity stores a user provisioned by an identity provider together
+// with the link to their identity there.
+func (repo *UserRepository) CreateWithIdentity(user *domain.User, identity *domain.UserIdentity) error {
+	tx := repo.DB.Begin()
+	if err := createUser(tx, user); err != nil {
+		tx.Rollback()
+		return err
+	}
+	identity.UserID = user.ID
+	if err := tx.Create(identity).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// AddIdentity links an existing user to an identity provider
+func (repo *UserRepository) AddIdentity(identity *domain.UserIdentity) error {
+	return repo.DB.Create(identity).Error
+}
@@ -0,0 +1,124 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/repository"
+	testutils "github.com/jt00721/habit-tracker/test"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateUser(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	// Start from an install that predates accounts
+	assert.NoError(t, db.Exec("UPDATE habits SET user_id = NULL").Error)
+	assert.NoError(t, db.Exec("DELETE FROM users").Error)
+
+	repo := &repository.UserRepository{DB: db}
+	habitRepo := &repository.HabitRepository{DB: db}
+
+	// Registering first does not make an admin
+	first := domain.User{Email: "first@example.com", PasswordHash: "hash"}
+	err := repo.Create(&first)
+	assert.NoError(t, err)
+	assert.False(t, first.IsAdmin)
+
+	habits, err := habitRepo.GetAllForUser(first.ID)
+	assert.NoError(t, err)
+	assert.Len(t, habits, 0)
+
+	// The admin adopts the existing habits
+	admin := domain.User{Email: "admin@example.com", PasswordHash: "hash", IsAdmin: true}
+	err = repo.Create(&admin)
+	assert.NoError(t, err)
+	habit, err := habitRepo.GetByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, admin.ID, habit.UserID)
+
+	// Emails are unique
+	err = repo.Create(&domain.User{Email: "first@example.com", PasswordHash: "hash"})
+	assert.Error(t, err)
+}
+
+func TestPromoteUser(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	assert.NoError(t, db.Exec("UPDATE habits SET user_id = NULL").Error)
+
+	repo := &repository.UserRepository{DB: db}
+	habitRepo := &repository.HabitRepository{DB: db}
+	settingsRepo := &repository.SettingsRepository{DB: db}
+	assert.NoError(t, settingsRepo.Save(&domain.Settings{Timezone: "Europe/Paris"}))
+
+	err := repo.Promote(2)
+	assert.NoError(t, err)
+
+	user, err := repo.GetByID(2)
+	assert.NoError(t, err)
+	assert.True(t, user.IsAdmin)
+	habit, err := habitRepo.GetByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), habit.UserID)
+	settings, err := settingsRepo.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Paris", settings.Timezone)
+}
+
+func TestGetUser(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.UserRepository{DB: db}
+
+	user, err := repo.GetByEmail(testutils.TestUserEmail)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), user.ID)
+
+	user, err = repo.GetByID(2)
+	assert.NoError(t, err)
+	assert.Equal(t, testutils.OtherUserEmail, user.Email)
+
+	_, err = repo.GetByEmail("nobody@example.com")
+	assert.Error(t, err)
+}
+
+func TestUserIdentities(t *testing.T) {
+	db, teardown := testutils.NewTestDB(t)
+	defer teardown()
+
+	repo := &repository.UserRepository{DB: db}
+
+	_, err := repo.GetByIdentity("https://idp.example.com", "alice")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	// A provisioned user has no password and is found by their identity
+	user := domain.User{Email: "alice@example.com"}
+	err = repo.CreateWithIdentity(&user, &domain.UserIdentity{Issuer: "https://idp.example.com", Subject: "alice"})
+	assert.NoError(t, err)
+
+	found, err := repo.GetByIdentity("https://idp.example.com", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+	assert.Empty(t, found.PasswordHash)
+
+	// The same subject at another issuer is someone else
+	_, err = repo.GetByIdentity("https://other.example.com", "alice")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	err = repo.AddIdentity(&domain.UserIdentity{UserID: 1, Issuer: "https://other.example.com", Subject: "alice"})
+	assert.NoError(t, err)
+	found, err = repo.GetByIdentity("https://other.example.com", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), found.ID)
+
+	// An identity links to one user only, and the user is rolled back with it
+	err = repo.CreateWithIdentity(&domain.User{Email: "mallory@example.com"}, &domain.UserIdentity{Issuer: "https://idp.example.com", Subject: "alice"})
+	assert.Error(t, err)
+	_, err = repo.GetByEmail("mallory@example.com")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
@@ -2,18 +2,103 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/jt00721/habit-tracker/internal/domain"
 	"github.com/jt00721/habit-tracker/internal/handler"
 	"github.com/jt00721/habit-tracker/internal/usecase"
 )
 
-func SetupRoutes(router *gin.Engine, uc *usecase.HabitUsecase) {
+func SetupRoutes(router *gin.Engine, uc *usecase.HabitUsecase, settingsUc *usecase.SettingsUsecase, skipUc *usecase.SkipUsecase, streakResetUc *usecase.StreakResetUsecase, statsUc *usecase.StatsUsecase, userUc *usecase.UserUsecase, tokenUc *usecase.AccessTokenUsecase, ssoUc *usecase.SSOUsecase, twoFactorUc *usecase.TwoFactorUsecase, sharingUc *usecase.SharingUsecase, challengeUc *usecase.ChallengeUsecase) {
 	habitHandler := &handler.HabitHandler{Usecase: uc}
+	settingsHandler := &handler.SettingsHandler{Usecase: settingsUc}
+	skipHandler := &handler.SkipHandler{Usecase: skipUc}
+	jobHandler := &handler.JobHandler{StreakResetUc: streakResetUc}
+	statsHandler := &handler.StatsHandler{Usecase: statsUc}
+	userHandler := &handler.UserHandler{Usecase: userUc, TwoFactorUc: twoFactorUc}
+	tokenHandler := &handler.AccessTokenHandler{Usecase: tokenUc}
+	ssoHandler := &handler.SSOHandler{Usecase: ssoUc, UserUc: userUc, TwoFactorUc: twoFactorUc}
+	twoFactorHandler := &handler.TwoFactorHandler{Usecase: twoFactorUc, UserUc: userUc}
+	sharingHandler := &handler.SharingHandler{Usecase: sharingUc}
+	challengeHandler := &handler.ChallengeHandler{Usecase: challengeUc}
 
-	router.POST("/api/habits", habitHandler.CreateHabitApi)
-	router.GET("/api/habits", habitHandler.GetAllHabitsApi)
-	router.GET("/api/habits/:id", habitHandler.GetHabitByIDApi)
-	router.PUT("/api/habits/:id", habitHandler.UpdateHabitApi)
-	router.DELETE("/api/habits/:id", habitHandler.DeleteHabitApi)
-	router.GET("/api/habits/streaks", habitHandler.GetStreaksApi)
-	router.PATCH("/api/habits/:id/mark_complete", habitHandler.MarkHabitCompletedApi)
+	router.POST("/api/register", userHandler.RegisterApi)
+	router.POST("/api/login", userHandler.LoginApi)
+	router.POST("/api/login/2fa", twoFactorHandler.VerifyLoginApi)
+	router.GET("/api/oidc/login", ssoHandler.LoginApi)
+	router.GET("/api/oidc/callback", ssoHandler.CallbackApi)
+
+	// Everything else acts on behalf of the authenticated user. Each route states
+	// the scope a personal access token needs for it, or denies access tokens.
+	api := router.Group("/api", handler.RequireUser(userUc, tokenUc))
+	read := handler.RequireScope(domain.ScopeHabitsRead)
+	write := handler.RequireScope(domain.ScopeHabitsWrite)
+	complete := handler.RequireScope(domain.ScopeCompletionsWrite)
+	noTokens := handler.DenyAccessTokens()
+
+	api.GET("/me", read, userHandler.GetMeApi)
+	api.POST("/refresh", noTokens, userHandler.RefreshApi)
+	api.POST("/logout", noTokens, userHandler.LogoutApi)
+	api.DELETE("/sessions", noTokens, userHandler.LogoutAllApi)
+
+	api.POST("/2fa/enroll", noTokens, twoFactorHandler.EnrollApi)
+	api.POST("/2fa/confirm", noTokens, twoFactorHandler.ConfirmApi)
+	api.POST("/2fa/disable", noTokens, twoFactorHandler.DisableApi)
+
+	api.POST("/tokens", noTokens, tokenHandler.CreateAccessTokenApi)
+	api.GET("/tokens", noTokens, tokenHandler.GetAccessTokensApi)
+	api.DELETE("/tokens/:id", noTokens, tokenHandler.RevokeAccessTokenApi)
+
+	api.POST("/habits", write, habitHandler.CreateHabitApi)
+	api.GET("/habits", read, habitHandler.GetAllHabitsApi)
+	api.GET("/habits/:id", read, habitHandler.GetHabitByIDApi)
+	api.PUT("/habits/:id", write, habitHandler.UpdateHabitApi)
+	api.DELETE("/habits/:id", write, habitHandler.DeleteHabitApi)
+	api.GET("/habits/streaks", read, habitHandler.GetStreaksApi)
+	api.POST("/habits/mark_complete", complete, habitHandler.MarkHabitsCompletedApi)
+	api.PATCH("/habits/:id/mark_complete", complete, habitHandler.MarkHabitCompletedApi)
+	api.GET("/habits/:id/completions", read, habitHandler.GetCompletionsApi)
+	api.DELETE("/habits/:id/completions/:date", complete, habitHandler.RemoveCompletionApi)
+	api.POST("/habits/:id/relapses", complete, habitHandler.LogRelapseApi)
+	api.GET("/habits/:id/relapses", read, habitHandler.GetRelapsesApi)
+	api.GET("/habits/:id/timer", read, habitHandler.GetTimerApi)
+	api.POST("/habits/:id/timer/start", complete, habitHandler.StartTimerApi)
+	api.POST("/habits/:id/timer/pause", complete, habitHandler.PauseTimerApi)
+	api.POST("/habits/:id/timer/stop", complete, habitHandler.StopTimerApi)
+	api.GET("/habits/:id/stats", read, statsHandler.GetHabitStatsApi)
+	api.GET("/habits/:id/heatmap", read, statsHandler.GetHabitHeatmapApi)
+
+	// Sharing habits with accountability partners and their feedback on completions
+	api.POST("/habits/:id/members", noTokens, sharingHandler.ShareHabitApi)
+	api.GET("/habits/:id/members", read, sharingHandler.GetPartnersApi)
+	api.PUT("/habits/:id/members/:user_id", noTokens, sharingHandler.UpdatePartnerApi)
+	api.DELETE("/habits/:id/members/:user_id", noTokens, sharingHandler.RemovePartnerApi)
+	api.GET("/completions/:completion_id/feedback", read, sharingHandler.GetFeedbackApi)
+	api.POST("/completions/:completion_id/reactions", write, sharingHandler.ReactApi)
+	api.DELETE("/completions/:completion_id/reactions/:emoji", write, sharingHandler.RemoveReactionApi)
+	api.POST("/completions/:completion_id/comments", write, sharingHandler.CommentApi)
+	api.DELETE("/comments/:id", write, sharingHandler.DeleteCommentApi)
+
+	api.POST("/challenges", write, challengeHandler.CreateChallengeApi)
+	api.GET("/challenges", read, challengeHandler.GetChallengesApi)
+	api.GET("/challenges/:id", read, challengeHandler.GetChallengeApi)
+	api.DELETE("/challenges/:id", write, challengeHandler.DeleteChallengeApi)
+	api.POST("/challenges/:id/join", write, challengeHandler.JoinChallengeApi)
+	api.POST("/challenges/:id/leave", write, challengeHandler.LeaveChallengeApi)
+	api.GET("/challenges/:id/leaderboard", read, challengeHandler.GetLeaderboardApi)
+
+	api.GET("/stats", read, statsHandler.GetStatsApi)
+	api.GET("/heatmap", read, statsHandler.GetHeatmapApi)
+
+	api.GET("/today", read, habitHandler.GetTodayApi)
+
+	api.GET("/settings", read, settingsHandler.GetSettingsApi)
+	api.PUT("/settings", noTokens, settingsHandler.UpdateSettingsApi)
+
+	api.POST("/skips", write, skipHandler.CreateSkipApi)
+	api.GET("/skips", read, skipHandler.GetSkipsApi)
+	api.DELETE("/skips/:id", write, skipHandler.DeleteSkipApi)
+	api.POST("/vacations", write, skipHandler.ScheduleVacationApi)
+
+	admin := api.Group("/admin", noTokens, handler.RequireAdmin(userUc))
+	admin.DELETE("/users/:id/2fa", twoFactorHandler.ResetApi)
+	admin.POST("/jobs/streak-reset", jobHandler.ResetStreaksApi)
 }
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+const (
+	maxAccessTokenNameLength = 100
+
+	// lastUsedResolution is how stale a token's LastUsedAt may get before a use
+	// updates it, so a busy script does not write to the database on every request
+	lastUsedResolution = time.Minute
+)
+
+type AccessTokenUsecase struct {
+	TokenRepo domain.AccessTokenRepository
+}
+
+// AccessTokenInput names a new personal access token and lists its scopes, e.g.
+// ["habits:read", "completions:write"].
+type AccessTokenInput struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// NewAccessToken is a newly created access token along with the token itself,
+// which is not stored and cannot be shown again.
+type NewAccessToken struct {
+	Token string
+	*domain.AccessToken
+}
+
+func (usecase *AccessTokenUsecase) CreateAccessToken(userID uint, input AccessTokenInput) (*NewAccessToken, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("token name cannot be empty")
+	}
+	if len(name) > maxAccessTokenNameLength {
+		return nil, fmt.Errorf("token name must be at most %d characters", maxAccessTokenNameLength)
+	}
+
+	scopes, err := domain.ParseScopes(input.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, _, err := domain.NewToken()
+	if err != nil {
+		log.Println("Error generating access token:", err)
+		return nil, fmt.Errorf("failed to create access token")
+	}
+	secret = domain.AccessTokenPrefix + secret
+
+	token := &domain.AccessToken{UserID: userID, Name: name, TokenHash: domain.HashToken(secret), Scopes: scopes}
+	if err := usecase.TokenRepo.Create(token); err != nil {
+		log.Println("Error creating access token:", err)
+		return nil, fmt.Errorf("failed to create access token")
+	}
+
+	log.Printf("Access token (%s) created for user with ID(%d)", name, userID)
+	return &NewAccessToken{Token: secret, AccessToken: token}, nil
+}
+
+func (usecase *AccessTokenUsecase) GetAccessTokens(userID uint) ([]domain.AccessToken, error) {
+	tokens, err := usecase.TokenRepo.GetForUser(userID)
+	if err != nil {
+		log.Printf("Error retrieving access tokens of user with ID(%d): %v", userID, err)
+		return nil, fmt.Errorf("failed to get access tokens")
+	}
+	return tokens, nil
+}
+
+func (usecase *AccessTokenUsecase) RevokeAccessToken(userID, id uint) error {
+	token, err := usecase.TokenRepo.GetByID(id)
+	if err != nil || token.UserID != userID {
+		if err == nil || err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("access token not found")
+		}
+		log.Printf("Error retrieving access token with ID(%d): %v", id, err)
+		return fmt.Errorf("failed to revoke access token")
+	}
+
+	if err := usecase.TokenRepo.Delete(id); err != nil {
+		log.Printf("Error deleting access token with ID(%d): %v", id, err)
+		return fmt.Errorf("failed to revoke access token")
+	}
+	return nil
+}
+
+// Authenticate returns the access token matching secret and records that it
+// was used.
+func (usecase *AccessTokenUsecase) Authenticate(secret string) (*domain.AccessToken, error) {
+	if !strings.HasPrefix(secret, domain.AccessTokenPrefix) {
+		return nil, fmt.Errorf("invalid access token")
+	}
+
+	token, err := usecase.TokenRepo.GetByTokenHash(domain.HashToken(secret))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("invalid access token")
+		}
+		log.Println("Error retrieving access token:", err)
+		return nil, fmt.Errorf("failed to authenticate")
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		if err := usecase.TokenRepo.UpdateLastUsed(token.ID, now); err != nil {
+			log.Printf("Error updating last use of access token with ID(%d): %v", token.ID, err)
+		} else {
+			token.LastUsedAt = &now
+		}
+	}
+	return token, nil
+}
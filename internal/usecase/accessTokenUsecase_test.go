@@ -0,0 +1,175 @@
+package usecase_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateAccessToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       usecase.AccessTokenInput
+		mockCreate  func(*domain.AccessToken) error
+		wantScopes  string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:  "valid token",
+			input: usecase.AccessTokenInput{Name: " cron ", Scopes: []string{"completions:write", "habits:read"}},
+			mockCreate: func(token *domain.AccessToken) error {
+				assert.Equal(t, testUserID, token.UserID)
+				assert.Equal(t, "cron", token.Name)
+				return nil
+			},
+			wantScopes: "habits:read,completions:write",
+			wantErr:    false,
+		},
+		{
+			name:        "missing name",
+			input:       usecase.AccessTokenInput{Scopes: []string{"habits:read"}},
+			wantErr:     true,
+			errContains: "token name cannot be empty",
+		},
+		{
+			name:        "unknown scope",
+			input:       usecase.AccessTokenInput{Name: "cron", Scopes: []string{"admin"}},
+			wantErr:     true,
+			errContains: "invalid scope: admin",
+		},
+		{
+			name:        "no scopes",
+			input:       usecase.AccessTokenInput{Name: "cron"},
+			wantErr:     true,
+			errContains: "at least one scope is required",
+		},
+		{
+			name:  "create fails",
+			input: usecase.AccessTokenInput{Name: "cron", Scopes: []string{"habits:read"}},
+			mockCreate: func(token *domain.AccessToken) error {
+				return errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to create access token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &usecase.AccessTokenUsecase{TokenRepo: &usecase.MockAccessTokenRepo{CreateFn: tt.mockCreate}}
+
+			token, err := uc.CreateAccessToken(testUserID, tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, strings.HasPrefix(token.Token, domain.AccessTokenPrefix))
+				assert.Equal(t, domain.HashToken(token.Token), token.TokenHash)
+				assert.Equal(t, tt.wantScopes, token.Scopes)
+			}
+		})
+	}
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          uint
+		wantDeleted bool
+		wantErr     bool
+		errContains string
+	}{
+		{name: "own token", id: 1, wantDeleted: true},
+		{name: "another user's token", id: 2, wantErr: true, errContains: "access token not found"},
+		{name: "unknown token", id: 3, wantErr: true, errContains: "access token not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted := false
+			repo := &usecase.MockAccessTokenRepo{
+				GetByIDFn: func(id uint) (*domain.AccessToken, error) {
+					if id == 3 {
+						return nil, gorm.ErrRecordNotFound
+					}
+					return &domain.AccessToken{ID: id, UserID: id}, nil
+				},
+				DeleteFn: func(id uint) error {
+					deleted = true
+					return nil
+				},
+			}
+			uc := &usecase.AccessTokenUsecase{TokenRepo: repo}
+
+			err := uc.RevokeAccessToken(testUserID, tt.id)
+
+			assert.Equal(t, tt.wantDeleted, deleted)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAccessToken(t *testing.T) {
+	recently := time.Now().Add(-10 * time.Second)
+	hourAgo := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		secret      string
+		lastUsedAt  *time.Time
+		wantUpdated bool
+		wantErr     bool
+		errContains string
+	}{
+		{name: "first use", secret: "htp_secret", wantUpdated: true},
+		{name: "used a while ago", secret: "htp_secret", lastUsedAt: &hourAgo, wantUpdated: true},
+		{name: "used moments ago", secret: "htp_secret", lastUsedAt: &recently, wantUpdated: false},
+		{name: "unknown token", secret: "htp_unknown", wantErr: true, errContains: "invalid access token"},
+		{name: "session token", secret: "secret", wantErr: true, errContains: "invalid access token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := false
+			repo := &usecase.MockAccessTokenRepo{
+				GetByTokenHashFn: func(hash string) (*domain.AccessToken, error) {
+					if hash != domain.HashToken("htp_secret") {
+						return nil, gorm.ErrRecordNotFound
+					}
+					return &domain.AccessToken{ID: 1, UserID: testUserID, LastUsedAt: tt.lastUsedAt}, nil
+				},
+				UpdateLastUsedFn: func(id uint, at time.Time) error {
+					updated = true
+					return nil
+				},
+			}
+			uc := &usecase.AccessTokenUsecase{TokenRepo: repo}
+
+			token, err := uc.Authenticate(tt.secret)
+
+			assert.Equal(t, tt.wantUpdated, updated)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testUserID, token.UserID)
+				assert.NotNil(t, token.LastUsedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+// LeaderboardSortCompletions ranks challenge participants by the periods they
+// completed within the challenge, ties broken by current streak. The streak orderings of
+// GetStreaks rank by streak first instead, ties broken by completions.
+const LeaderboardSortCompletions = "completions"
+
+type ChallengeUsecase struct {
+	ChallengeRepo domain.ChallengeRepository
+	HabitRepo     domain.HabitRepository
+	UserRepo      domain.UserRepository
+	SettingsRepo  domain.SettingsRepository
+	SkipRepo      domain.SkipRepository
+}
+
+// CreateChallengeInput describes a challenge: the habit every participant tracks,
+// with the same schedule fields as a habit, and the calendar days (YYYY-MM-DD,
+// inclusive) it runs for.
+type CreateChallengeInput struct {
+	Name           string  `json:"name"`
+	Description    string  `json:"description"`
+	Frequency      string  `json:"frequency"`
+	TimesPerPeriod int     `json:"times_per_period"`
+	Weekdays       string  `json:"weekdays"`
+	MonthDays      string  `json:"month_days"`
+	IntervalDays   int     `json:"interval_days"`
+	RRule          string  `json:"rrule"`
+	TargetValue    float64 `json:"target_value"`
+	Unit           string  `json:"unit"`
+	StartDate      string  `json:"start_date"`
+	EndDate        string  `json:"end_date"`
+}
+
+// LeaderboardEntry is a participant's standing in a challenge, counting only
+// the periods within it. Participants tied on every ranked field share a rank.
+type LeaderboardEntry struct {
+	Rank          int
+	UserID        uint
+	Email         string
+	HabitID       uint
+	Completions   int // periods completed
+	CurrentStreak int // as of the challenge's end, or now while it runs
+	LongestStreak int
+}
+
+func (usecase *ChallengeUsecase) CreateChallenge(userID uint, input CreateChallengeInput) (*domain.Challenge, error) {
+	challenge := &domain.Challenge{
+		CreatorID:      userID,
+		Name:           input.Name,
+		Description:    input.Description,
+		Frequency:      input.Frequency,
+		TimesPerPeriod: input.TimesPerPeriod,
+		Weekdays:       input.Weekdays,
+		MonthDays:      input.MonthDays,
+		IntervalDays:   input.IntervalDays,
+		RRule:          input.RRule,
+		TargetValue:    input.TargetValue,
+		Unit:           input.Unit,
+	}
+
+	var err error
+	if challenge.StartDate, err = parseChallengeDate(input.StartDate); err != nil {
+		return nil, err
+	}
+	if challenge.EndDate, err = parseChallengeDate(input.EndDate); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateChallenge(challenge); err != nil {
+		return nil, err
+	}
+
+	if err := usecase.ChallengeRepo.Create(challenge); err != nil {
+		log.Println("Error creating challenge:", err)
+		return nil, fmt.Errorf("failed to create challenge")
+	}
+
+	log.Printf("Challenge (%s) created from %s to %s", challenge.Name, challenge.StartDate.Format("2006-01-02"), challenge.EndDate.Format("2006-01-02"))
+	return challenge, nil
+}
+
+func (usecase *ChallengeUsecase) GetChallenges() ([]domain.Challenge, error) {
+	challenges, err := usecase.ChallengeRepo.GetAll()
+	if err != nil {
+		log.Println("Error retrieving challenges:", err)
+		return nil, fmt.Errorf("failed to get challenges")
+	}
+	return challenges, nil
+}
+
+func (usecase *ChallengeUsecase) GetChallenge(id uint) (*domain.Challenge, error) {
+	challenge, err := usecase.ChallengeRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("challenge not found")
+		}
+		log.Printf("Error retrieving challenge with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to retrieve challenge")
+	}
+	return challenge, nil
+}
+
+// DeleteChallenge removes a challenge. Only its creator may do this, and the
+// participants keep the habits they tracked it with.
+func (usecase *ChallengeUsecase) DeleteChallenge(userID, id uint) error {
+	challenge, err := usecase.GetChallenge(id)
+	if err != nil {
+		return err
+	}
+	if challenge.CreatorID != userID {
+		return fmt.Errorf("permission denied")
+	}
+
+	if err := usecase.ChallengeRepo.Delete(id); err != nil {
+		log.Printf("Error deleting challenge with ID(%d): %v", id, err)
+		return fmt.Errorf("failed to delete challenge")
+	}
+
+	log.Printf("Challenge (%s) deleted", challenge.Name)
+	return nil
+}
+
+// JoinChallenge adds the user to a challenge that has not ended yet and returns
+// the habit created for them to track it with.
+func (usecase *ChallengeUsecase) JoinChallenge(userID, id uint) (*domain.Habit, error) {
+	challenge, err := usecase.GetChallenge(id)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := loadSettings(usecase.SettingsRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join challenge")
+	}
+	if challenge.HasEnded(*settings, time.Now()) {
+		return nil, fmt.Errorf("challenge has ended")
+	}
+
+	if _, err := usecase.ChallengeRepo.GetParticipant(id, userID); err == nil {
+		return nil, fmt.Errorf("already joined")
+	} else if err != gorm.ErrRecordNotFound {
+		log.Printf("Error retrieving participant of challenge with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to join challenge")
+	}
+
+	habit := challenge.Habit(userID)
+	participant := &domain.ChallengeParticipant{ChallengeID: id, UserID: userID}
+	if err := usecase.ChallengeRepo.Join(participant, &habit); err != nil {
+		log.Printf("Error joining challenge with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to join challenge")
+	}
+
+	log.Printf("User with ID(%d) joined challenge (%s)", userID, challenge.Name)
+	return &habit, nil
+}
+
+// LeaveChallenge removes the user from a challenge. The habit they tracked it
+// with stays theirs, with its history.
+func (usecase *ChallengeUsecase) LeaveChallenge(userID, id uint) error {
+	if _, err := usecase.GetChallenge(id); err != nil {
+		return err
+	}
+
+	if _, err := usecase.ChallengeRepo.GetParticipant(id, userID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("not a participant")
+		}
+		log.Printf("Error retrieving participant of challenge with ID(%d): %v", id, err)
+		return fmt.Errorf("failed to leave challenge")
+	}
+
+	if err := usecase.ChallengeRepo.Leave(id, userID); err != nil {
+		log.Printf("Error leaving challenge with ID(%d): %v", id, err)
+		return fmt.Errorf("failed to leave challenge")
+	}
+
+	log.Printf("User with ID(%d) left challenge with ID(%d)", userID, id)
+	return nil
+}
+
+// GetLeaderboard ranks the challenge's participants by the periods they
+// completed within its window, or by their streak within it with sortBy
+// StreakSortCurrent or StreakSortLongest. Only participants may see it.
+func (usecase *ChallengeUsecase) GetLeaderboard(userID, id uint, sortBy string) ([]LeaderboardEntry, error) {
+	if sortBy == "" {
+		sortBy = LeaderboardSortCompletions
+	}
+	if sortBy != LeaderboardSortCompletions && sortBy != StreakSortCurrent && sortBy != StreakSortLongest {
+		return nil, fmt.Errorf("invalid leaderboard sort")
+	}
+
+	challenge, err := usecase.GetChallenge(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := usecase.ChallengeRepo.GetParticipant(id, userID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("not a participant")
+		}
+		log.Printf("Error retrieving participant of challenge with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to get leaderboard")
+	}
+
+	participants, err := usecase.ChallengeRepo.GetParticipants(id)
+	if err != nil {
+		log.Printf("Error retrieving participants of challenge with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to get leaderboard")
+	}
+
+	now := time.Now()
+	cache := newSettingsCache(usecase.SettingsRepo)
+	entries := make([]LeaderboardEntry, 0, len(participants))
+	for _, participant := range participants {
+		habit, err := usecase.HabitRepo.GetByID(participant.HabitID)
+		if err == gorm.ErrRecordNotFound {
+			// The participant deleted the habit they tracked the challenge with
+			continue
+		}
+		if err != nil {
+			log.Printf("Error retrieving habit with ID(%d): %v", participant.HabitID, err)
+			return nil, fmt.Errorf("failed to get leaderboard")
+		}
+
+		completions, err := usecase.HabitRepo.GetCompletions(habit.ID)
+		if err != nil {
+			log.Printf("Error fetching completion history for habit with ID(%d): %v", habit.ID, err)
+			return nil, fmt.Errorf("failed to get leaderboard")
+		}
+
+		// Each participant's days follow their own settings
+		settings, err := cache.get(habit.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get leaderboard")
+		}
+		// Periods follow the challenge's schedule, so editing the habit cannot game the ranking
+		tracked := challenge.Habit(habit.UserID)
+		tracked.ID, tracked.CreatedAt = habit.ID, challenge.StartDate
+		engine, err := newStreakEngine(&tracked, *settings, usecase.SkipRepo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get leaderboard")
+		}
+
+		from, to := challenge.Window(*settings)
+		var inWindow []domain.HabitCompletion
+		for _, completion := range completions {
+			if !completion.CompletedAt.Before(from) && completion.CompletedAt.Before(to) {
+				inWindow = append(inWindow, completion)
+			}
+		}
+		end := to
+		if now.Before(to) {
+			end = now
+		}
+
+		streak := engine.Calculate(inWindow, end)
+		entry := LeaderboardEntry{
+			UserID:        participant.UserID,
+			HabitID:       habit.ID,
+			Completions:   engine.Stats(inWindow, from, end).CompletedPeriods,
+			CurrentStreak: streak.CurrentStreak,
+			LongestStreak: streak.LongestStreak,
+		}
+		if user, err := usecase.UserRepo.GetByID(participant.UserID); err == nil {
+			entry.Email = user.Email
+		} else {
+			log.Printf("Error retrieving user with ID(%d): %v", participant.UserID, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	rankEntries(entries, sortBy)
+	return entries, nil
+}
+
+// rankEntries sorts the leaderboard and numbers it, building on the streak
+// ordering of GetStreaks so both rank streaks the same way.
+func rankEntries(entries []LeaderboardEntry, sortBy string) {
+	streakSort := sortBy
+	if sortBy == LeaderboardSortCompletions {
+		streakSort = StreakSortCurrent
+	}
+	streak := func(entry LeaderboardEntry) domain.Habit {
+		return domain.Habit{CurrentStreak: entry.CurrentStreak, LongestStreak: entry.LongestStreak}
+	}
+	before := func(a, b LeaderboardEntry) bool {
+		if sortBy == LeaderboardSortCompletions && a.Completions != b.Completions {
+			return a.Completions > b.Completions
+		}
+		if x, y := streak(a), streak(b); streakBefore(x, y, streakSort) || streakBefore(y, x, streakSort) {
+			return streakBefore(x, y, streakSort)
+		}
+		return a.Completions > b.Completions
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return before(entries[i], entries[j])
+	})
+	for i := range entries {
+		if i > 0 && !before(entries[i-1], entries[i]) {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+}
+
+func parseChallengeDate(value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid challenge date")
+	}
+	return date, nil
+}
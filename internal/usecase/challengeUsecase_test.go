@@ -0,0 +1,256 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateChallenge(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       usecase.CreateChallengeInput
+		mockCreate  func(*domain.Challenge) error
+		errContains string
+	}{
+		{
+			name:  "valid challenge",
+			input: usecase.CreateChallengeInput{Name: "Walk 10k", Frequency: "daily", TargetValue: 10000, Unit: "steps", StartDate: "2026-11-01", EndDate: "2026-11-30"},
+		},
+		{
+			name:        "invalid date",
+			input:       usecase.CreateChallengeInput{Name: "Walk 10k", Frequency: "daily", StartDate: "November", EndDate: "2026-11-30"},
+			errContains: "invalid challenge date",
+		},
+		{
+			name:        "invalid window",
+			input:       usecase.CreateChallengeInput{Name: "Walk 10k", Frequency: "daily", StartDate: "2026-11-30", EndDate: "2026-11-01"},
+			errContains: "challenge end date cannot be before its start date",
+		},
+		{
+			name:        "repository error",
+			input:       usecase.CreateChallengeInput{Name: "Walk 10k", Frequency: "daily", StartDate: "2026-11-01", EndDate: "2026-11-30"},
+			mockCreate:  func(*domain.Challenge) error { return errors.New("db error") },
+			errContains: "failed to create challenge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &usecase.ChallengeUsecase{ChallengeRepo: &usecase.MockChallengeRepo{CreateFn: tt.mockCreate}}
+
+			challenge, err := uc.CreateChallenge(testUserID, tt.input)
+
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testUserID, challenge.CreatorID)
+			assert.Equal(t, time.Date(2026, time.November, 30, 0, 0, 0, 0, time.UTC), challenge.EndDate)
+		})
+	}
+}
+
+func TestJoinChallenge(t *testing.T) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	running := &domain.Challenge{ID: 1, Name: "Walk 10k", Frequency: "daily", TargetValue: 10000, Unit: "steps", StartDate: today.AddDate(0, 0, -3), EndDate: today.AddDate(0, 0, 3)}
+	ended := &domain.Challenge{ID: 2, Name: "Read", Frequency: "daily", StartDate: today.AddDate(0, 0, -30), EndDate: today.AddDate(0, 0, -2)}
+
+	tests := []struct {
+		name            string
+		challengeID     uint
+		mockParticipant func(uint, uint) (*domain.ChallengeParticipant, error)
+		errContains     string
+	}{
+		{
+			name:            "join running challenge",
+			challengeID:     1,
+			mockParticipant: func(uint, uint) (*domain.ChallengeParticipant, error) { return nil, gorm.ErrRecordNotFound },
+		},
+		{
+			name:            "already joined",
+			challengeID:     1,
+			mockParticipant: func(uint, uint) (*domain.ChallengeParticipant, error) { return &domain.ChallengeParticipant{}, nil },
+			errContains:     "already joined",
+		},
+		{
+			name:        "challenge has ended",
+			challengeID: 2,
+			errContains: "challenge has ended",
+		},
+		{
+			name:        "unknown challenge",
+			challengeID: 3,
+			errContains: "challenge not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var joined *domain.ChallengeParticipant
+			uc := &usecase.ChallengeUsecase{
+				ChallengeRepo: &usecase.MockChallengeRepo{
+					GetByIDFn: func(id uint) (*domain.Challenge, error) {
+						switch id {
+						case 1:
+							return running, nil
+						case 2:
+							return ended, nil
+						}
+						return nil, gorm.ErrRecordNotFound
+					},
+					GetParticipantFn: tt.mockParticipant,
+					JoinFn: func(p *domain.ChallengeParticipant, habit *domain.Habit) error {
+						habit.ID = 42
+						p.HabitID = habit.ID
+						joined = p
+						return nil
+					},
+				},
+			}
+
+			habit, err := uc.JoinChallenge(testUserID, tt.challengeID)
+
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				assert.Nil(t, joined)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, &domain.ChallengeParticipant{ChallengeID: 1, UserID: testUserID, HabitID: 42}, joined)
+			assert.Equal(t, testUserID, habit.UserID)
+			assert.Equal(t, "Walk 10k", habit.Name)
+			assert.Equal(t, "steps", habit.Unit)
+		})
+	}
+}
+
+func TestLeaveChallenge(t *testing.T) {
+	left := false
+	uc := &usecase.ChallengeUsecase{
+		ChallengeRepo: &usecase.MockChallengeRepo{
+			GetByIDFn: func(id uint) (*domain.Challenge, error) { return &domain.Challenge{ID: id}, nil },
+			GetParticipantFn: func(challengeID, userID uint) (*domain.ChallengeParticipant, error) {
+				if userID != testUserID {
+					return nil, gorm.ErrRecordNotFound
+				}
+				return &domain.ChallengeParticipant{ChallengeID: challengeID, UserID: userID}, nil
+			},
+			LeaveFn: func(challengeID, userID uint) error {
+				left = true
+				return nil
+			},
+		},
+	}
+
+	assert.ErrorContains(t, uc.LeaveChallenge(2, 1), "not a participant")
+	assert.False(t, left)
+	assert.NoError(t, uc.LeaveChallenge(testUserID, 1))
+	assert.True(t, left)
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	challenge := &domain.Challenge{
+		ID:        1,
+		Frequency: "daily",
+		StartDate: time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, time.November, 30, 0, 0, 0, 0, time.UTC),
+	}
+	at := func(month time.Month, day int) domain.HabitCompletion {
+		return domain.HabitCompletion{CompletedAt: time.Date(2025, month, day, 12, 0, 0, 0, time.UTC)}
+	}
+	// The stored streaks span the habits' whole history and are ignored
+	habits := map[uint]*domain.Habit{
+		11: {ID: 11, Frequency: "daily", CurrentStreak: 9, LongestStreak: 9},
+		// Editing the habit's schedule does not change how the challenge counts it
+		12: {ID: 12, Frequency: "monthly", TargetValue: 100},
+		13: {ID: 13, Frequency: "daily"},
+	}
+	completions := map[uint][]domain.HabitCompletion{
+		// Completions outside the window do not count
+		11: {at(time.October, 31), at(time.November, 1), at(time.November, 2), at(time.December, 1)},
+		// Several completions on one day complete a single period
+		12: {at(time.November, 10), at(time.November, 10), at(time.November, 10), at(time.November, 11), at(time.November, 12)},
+		13: {at(time.November, 20), at(time.November, 30)},
+	}
+
+	uc := &usecase.ChallengeUsecase{
+		ChallengeRepo: &usecase.MockChallengeRepo{
+			GetByIDFn: func(id uint) (*domain.Challenge, error) { return challenge, nil },
+			GetParticipantFn: func(challengeID, userID uint) (*domain.ChallengeParticipant, error) {
+				if userID > 4 {
+					return nil, gorm.ErrRecordNotFound
+				}
+				return &domain.ChallengeParticipant{ChallengeID: challengeID, UserID: userID}, nil
+			},
+			GetParticipantsFn: func(uint) ([]domain.ChallengeParticipant, error) {
+				return []domain.ChallengeParticipant{
+					{UserID: 1, HabitID: 11},
+					{UserID: 2, HabitID: 12},
+					{UserID: 3, HabitID: 13},
+					{UserID: 4, HabitID: 14}, // deleted their habit
+				}, nil
+			},
+		},
+		HabitRepo: &usecase.MockHabitRepo{
+			GetByIDFn: func(id uint) (*domain.Habit, error) {
+				if habit, ok := habits[id]; ok {
+					return habit, nil
+				}
+				return nil, gorm.ErrRecordNotFound
+			},
+			GetCompletionsFn: func(id uint) ([]domain.HabitCompletion, error) { return completions[id], nil },
+		},
+		UserRepo: &usecase.MockUserRepo{
+			GetByIDFn: func(id uint) (*domain.User, error) { return &domain.User{ID: id}, nil },
+		},
+	}
+
+	ranking := func(entries []usecase.LeaderboardEntry) [][2]uint {
+		var got [][2]uint
+		for _, entry := range entries {
+			got = append(got, [2]uint{uint(entry.Rank), entry.UserID})
+		}
+		return got
+	}
+
+	tests := []struct {
+		sortBy      string
+		want        [][2]uint // rank and user ID
+		errContains string
+	}{
+		// User 1 and 3 are tied on completed periods, user 3 is still on a streak at the end
+		{sortBy: "", want: [][2]uint{{1, 2}, {2, 3}, {3, 1}}},
+		{sortBy: "current", want: [][2]uint{{1, 3}, {2, 2}, {3, 1}}},
+		{sortBy: "longest", want: [][2]uint{{1, 2}, {2, 1}, {3, 3}}},
+		{sortBy: "fastest", errContains: "invalid leaderboard sort"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			entries, err := uc.GetLeaderboard(testUserID, 1, tt.sortBy)
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, ranking(entries))
+		})
+	}
+
+	entries, err := uc.GetLeaderboard(testUserID, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, entries[0].Completions)
+	assert.Equal(t, 2, entries[2].Completions)
+	assert.Equal(t, 2, entries[2].LongestStreak)
+	assert.Equal(t, 0, entries[2].CurrentStreak)
+
+	_, err = uc.GetLeaderboard(5, 1, "")
+	assert.ErrorContains(t, err, "not a participant")
+}
@@ -11,18 +11,101 @@ import (
 )
 
 type HabitUsecase struct {
-	HabitRepo domain.HabitRepository
+	HabitRepo    domain.HabitRepository
+	SettingsRepo domain.SettingsRepository
+	TimerRepo    domain.TimerSessionRepository
+	SkipRepo     domain.SkipRepository
 }
 
-func (usecase *HabitUsecase) CreateHabit(habit *domain.Habit) error {
+// maxBulkCompletions is the most completions a single bulk check-in can record
+const maxBulkCompletions = 100
+
+// BulkCompletionInput is one item of a bulk check-in: the habit and, as for a
+// single completion, when it happened and the quantity logged.
+type BulkCompletionInput struct {
+	HabitID uint `json:"habit_id"`
+	CompletionInput
+}
+
+// BulkCompletionResult reports the outcome of one item of a bulk check-in. Error
+// is empty if the completion was recorded.
+type BulkCompletionResult struct {
+	HabitID       uint
+	Completed     bool
+	Error         string
+	CurrentStreak int
+}
+
+// Today is the checklist for a day: the build habits grouped by the status of
+// their period on Date, each with its next due date and progress.
+type Today struct {
+	Date      string
+	Due       []domain.Habit
+	Overdue   []domain.Habit
+	Completed []domain.Habit
+	Skipped   []domain.Habit
+}
+
+// maxStrengthHistory is the number of most recent periods returned in a habit's strength history
+const maxStrengthHistory = 365
+
+// Orderings for GetStreaks
+const (
+	StreakSortCurrent = "current"
+	StreakSortLongest = "longest"
+)
+
+// RelapseInput describes when a quit habit's relapse happened, in the same way as
+// CompletionInput, with an optional note.
+type RelapseInput struct {
+	OccurredAt *time.Time `json:"occurred_at"`
+	Date       string     `json:"date"`
+	Note       string     `json:"note"`
+}
+
+// CompletionInput describes when a completion happened. CompletedAt takes precedence
+// over Date, a calendar day (YYYY-MM-DD) in the configured timezone. When both are
+// empty the completion is recorded now. Quantity is the amount logged towards a
+// measurable habit's target and is required for those habits.
+type CompletionInput struct {
+	CompletedAt *time.Time `json:"completed_at"`
+	Date        string     `json:"date"`
+	Quantity    float64    `json:"quantity"`
+}
+
+func (usecase *HabitUsecase) CreateHabit(userID uint, habit *domain.Habit) error {
+	// Only keep what the user may set, streaks, freezes and timestamps are managed by the server
+	*habit = domain.Habit{
+		Name:           habit.Name,
+		Frequency:      habit.Frequency,
+		Kind:           habit.Kind,
+		TimesPerPeriod: habit.TimesPerPeriod,
+		Weekdays:       habit.Weekdays,
+		MonthDays:      habit.MonthDays,
+		IntervalDays:   habit.IntervalDays,
+		RRule:          habit.RRule,
+		TargetValue:    habit.TargetValue,
+		Unit:           habit.Unit,
+	}
+
 	if habit.Name == "" {
 		return fmt.Errorf("habit name cannot be empty")
 	}
 
+	if err := domain.ValidateKind(habit); err != nil {
+		return err
+	}
+
+	// Validate the schedule first, a recurrence rule can supply the frequency
+	if err := domain.ValidateSchedule(habit); err != nil {
+		return err
+	}
+
 	if !domain.IsValidFrequency(habit.Frequency) {
 		return fmt.Errorf("invalid frequency type: %s", habit.Frequency)
 	}
 
+	habit.UserID = userID
 	if err := usecase.HabitRepo.Create(habit); err != nil {
 		log.Println("Error creating habit:", err)
 		return fmt.Errorf("failed to create habit")
@@ -31,12 +114,34 @@ func (usecase *HabitUsecase) CreateHabit(habit *domain.Habit) error {
 	return nil
 }
 
-func (usecase *HabitUsecase) GetAllHabits() ([]domain.Habit, error) {
-	habits, err := usecase.HabitRepo.GetAll()
+// GetAllHabits returns the user's own habits along with those shared with them
+// by other users, each with the user's role in it.
+func (usecase *HabitUsecase) GetAllHabits(userID uint) ([]domain.Habit, error) {
+	habits, err := usecase.HabitRepo.GetAllForUser(userID)
 	if err != nil {
 		log.Println("Error retrieving all habits:", err)
 		return nil, fmt.Errorf("failed to get habits")
 	}
+	for i := range habits {
+		habits[i].Role = domain.RoleOwner
+	}
+
+	shared, err := usecase.HabitRepo.GetAllSharedWithUser(userID)
+	if err != nil {
+		log.Printf("Error retrieving habits shared with user with ID(%d): %v", userID, err)
+		return nil, fmt.Errorf("failed to get habits")
+	}
+	habits = append(habits, shared...)
+
+	cache := newSettingsCache(usecase.SettingsRepo)
+	now := time.Now()
+	for i := range habits {
+		settings, err := cache.get(habits[i].UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get habits")
+		}
+		refreshDaysClean(&habits[i], *settings, now)
+	}
 
 	sort.Slice(habits, func(i, j int) bool {
 		return habits[i].CurrentStreak > habits[j].CurrentStreak
@@ -44,8 +149,10 @@ func (usecase *HabitUsecase) GetAllHabits() ([]domain.Habit, error) {
 	return habits, nil
 }
 
-func (usecase *HabitUsecase) GetHabitByID(id uint) (*domain.Habit, error) {
-	habit, err := usecase.HabitRepo.GetByID(id)
+// GetHabitByID returns the habit if the user owns it or it was shared with them,
+// with Role set to the user's access.
+func (usecase *HabitUsecase) GetHabitByID(userID, id uint) (*domain.Habit, error) {
+	habit, role, err := usecase.HabitRepo.GetByIDForMember(id, userID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("habit not found")
@@ -53,12 +160,178 @@ func (usecase *HabitUsecase) GetHabitByID(id uint) (*domain.Habit, error) {
 		log.Printf("Error retrieving habit with ID(%d): %v", id, err)
 		return nil, fmt.Errorf("failed to retrieve habit")
 	}
+	habit.Role = role
+	return habit, nil
+}
+
+// editableHabit returns the habit if the user may change it, as its owner or a co-owner.
+func (usecase *HabitUsecase) editableHabit(userID, id uint) (*domain.Habit, error) {
+	habit, err := usecase.GetHabitByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !habit.Role.CanEdit() {
+		return nil, fmt.Errorf("permission denied")
+	}
+	return habit, nil
+}
+
+// GetHabitDetails returns the habit along with the fields computed from its
+// schedule and completion history, including its next occurrences and past streaks.
+func (usecase *HabitUsecase) GetHabitDetails(userID, id uint, occurrences int) (*domain.Habit, error) {
+	habit, err := usecase.GetHabitByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := loadSettings(usecase.SettingsRepo, habit.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve habit")
+	}
+
+	runs, err := usecase.HabitRepo.GetStreakRuns(id)
+	if err != nil {
+		log.Printf("Error fetching streak history for habit with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to retrieve habit")
+	}
+	if runs == nil {
+		runs = []domain.StreakRun{}
+	}
+	habit.StreakRuns = runs
+
+	// Quit habits are never due, their streak is the days since the last relapse
+	if habit.IsQuit() {
+		refreshDaysClean(habit, *settings, time.Now())
+		return habit, nil
+	}
+
+	completions, err := usecase.HabitRepo.GetCompletions(id)
+	if err != nil {
+		log.Printf("Error fetching completion history for habit with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to retrieve habit")
+	}
+
+	engine, err := newStreakEngine(habit, *settings, usecase.SkipRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve habit")
+	}
+
+	now := time.Now()
+	if nextDue, ok := engine.NextDue(completions, now); ok {
+		habit.NextDueAt = &nextDue
+	}
+	habit.NextOccurrences = engine.Occurrences(now, occurrences)
+	habit.PeriodProgress = engine.PeriodProgress(completions, now)
+
+	result := engine.Calculate(completions, now)
+	habit.StreakFreezes = result.Freezes
+	habit.FreezeHistory = result.FreezeHistory
+
+	strength, history := engine.Strength(completions, now)
+	if len(history) > maxStrengthHistory {
+		history = history[len(history)-maxStrengthHistory:]
+	}
+	habit.Strength = strength
+	habit.StrengthHistory = history
+
 	return habit, nil
 }
 
-func (usecase *HabitUsecase) UpdateHabit(habit *domain.Habit) error {
-	existingHabit, err := usecase.GetHabitByID(habit.ID)
+// GetToday returns the checklist for the calendar day date (YYYY-MM-DD), or for
+// today if date is empty. Today is evaluated as of now, past days as of their
+// end and future days as of their start, with nothing overdue ahead of time.
+// Quit habits and habits with no period in progress are left out.
+func (usecase *HabitUsecase) GetToday(userID uint, date string) (*Today, error) {
+	settings, err := loadSettings(usecase.SettingsRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get today")
+	}
+
+	now := time.Now()
+	at := now
+	if date != "" {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date")
+		}
+
+		start := settings.DayStart(day.Year(), day.Month(), day.Day())
+		end := settings.DayStart(day.Year(), day.Month(), day.Day()+1)
+		switch {
+		case end.Before(now):
+			at = end.Add(-time.Nanosecond)
+		case start.After(now):
+			at = start
+		}
+	}
+
+	habits, err := usecase.HabitRepo.GetAllForUser(userID)
+	if err != nil {
+		log.Println("Error retrieving all habits:", err)
+		return nil, fmt.Errorf("failed to get today")
+	}
+
+	today := &Today{
+		Date:      civilToday(*settings, at).Format("2006-01-02"),
+		Due:       []domain.Habit{},
+		Overdue:   []domain.Habit{},
+		Completed: []domain.Habit{},
+		Skipped:   []domain.Habit{},
+	}
+	for i := range habits {
+		habit := &habits[i]
+		if habit.IsQuit() {
+			continue
+		}
+
+		completions, err := usecase.HabitRepo.GetCompletions(habit.ID)
+		if err != nil {
+			log.Printf("Error fetching completion history for habit with ID(%d): %v", habit.ID, err)
+			return nil, fmt.Errorf("failed to get today")
+		}
+
+		// Only count what had been completed by then
+		done := make([]domain.HabitCompletion, 0, len(completions))
+		for _, completion := range completions {
+			if !completion.CompletedAt.After(at) {
+				done = append(done, completion)
+			}
+		}
+
+		engine, err := newStreakEngine(habit, *settings, usecase.SkipRepo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get today")
+		}
+
+		if nextDue, ok := engine.NextDue(done, at); ok {
+			habit.NextDueAt = &nextDue
+		}
+		habit.PeriodProgress = engine.PeriodProgress(done, at)
+
+		switch engine.Status(done, trackedSince(habit, completions), at) {
+		case domain.StatusOverdue:
+			if at.After(now) {
+				today.Due = append(today.Due, *habit)
+			} else {
+				today.Overdue = append(today.Overdue, *habit)
+			}
+		case domain.StatusDue:
+			today.Due = append(today.Due, *habit)
+		case domain.StatusCompleted:
+			today.Completed = append(today.Completed, *habit)
+		case domain.StatusSkipped:
+			today.Skipped = append(today.Skipped, *habit)
+		}
+	}
+	return today, nil
+}
+
+func (usecase *HabitUsecase) UpdateHabit(userID uint, habit *domain.Habit) error {
+	existingHabit, err := usecase.editableHabit(userID, habit.ID)
 	if err != nil {
+		if err.Error() == "permission denied" {
+			return err
+		}
 		log.Println("Error retrieving habit while trying to update habit:", err)
 		return fmt.Errorf("failed to retrieve existing habit")
 	}
@@ -67,12 +340,65 @@ func (usecase *HabitUsecase) UpdateHabit(habit *domain.Habit) error {
 		return fmt.Errorf("habit name cannot be empty")
 	}
 
+	if err := domain.ValidateKind(existingHabit); err != nil {
+		return err
+	}
+	if habit.Kind == "" {
+		habit.Kind = existingHabit.Kind
+	}
+	if habit.Kind != existingHabit.Kind {
+		return fmt.Errorf("habit kind cannot be changed")
+	}
+	if err := domain.ValidateKind(habit); err != nil {
+		return err
+	}
+
+	// Validate the schedule first, a recurrence rule can supply the frequency
+	if err := domain.ValidateSchedule(habit); err != nil {
+		return err
+	}
+
 	if !domain.IsValidFrequency(habit.Frequency) {
 		return fmt.Errorf("invalid frequency type: %s", habit.Frequency)
 	}
 
+	scheduleChanged := existingHabit.Frequency != habit.Frequency ||
+		existingHabit.TimesPerPeriod != habit.TimesPerPeriod ||
+		existingHabit.Weekdays != habit.Weekdays ||
+		existingHabit.MonthDays != habit.MonthDays ||
+		existingHabit.IntervalDays != habit.IntervalDays ||
+		existingHabit.RRule != habit.RRule ||
+		existingHabit.TargetValue != habit.TargetValue
+
 	existingHabit.Name = habit.Name
 	existingHabit.Frequency = habit.Frequency
+	existingHabit.TimesPerPeriod = habit.TimesPerPeriod
+	existingHabit.Weekdays = habit.Weekdays
+	existingHabit.MonthDays = habit.MonthDays
+	existingHabit.IntervalDays = habit.IntervalDays
+	existingHabit.RRule = habit.RRule
+	existingHabit.TargetValue = habit.TargetValue
+	existingHabit.Unit = habit.Unit
+
+	// A new schedule changes which periods were completed, so replay the history
+	if scheduleChanged {
+		settings, err := loadSettings(usecase.SettingsRepo, existingHabit.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to update habit")
+		}
+
+		completions, err := usecase.HabitRepo.GetCompletions(existingHabit.ID)
+		if err != nil {
+			log.Printf("Error fetching completion history for habit with ID(%d): %v", habit.ID, err)
+			return fmt.Errorf("failed to update habit")
+		}
+
+		engine, err := newStreakEngine(existingHabit, *settings, usecase.SkipRepo)
+		if err != nil {
+			return fmt.Errorf("failed to update habit")
+		}
+		recalculateStreak(existingHabit, completions, engine)
+	}
 
 	err = usecase.HabitRepo.Update(existingHabit)
 	if err != nil {
@@ -84,12 +410,15 @@ func (usecase *HabitUsecase) UpdateHabit(habit *domain.Habit) error {
 	return nil
 }
 
-func (usecase *HabitUsecase) DeleteHabit(id uint) error {
-	habit, err := usecase.GetHabitByID(id)
+func (usecase *HabitUsecase) DeleteHabit(userID, id uint) error {
+	habit, err := usecase.GetHabitByID(userID, id)
 	if err != nil {
 		log.Println("Error: Tried to delete non-existing habit with ID:", id)
 		return fmt.Errorf("habit not found")
 	}
+	if habit.Role != domain.RoleOwner {
+		return fmt.Errorf("permission denied")
+	}
 
 	err = usecase.HabitRepo.Delete(id)
 	if err != nil {
@@ -101,27 +430,53 @@ func (usecase *HabitUsecase) DeleteHabit(id uint) error {
 	return nil
 }
 
-func (usecase *HabitUsecase) MarkCompleted(id uint) error {
-	habit, err := usecase.GetHabitByID(id)
+// MarkCompleted records a completion for the habit at the time described by input
+// and recalculates the streak from the full completion history so backdated entries count.
+func (usecase *HabitUsecase) MarkCompleted(userID, id uint, input CompletionInput) error {
+	habit, err := usecase.editableHabit(userID, id)
 	if err != nil {
+		if err.Error() == "permission denied" {
+			return err
+		}
 		log.Println("Error fetching habit for completion", err)
 		return fmt.Errorf("habit not found")
 	}
 
-	now := time.Now()
+	if habit.IsQuit() {
+		return fmt.Errorf("quit habits cannot be completed, log a relapse instead")
+	}
 
-	if habit.LastCompletedAt == nil || (habit.Frequency == "daily" && habit.LastCompletedAt.Add(24*time.Hour).Before(now)) {
-		habit.CurrentStreak = 1
-	} else if habit.Frequency == "weekly" && habit.LastCompletedAt.Add(7*24*time.Hour).Before(now) {
-		habit.CurrentStreak = 1
-	} else {
-		habit.CurrentStreak++
+	settings, err := loadSettings(usecase.SettingsRepo, habit.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to mark habit as complete")
+	}
+
+	completedAt, err := completionTime(input, habit, *settings, time.Now())
+	if err != nil {
+		return err
 	}
 
-	habit.LastCompletedAt = &now
-	habit.TotalCompletions++
+	quantity, err := completionQuantity(habit, input)
+	if err != nil {
+		return err
+	}
+
+	completions, err := usecase.HabitRepo.GetCompletions(id)
+	if err != nil {
+		log.Println("Error fetching completion history for habit:", err)
+		return fmt.Errorf("failed to mark habit as complete")
+	}
 
-	if err := usecase.HabitRepo.SafeUpdate(habit); err != nil {
+	engine, err := newStreakEngine(habit, *settings, usecase.SkipRepo)
+	if err != nil {
+		return fmt.Errorf("failed to mark habit as complete")
+	}
+
+	completion := &domain.HabitCompletion{HabitID: habit.ID, CompletedAt: completedAt, Quantity: quantity}
+	recalculateStreak(habit, append(completions, *completion), engine)
+	completion.Strength = completionStrength(habit, completion, engine)
+
+	if err := usecase.HabitRepo.AddCompletion(habit, completion); err != nil {
 		log.Println("Error marking habit as completed:", err)
 		return fmt.Errorf("failed to mark habit as complete")
 	}
@@ -130,11 +485,635 @@ func (usecase *HabitUsecase) MarkCompleted(id uint) error {
 	return nil
 }
 
-func (usecase *HabitUsecase) GetStreaks() ([]domain.Habit, error) {
-	habits, err := usecase.HabitRepo.GetStreaks()
+// MarkCompletedBulk records a batch of completions. Items that cannot be recorded,
+// e.g. for a habit that does not exist, are reported in their result and the
+// others are recorded together in a single transaction.
+func (usecase *HabitUsecase) MarkCompletedBulk(userID uint, inputs []BulkCompletionInput) ([]BulkCompletionResult, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no completions given")
+	}
+	if len(inputs) > maxBulkCompletions {
+		return nil, fmt.Errorf("too many completions, at most %d", maxBulkCompletions)
+	}
+
+	// Each habit is tracked in its owner's settings
+	cache := newSettingsCache(usecase.SettingsRepo)
+	now := time.Now()
+	results := make([]BulkCompletionResult, len(inputs))
+	habits := map[uint]*domain.Habit{}
+	history := map[uint][]domain.HabitCompletion{}
+	pending := map[uint][]*domain.HabitCompletion{}
+	var order []uint // habits in the order they first appear
+
+	for i, input := range inputs {
+		results[i].HabitID = input.HabitID
+
+		habit, ok := habits[input.HabitID]
+		if !ok {
+			var err error
+			habit, err = usecase.editableHabit(userID, input.HabitID)
+			if err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+
+			completions, err := usecase.HabitRepo.GetCompletions(habit.ID)
+			if err != nil {
+				log.Printf("Error fetching completion history for habit with ID(%d): %v", habit.ID, err)
+				results[i].Error = "failed to mark habit as complete"
+				continue
+			}
+			habits[habit.ID], history[habit.ID] = habit, completions
+		}
+
+		if habit.IsQuit() {
+			results[i].Error = "quit habits cannot be completed, log a relapse instead"
+			continue
+		}
+
+		settings, err := cache.get(habit.UserID)
+		if err != nil {
+			results[i].Error = "failed to mark habit as complete"
+			continue
+		}
+
+		completedAt, err := completionTime(input.CompletionInput, habit, *settings, now)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		quantity, err := completionQuantity(habit, input.CompletionInput)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		if len(pending[habit.ID]) == 0 {
+			order = append(order, habit.ID)
+		}
+		pending[habit.ID] = append(pending[habit.ID], &domain.HabitCompletion{HabitID: habit.ID, CompletedAt: completedAt, Quantity: quantity})
+		results[i].Completed = true
+	}
+
+	if len(order) == 0 {
+		return results, nil
+	}
+
+	var changed []*domain.Habit
+	var completions []*domain.HabitCompletion
+	for _, id := range order {
+		habit := habits[id]
+		settings, err := cache.get(habit.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark habits as complete")
+		}
+		engine, err := newStreakEngine(habit, *settings, usecase.SkipRepo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark habits as complete")
+		}
+
+		all := history[id]
+		for _, completion := range pending[id] {
+			all = append(all, *completion)
+		}
+		recalculateStreak(habit, all, engine)
+		for _, completion := range pending[id] {
+			completion.Strength = completionStrength(habit, completion, engine)
+		}
+
+		changed = append(changed, habit)
+		completions = append(completions, pending[id]...)
+	}
+
+	if err := usecase.HabitRepo.AddCompletions(changed, completions); err != nil {
+		log.Println("Error marking habits as completed:", err)
+		return nil, fmt.Errorf("failed to mark habits as complete")
+	}
+
+	for i := range results {
+		if habit, ok := habits[results[i].HabitID]; ok && results[i].Completed {
+			results[i].CurrentStreak = habit.CurrentStreak
+		}
+	}
+
+	log.Printf("Bulk check-in recorded %d completions for %d habits", len(completions), len(changed))
+	return results, nil
+}
+
+// RemoveCompletion undoes every completion logged for the habit on the given
+// calendar day (YYYY-MM-DD) and recalculates the streak from the remaining history.
+func (usecase *HabitUsecase) RemoveCompletion(userID, id uint, date string) error {
+	habit, err := usecase.editableHabit(userID, id)
+	if err != nil {
+		if err.Error() == "permission denied" {
+			return err
+		}
+		log.Println("Error fetching habit for completion removal", err)
+		return fmt.Errorf("habit not found")
+	}
+
+	settings, err := loadSettings(usecase.SettingsRepo, habit.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to remove completion")
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return fmt.Errorf("invalid completion date")
+	}
+	from := settings.DayStart(day.Year(), day.Month(), day.Day())
+	to := settings.DayStart(day.Year(), day.Month(), day.Day()+1)
+
+	completions, err := usecase.HabitRepo.GetCompletions(id)
+	if err != nil {
+		log.Println("Error fetching completion history for habit:", err)
+		return fmt.Errorf("failed to remove completion")
+	}
+
+	remaining := make([]domain.HabitCompletion, 0, len(completions))
+	for _, completion := range completions {
+		if completion.CompletedAt.Before(from) || !completion.CompletedAt.Before(to) {
+			remaining = append(remaining, completion)
+		}
+	}
+	if len(remaining) == len(completions) {
+		return fmt.Errorf("completion not found")
+	}
+
+	engine, err := newStreakEngine(habit, *settings, usecase.SkipRepo)
+	if err != nil {
+		return fmt.Errorf("failed to remove completion")
+	}
+	recalculateStreak(habit, remaining, engine)
+
+	if err := usecase.HabitRepo.RemoveCompletions(habit, from, to); err != nil {
+		log.Printf("Error removing completions for habit with ID(%d): %v", id, err)
+		return fmt.Errorf("failed to remove completion")
+	}
+
+	log.Printf("Completion on %s removed from habit with ID(%d). Current streak: %d", date, id, habit.CurrentStreak)
+	return nil
+}
+
+// completionTime resolves the instant a completion should be recorded at. A bare
+// date is placed in the middle of that day, or at now if that is still to come today.
+// It cannot be in the future or before the day the habit was created.
+func completionTime(input CompletionInput, habit *domain.Habit, settings domain.Settings, now time.Time) (time.Time, error) {
+	completedAt := now
+	if input.CompletedAt != nil {
+		if input.CompletedAt.After(now) {
+			return time.Time{}, fmt.Errorf("completion time cannot be in the future")
+		}
+		completedAt = *input.CompletedAt
+	} else if input.Date != "" {
+		day, err := time.Parse("2006-01-02", input.Date)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid completion date")
+		}
+
+		start := settings.DayStart(day.Year(), day.Month(), day.Day())
+		if start.After(now) {
+			return time.Time{}, fmt.Errorf("completion time cannot be in the future")
+		}
+
+		if midday := start.Add(12 * time.Hour); midday.Before(now) {
+			completedAt = midday
+		}
+	}
+
+	if !habit.CreatedAt.IsZero() {
+		created := habit.CreatedAt.In(settings.Location()).Add(-time.Duration(settings.DayEndHour) * time.Hour)
+		if completedAt.Before(settings.DayStart(created.Year(), created.Month(), created.Day())) {
+			return time.Time{}, fmt.Errorf("completion time cannot be before the habit was created")
+		}
+	}
+	return completedAt, nil
+}
+
+// completionQuantity returns the quantity to record for a completion. Measurable
+// habits need a positive quantity, any other completion counts once.
+func completionQuantity(habit *domain.Habit, input CompletionInput) (float64, error) {
+	if input.Quantity < 0 {
+		return 0, fmt.Errorf("quantity must be positive")
+	}
+	if habit.TargetValue <= 0 {
+		return 1, nil
+	}
+	if input.Quantity == 0 {
+		return 0, fmt.Errorf("quantity is required for measurable habits")
+	}
+	return input.Quantity, nil
+}
+
+// refreshDaysClean sets a quit habit's streak to the days since its last relapse,
+// or since it was created. It grows without any new events, so it is computed on read.
+func refreshDaysClean(habit *domain.Habit, settings domain.Settings, now time.Time) {
+	if !habit.IsQuit() {
+		return
+	}
+
+	since := habit.CreatedAt
+	if habit.LastRelapseAt != nil {
+		since = *habit.LastRelapseAt
+	}
+	engine := domain.NewStreakEngine(domain.HabitSchedule(*habit), settings)
+	habit.CurrentStreak = engine.DaysClean(since, now)
+	if habit.CurrentStreak > habit.LongestStreak {
+		habit.LongestStreak = habit.CurrentStreak
+	}
+}
+
+// newStreakEngine builds the streak engine for the habit's schedule, the settings
+// and the skips that apply to the habit.
+func newStreakEngine(habit *domain.Habit, settings domain.Settings, skipRepo domain.SkipRepository) (domain.StreakEngine, error) {
+	engine := domain.NewStreakEngine(domain.HabitSchedule(*habit), settings)
+
+	skips, err := loadSkips(skipRepo, habit)
+	if err != nil {
+		return engine, err
+	}
+	engine.Skips = skips
+	return engine, nil
+}
+
+// recalculateStreak replays the completion history through the streak engine and
+// updates the habit's streak, last completion time and total completions to match it.
+func recalculateStreak(habit *domain.Habit, completions []domain.HabitCompletion, engine domain.StreakEngine) {
+	result := engine.Calculate(completions, time.Now())
+
+	habit.CurrentStreak = result.CurrentStreak
+	habit.LastCompletedAt = result.LastCompletedAt
+	habit.TotalCompletions = result.TotalCompletions
+	habit.LongestStreak = result.LongestStreak
+	habit.StreakRuns = result.Runs
+	if habit.StreakRuns == nil {
+		habit.StreakRuns = []domain.StreakRun{}
+	}
+	habit.StreakFreezes = result.Freezes
+	habit.Strength, habit.StrengthHistory = engine.Strength(completions, time.Now())
+}
+
+// completionStrength returns the habit's strength after the completion's period,
+// or its current strength if that period is still in progress.
+func completionStrength(habit *domain.Habit, completion *domain.HabitCompletion, engine domain.StreakEngine) float64 {
+	if strength, ok := domain.StrengthAt(habit.StrengthHistory, engine.PeriodStart(completion.CompletedAt)); ok {
+		return strength
+	}
+	return habit.Strength
+}
+
+// GetStreaks returns the habits with an active streak, longest first, or with
+// StreakSortLongest every habit that has had a streak, by its longest streak.
+// Quit habits are ranked by their days clean.
+func (usecase *HabitUsecase) GetStreaks(userID uint, sortBy string) ([]domain.Habit, error) {
+	if sortBy == "" {
+		sortBy = StreakSortCurrent
+	}
+	if sortBy != StreakSortCurrent && sortBy != StreakSortLongest {
+		return nil, fmt.Errorf("invalid streak sort")
+	}
+
+	var habits []domain.Habit
+	var err error
+	if sortBy == StreakSortLongest {
+		habits, err = usecase.HabitRepo.GetAllForUser(userID)
+	} else {
+		habits, err = usecase.HabitRepo.GetStreaksForUser(userID)
+	}
 	if err != nil {
 		log.Println("Error retrieving all habit streaks:", err)
 		return nil, fmt.Errorf("failed to get all habit streaks")
 	}
-	return habits, nil
+
+	settings, err := loadSettings(usecase.SettingsRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all habit streaks")
+	}
+
+	now := time.Now()
+	streaks := make([]domain.Habit, 0, len(habits))
+	for _, habit := range habits {
+		refreshDaysClean(&habit, *settings, now)
+		if habit.CurrentStreak > 0 || (sortBy == StreakSortLongest && habit.LongestStreak > 0) {
+			streaks = append(streaks, habit)
+		}
+	}
+
+	sort.SliceStable(streaks, func(i, j int) bool {
+		return streakBefore(streaks[i], streaks[j], sortBy)
+	})
+	return streaks, nil
+}
+
+// streakBefore reports whether habit a ranks before b in the streak ordering
+// given by sortBy, current streak first or longest streak first.
+func streakBefore(a, b domain.Habit, sortBy string) bool {
+	if sortBy == StreakSortLongest && a.LongestStreak != b.LongestStreak {
+		return a.LongestStreak > b.LongestStreak
+	}
+	return a.CurrentStreak > b.CurrentStreak
+}
+
+func (usecase *HabitUsecase) GetCompletions(userID, id uint) ([]domain.HabitCompletion, error) {
+	if _, err := usecase.GetHabitByID(userID, id); err != nil {
+		log.Println("Error fetching habit for completion history", err)
+		return nil, fmt.Errorf("habit not found")
+	}
+
+	completions, err := usecase.HabitRepo.GetCompletions(id)
+	if err != nil {
+		log.Printf("Error retrieving completions for habit with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to get habit completions")
+	}
+	return completions, nil
+}
+
+// LogRelapse records a slip on a quit habit, restarting its days clean count
+// unless the relapse is backdated before the latest one.
+func (usecase *HabitUsecase) LogRelapse(userID, id uint, input RelapseInput) error {
+	habit, err := usecase.editableHabit(userID, id)
+	if err != nil {
+		if err.Error() == "permission denied" {
+			return err
+		}
+		log.Println("Error fetching habit for relapse", err)
+		return fmt.Errorf("habit not found")
+	}
+
+	if !habit.IsQuit() {
+		return fmt.Errorf("relapses can only be logged for quit habits")
+	}
+
+	settings, err := loadSettings(usecase.SettingsRepo, habit.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to log relapse")
+	}
+
+	now := time.Now()
+	occurredAt, err := completionTime(CompletionInput{CompletedAt: input.OccurredAt, Date: input.Date}, habit, *settings, now)
+	if err != nil {
+		return err
+	}
+
+	relapses, err := usecase.HabitRepo.GetRelapses(id)
+	if err != nil {
+		log.Printf("Error retrieving relapses for habit with ID(%d): %v", id, err)
+		return fmt.Errorf("failed to log relapse")
+	}
+
+	relapse := &domain.Relapse{HabitID: habit.ID, OccurredAt: occurredAt, Note: input.Note}
+	engine := domain.NewStreakEngine(domain.HabitSchedule(*habit), *settings)
+	habit.StreakRuns = engine.CleanRuns(habit.CreatedAt, append(relapses, *relapse))
+	habit.LongestStreak = domain.LongestRun(habit.StreakRuns)
+
+	if habit.LastRelapseAt == nil || occurredAt.After(*habit.LastRelapseAt) {
+		habit.LastRelapseAt = &occurredAt
+	}
+	refreshDaysClean(habit, *settings, now)
+
+	if err := usecase.HabitRepo.AddRelapse(habit, relapse); err != nil {
+		log.Println("Error logging relapse:", err)
+		return fmt.Errorf("failed to log relapse")
+	}
+
+	log.Printf("Relapse logged for habit with ID(%d). Days clean: %d", id, habit.CurrentStreak)
+	return nil
+}
+
+func (usecase *HabitUsecase) GetRelapses(userID, id uint) ([]domain.Relapse, error) {
+	if _, err := usecase.GetHabitByID(userID, id); err != nil {
+		log.Println("Error fetching habit for relapse history", err)
+		return nil, fmt.Errorf("habit not found")
+	}
+
+	relapses, err := usecase.HabitRepo.GetRelapses(id)
+	if err != nil {
+		log.Printf("Error retrieving relapses for habit with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to get habit relapses")
+	}
+	return relapses, nil
+}
+
+// StartTimer starts a timer session for a timed habit, or resumes its paused session.
+func (usecase *HabitUsecase) StartTimer(userID, id uint) (*domain.TimerSession, error) {
+	habit, err := usecase.editableHabit(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !habit.IsTimed() {
+		return nil, fmt.Errorf("habit is not timed")
+	}
+
+	now := time.Now()
+	session, err := usecase.activeTimer(habit, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if session == nil || session.Status == domain.TimerStopped {
+		session = &domain.TimerSession{HabitID: habit.ID, StartedAt: now}
+	} else if session.Status == domain.TimerRunning {
+		return nil, fmt.Errorf("timer already running")
+	}
+	session.Resume(now)
+
+	if err := usecase.TimerRepo.Save(session); err != nil {
+		log.Printf("Error starting timer for habit with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to start timer")
+	}
+	return session, nil
+}
+
+// PauseTimer pauses the habit's running timer session.
+func (usecase *HabitUsecase) PauseTimer(userID, id uint) (*domain.TimerSession, error) {
+	habit, err := usecase.editableHabit(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session, err := usecase.activeTimer(habit, now)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.Status == domain.TimerPaused {
+		return nil, fmt.Errorf("no running timer")
+	}
+	// The session may have just completed the habit
+	if session.Status == domain.TimerStopped {
+		return session, nil
+	}
+
+	session.Pause(now)
+	if err := usecase.TimerRepo.Save(session); err != nil {
+		log.Printf("Error pausing timer for habit with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to pause timer")
+	}
+	return session, nil
+}
+
+// StopTimer stops the habit's timer session and records the time spent as a
+// completion, in minutes, through the same streak recalculation as MarkCompleted.
+func (usecase *HabitUsecase) StopTimer(userID, id uint) (*domain.TimerSession, error) {
+	habit, err := usecase.editableHabit(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session, err := usecase.activeTimer(habit, now)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, fmt.Errorf("no active timer")
+	}
+	if session.Status == domain.TimerStopped {
+		return session, nil
+	}
+
+	if err := usecase.completeTimer(habit, session, now); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetTimer returns the habit's running or paused timer session. It changes
+// nothing, so members who cannot edit the habit may call it: a running session
+// that has reached its target is returned stopped at that moment, and recorded
+// as a completion by the next start, pause or stop, or by the streak reset job.
+func (usecase *HabitUsecase) GetTimer(userID, id uint) (*domain.TimerSession, error) {
+	habit, err := usecase.GetHabitByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session, err := usecase.loadTimer(habit)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, fmt.Errorf("no active timer")
+	}
+
+	reachedAt, reached, err := usecase.timerTargetReachedAt(habit, session, now)
+	if err != nil {
+		return nil, err
+	}
+	if reached {
+		session.Stop(reachedAt)
+	}
+	return session, nil
+}
+
+// activeTimer returns the habit's running or paused session, or nil if there is
+// none. A running session that has reached the habit's target for the current
+// period is completed at the moment the target was reached and returned stopped.
+func (usecase *HabitUsecase) activeTimer(habit *domain.Habit, now time.Time) (*domain.TimerSession, error) {
+	session, err := usecase.loadTimer(habit)
+	if err != nil || session == nil {
+		return session, err
+	}
+
+	reachedAt, reached, err := usecase.timerTargetReachedAt(habit, session, now)
+	if err != nil || !reached {
+		return session, err
+	}
+	if err := usecase.completeTimer(habit, session, reachedAt); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// loadTimer returns the habit's running or paused session as stored, or nil if there is none.
+func (usecase *HabitUsecase) loadTimer(habit *domain.Habit) (*domain.TimerSession, error) {
+	session, err := usecase.TimerRepo.GetActive(habit.ID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		log.Printf("Error retrieving timer for habit with ID(%d): %v", habit.ID, err)
+		return nil, fmt.Errorf("failed to retrieve timer")
+	}
+	return session, nil
+}
+
+// timerTargetReachedAt reports whether the running session has reached the
+// habit's target for the current period by now, and when it did.
+func (usecase *HabitUsecase) timerTargetReachedAt(habit *domain.Habit, session *domain.TimerSession, now time.Time) (time.Time, bool, error) {
+	if session.Status != domain.TimerRunning {
+		return time.Time{}, false, nil
+	}
+
+	settings, err := loadSettings(usecase.SettingsRepo, habit.UserID)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to retrieve timer")
+	}
+
+	completions, err := usecase.HabitRepo.GetCompletions(habit.ID)
+	if err != nil {
+		log.Printf("Error fetching completion history for habit with ID(%d): %v", habit.ID, err)
+		return time.Time{}, false, fmt.Errorf("failed to retrieve timer")
+	}
+
+	engine, err := newStreakEngine(habit, *settings, usecase.SkipRepo)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to retrieve timer")
+	}
+
+	remaining := time.Duration((habit.TargetValue - engine.PeriodProgress(completions, now)) * float64(time.Minute))
+	elapsed := session.Elapsed(now)
+	if remaining <= 0 || elapsed < remaining {
+		return time.Time{}, false, nil
+	}
+
+	reachedAt := now.Add(remaining - elapsed)
+	if session.ResumedAt != nil && reachedAt.Before(*session.ResumedAt) {
+		reachedAt = *session.ResumedAt
+	}
+	return reachedAt, true, nil
+}
+
+// completeTimer stops the session at the given time and records its minutes as a completion.
+func (usecase *HabitUsecase) completeTimer(habit *domain.Habit, session *domain.TimerSession, at time.Time) error {
+	session.Stop(at)
+
+	// Nothing to record for a session stopped straight away
+	if session.ElapsedSeconds == 0 {
+		if err := usecase.TimerRepo.Save(session); err != nil {
+			log.Printf("Error stopping timer for habit with ID(%d): %v", habit.ID, err)
+			return fmt.Errorf("failed to stop timer")
+		}
+		return nil
+	}
+
+	settings, err := loadSettings(usecase.SettingsRepo, habit.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to stop timer")
+	}
+
+	completions, err := usecase.HabitRepo.GetCompletions(habit.ID)
+	if err != nil {
+		log.Printf("Error fetching completion history for habit with ID(%d): %v", habit.ID, err)
+		return fmt.Errorf("failed to stop timer")
+	}
+
+	engine, err := newStreakEngine(habit, *settings, usecase.SkipRepo)
+	if err != nil {
+		return fmt.Errorf("failed to stop timer")
+	}
+
+	completion := &domain.HabitCompletion{HabitID: habit.ID, CompletedAt: at, Quantity: session.Minutes()}
+	recalculateStreak(habit, append(completions, *completion), engine)
+	completion.Strength = completionStrength(habit, completion, engine)
+
+	if err := usecase.TimerRepo.Complete(session, habit, completion); err != nil {
+		log.Printf("Error recording timer completion for habit with ID(%d): %v", habit.ID, err)
+		return fmt.Errorf("failed to stop timer")
+	}
+
+	log.Printf("Timer for habit with ID(%d) stopped after %.1f minutes. Current streak: %d", habit.ID, session.Minutes(), habit.CurrentStreak)
+	return nil
 }
@@ -11,6 +11,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// testUserID is the authenticated user the usecases are called on behalf of
+const testUserID uint = 1
+
 func TestCreateHabit(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -26,6 +29,27 @@ func TestCreateHabit(t *testing.T) {
 				Frequency: "daily",
 			},
 			mockCreate: func(h *domain.Habit) error {
+				assert.Equal(t, testUserID, h.UserID)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "server-managed fields are ignored",
+			input: domain.Habit{
+				ID:            99,
+				UserID:        2,
+				Name:          "Read",
+				Frequency:     "daily",
+				CurrentStreak: 500,
+				LongestStreak: 500,
+				Strength:      100,
+				StreakFreezes: 10,
+				LastRelapseAt: &time.Time{},
+				CreatedAt:     time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			mockCreate: func(h *domain.Habit) error {
+				assert.Equal(t, domain.Habit{UserID: testUserID, Name: "Read", Frequency: "daily", Kind: "build"}, *h)
 				return nil
 			},
 			wantErr: false,
@@ -48,6 +72,29 @@ func TestCreateHabit(t *testing.T) {
 			wantErr:     true,
 			errContains: "invalid frequency type",
 		},
+		{
+			name: "valid flexible schedule",
+			input: domain.Habit{
+				Name:      "Gym",
+				Frequency: "weekly",
+				Weekdays:  "Fri,Mon,Wed",
+			},
+			mockCreate: func(h *domain.Habit) error {
+				assert.Equal(t, "mon,wed,fri", h.Weekdays)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid schedule",
+			input: domain.Habit{
+				Name:      "Gym",
+				Frequency: "daily",
+				MonthDays: "1,15",
+			},
+			wantErr:     true,
+			errContains: "month days can only be set on monthly habits",
+		},
 		{
 			name: "repo error",
 			input: domain.Habit{
@@ -69,7 +116,7 @@ func TestCreateHabit(t *testing.T) {
 			}
 			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
 
-			err := uc.CreateHabit(&tt.input)
+			err := uc.CreateHabit(testUserID, &tt.input)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -111,11 +158,14 @@ func TestGetAllHabits(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &usecase.MockHabitRepo{
-				GetAllFn: tt.mockGetAll,
+				GetAllForUserFn: func(userID uint) ([]domain.Habit, error) {
+					assert.Equal(t, testUserID, userID)
+					return tt.mockGetAll()
+				},
 			}
 
 			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
-			_, err := uc.GetAllHabits()
+			_, err := uc.GetAllHabits(testUserID)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -147,7 +197,7 @@ func TestGetHabitByID(t *testing.T) {
 				}, nil
 			},
 			wantErr:   false,
-			wantHabit: &domain.Habit{ID: 1, Name: "Meditate", Frequency: "daily"},
+			wantHabit: &domain.Habit{ID: 1, Name: "Meditate", Frequency: "daily", Role: domain.RoleOwner},
 		},
 		{
 			name:    "habit not found",
@@ -176,7 +226,7 @@ func TestGetHabitByID(t *testing.T) {
 			}
 			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
 
-			habit, err := uc.GetHabitByID(tt.inputID)
+			habit, err := uc.GetHabitByID(testUserID, tt.inputID)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -189,6 +239,186 @@ func TestGetHabitByID(t *testing.T) {
 	}
 }
 
+func TestSharedHabitPermissions(t *testing.T) {
+	roles := map[uint]domain.HabitRole{2: domain.RoleViewer, 3: domain.RoleCoOwner}
+	newUsecase := func() *usecase.HabitUsecase {
+		return &usecase.HabitUsecase{HabitRepo: &usecase.MockHabitRepo{GetByIDForMemberFn: memberLookup(roles)}}
+	}
+
+	tests := []struct {
+		name   string
+		userID uint
+		want   map[string]string // action to the error expected, if any
+	}{
+		{
+			name:   "viewer",
+			userID: 2,
+			want:   map[string]string{"view": "", "update": "permission denied", "complete": "permission denied", "delete": "permission denied"},
+		},
+		{
+			name:   "co-owner",
+			userID: 3,
+			want:   map[string]string{"view": "", "update": "", "complete": "", "delete": "permission denied"},
+		},
+		{
+			name:   "stranger",
+			userID: 4,
+			want:   map[string]string{"view": "habit not found", "update": "failed to retrieve existing habit", "complete": "habit not found", "delete": "habit not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newUsecase()
+			errs := map[string]error{}
+			_, errs["view"] = uc.GetHabitByID(tt.userID, 1)
+			errs["update"] = uc.UpdateHabit(tt.userID, &domain.Habit{ID: 1, Name: "Run", Frequency: "daily"})
+			errs["complete"] = uc.MarkCompleted(tt.userID, 1, usecase.CompletionInput{})
+			errs["delete"] = uc.DeleteHabit(tt.userID, 1)
+
+			for action, want := range tt.want {
+				if want == "" {
+					assert.NoError(t, errs[action], action)
+				} else {
+					assert.ErrorContains(t, errs[action], want, action)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAllHabitsIncludesShared(t *testing.T) {
+	var loaded []uint
+	uc := &usecase.HabitUsecase{
+		HabitRepo: &usecase.MockHabitRepo{
+			GetAllForUserFn: func(userID uint) ([]domain.Habit, error) {
+				return []domain.Habit{{ID: 1, UserID: testUserID, Name: "Run", CurrentStreak: 1}, {ID: 3, UserID: testUserID, Name: "Swim"}}, nil
+			},
+			GetAllSharedWithUserFn: func(userID uint) ([]domain.Habit, error) {
+				return []domain.Habit{{ID: 2, UserID: 2, Name: "Read", CurrentStreak: 4, Role: domain.RoleViewer}}, nil
+			},
+		},
+		SettingsRepo: &usecase.MockSettingsRepo{
+			GetFn: func(userID uint) (*domain.Settings, error) {
+				loaded = append(loaded, userID)
+				return nil, gorm.ErrRecordNotFound
+			},
+		},
+	}
+
+	habits, err := uc.GetAllHabits(testUserID)
+	assert.NoError(t, err)
+	assert.Len(t, habits, 3)
+	assert.Equal(t, domain.RoleViewer, habits[0].Role)
+	assert.Equal(t, domain.RoleOwner, habits[1].Role)
+	// Each habit uses its owner's settings, loaded once per owner
+	assert.Equal(t, []uint{testUserID, 2}, loaded)
+}
+
+func TestGetHabitDetails(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name               string
+		mockGetByID        func(uint) (*domain.Habit, error)
+		mockGetCompletions func(uint) ([]domain.HabitCompletion, error)
+		occurrences        int
+		wantErr            bool
+		errContains        string
+		wantDueNow         bool
+		wantNoDue          bool
+		wantOccurrences    int
+		wantStrength       float64
+	}{
+		{
+			name: "not yet completed this period",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Name: "Meditate", Frequency: "daily"}, nil
+			},
+			wantErr:    false,
+			wantDueNow: true,
+		},
+		{
+			name: "completed this period",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Name: "Meditate", Frequency: "daily"}, nil
+			},
+			mockGetCompletions: func(id uint) ([]domain.HabitCompletion, error) {
+				return []domain.HabitCompletion{{HabitID: id, CompletedAt: now}}, nil
+			},
+			wantErr:      false,
+			wantDueNow:   false,
+			wantStrength: 5.2,
+		},
+		{
+			name: "next occurrences of a recurrence rule",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Name: "Gym", Frequency: "weekly", RRule: "FREQ=WEEKLY;BYDAY=MO,WE,FR", CreatedAt: now.AddDate(0, 0, -14)}, nil
+			},
+			occurrences:     3,
+			wantErr:         false,
+			wantDueNow:      true,
+			wantOccurrences: 3,
+		},
+		{
+			name: "recurrence rule has ended",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Name: "Course", Frequency: "daily", RRule: "FREQ=DAILY;COUNT=3", CreatedAt: now.AddDate(0, 0, -14)}, nil
+			},
+			occurrences:     3,
+			wantErr:         false,
+			wantNoDue:       true,
+			wantOccurrences: 0,
+		},
+		{
+			name: "habit not found",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			wantErr:     true,
+			errContains: "habit not found",
+		},
+		{
+			name: "completion history error",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Name: "Meditate", Frequency: "daily"}, nil
+			},
+			mockGetCompletions: func(id uint) ([]domain.HabitCompletion, error) {
+				return nil, errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to retrieve habit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &usecase.MockHabitRepo{
+				GetByIDFn:        tt.mockGetByID,
+				GetCompletionsFn: tt.mockGetCompletions,
+			}
+			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
+
+			habit, err := uc.GetHabitDetails(testUserID, 1, tt.occurrences)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, habit.NextOccurrences, tt.wantOccurrences)
+				assert.Equal(t, tt.wantStrength, habit.Strength)
+				if tt.wantNoDue {
+					assert.Nil(t, habit.NextDueAt)
+					return
+				}
+				assert.NotNil(t, habit.NextDueAt)
+				assert.Equal(t, tt.wantDueNow, !habit.NextDueAt.After(now))
+			}
+		})
+	}
+}
+
 func TestUpdateHabit(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -236,6 +466,28 @@ func TestUpdateHabit(t *testing.T) {
 			wantErr:     true,
 			errContains: "invalid frequency type",
 		},
+		{
+			name:       "schedule change recalculates streak",
+			inputHabit: domain.Habit{ID: 1, Name: "Habit", Frequency: "weekly", TimesPerPeriod: 5},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Name: "Old", Frequency: "weekly", CurrentStreak: 4}, nil
+			},
+			mockUpdate: func(h *domain.Habit) error {
+				assert.Equal(t, 5, h.TimesPerPeriod)
+				assert.Equal(t, 0, h.CurrentStreak)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:       "invalid schedule",
+			inputHabit: domain.Habit{ID: 1, Name: "Habit", Frequency: "weekly", IntervalDays: 3},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Name: "Old", Frequency: "daily"}, nil
+			},
+			wantErr:     true,
+			errContains: "interval days can only be set on daily habits",
+		},
 		{
 			name:       "repository update error",
 			inputHabit: domain.Habit{ID: 1, Name: "Habit", Frequency: "daily"},
@@ -258,7 +510,7 @@ func TestUpdateHabit(t *testing.T) {
 			}
 			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
 
-			err := uc.UpdateHabit(&tt.inputHabit)
+			err := uc.UpdateHabit(testUserID, &tt.inputHabit)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -321,7 +573,7 @@ func TestDeleteHabit(t *testing.T) {
 			}
 			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
 
-			err := uc.DeleteHabit(tt.habitID)
+			err := uc.DeleteHabit(testUserID, tt.habitID)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -334,16 +586,21 @@ func TestDeleteHabit(t *testing.T) {
 }
 
 func TestMarkCompleted(t *testing.T) {
-	now := time.Now()
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+	tomorrow := now.Add(24 * time.Hour)
 
 	tests := []struct {
-		name           string
-		habitID        uint
-		mockGetByID    func(uint) (*domain.Habit, error)
-		mockSafeUpdate func(*domain.Habit) error
-		wantErr        bool
-		errContains    string
-		expectStreak   int
+		name               string
+		habitID            uint
+		input              usecase.CompletionInput
+		mockSettings       func(uint) (*domain.Settings, error)
+		mockGetByID        func(uint) (*domain.Habit, error)
+		mockGetCompletions func(uint) ([]domain.HabitCompletion, error)
+		mockAddCompletion  func(*domain.Habit, *domain.HabitCompletion) error
+		wantErr            bool
+		errContains        string
 	}{
 		{
 			name:    "first time completion",
@@ -351,26 +608,82 @@ func TestMarkCompleted(t *testing.T) {
 			mockGetByID: func(id uint) (*domain.Habit, error) {
 				return &domain.Habit{ID: id, Frequency: "daily", LastCompletedAt: nil}, nil
 			},
-			mockSafeUpdate: func(h *domain.Habit) error {
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
 				assert.Equal(t, 1, h.CurrentStreak)
+				assert.Equal(t, 1, h.TotalCompletions)
+				assert.Equal(t, h.ID, c.HabitID)
+				assert.Equal(t, *h.LastCompletedAt, c.CompletedAt)
+				// The streak history is saved with the completion, even when empty
+				assert.NotNil(t, h.StreakRuns)
 				return nil
 			},
-			wantErr:      false,
-			expectStreak: 1,
+			wantErr: false,
 		},
 		{
 			name:    "daily habit continued",
 			habitID: 2,
 			mockGetByID: func(id uint) (*domain.Habit, error) {
-				yesterday := now.Add(-23 * time.Hour)
 				return &domain.Habit{ID: id, Frequency: "daily", LastCompletedAt: &yesterday, CurrentStreak: 3}, nil
 			},
-			mockSafeUpdate: func(h *domain.Habit) error {
+			mockGetCompletions: func(id uint) ([]domain.HabitCompletion, error) {
+				return []domain.HabitCompletion{
+					{HabitID: id, CompletedAt: today.AddDate(0, 0, -3)},
+					{HabitID: id, CompletedAt: today.AddDate(0, 0, -2)},
+					{HabitID: id, CompletedAt: yesterday},
+				}, nil
+			},
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
 				assert.Equal(t, 4, h.CurrentStreak)
+				assert.Equal(t, 4, h.TotalCompletions)
+				assert.Equal(t, 19.2, h.Strength)
+				assert.Equal(t, h.Strength, c.Strength)
 				return nil
 			},
-			wantErr:      false,
-			expectStreak: 4,
+			wantErr: false,
+		},
+		{
+			name:    "daily habit missed day bridged by a streak freeze",
+			habitID: 9,
+			mockSettings: func(uint) (*domain.Settings, error) {
+				settings := domain.DefaultSettings()
+				settings.FreezeEarnInterval = 2
+				return &settings, nil
+			},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", LastCompletedAt: &yesterday, CurrentStreak: 1}, nil
+			},
+			mockGetCompletions: func(id uint) ([]domain.HabitCompletion, error) {
+				return []domain.HabitCompletion{
+					{HabitID: id, CompletedAt: today.AddDate(0, 0, -4)},
+					{HabitID: id, CompletedAt: today.AddDate(0, 0, -3)},
+					{HabitID: id, CompletedAt: yesterday},
+				}, nil
+			},
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
+				assert.Equal(t, 4, h.CurrentStreak)
+				assert.Equal(t, 1, h.StreakFreezes)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:    "daily habit completed twice on the same day",
+			habitID: 8,
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", CurrentStreak: 2}, nil
+			},
+			mockGetCompletions: func(id uint) ([]domain.HabitCompletion, error) {
+				return []domain.HabitCompletion{
+					{HabitID: id, CompletedAt: yesterday},
+					{HabitID: id, CompletedAt: now},
+				}, nil
+			},
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
+				assert.Equal(t, 2, h.CurrentStreak)
+				assert.Equal(t, 3, h.TotalCompletions)
+				return nil
+			},
+			wantErr: false,
 		},
 		{
 			name:    "weekly habit missed",
@@ -379,30 +692,191 @@ func TestMarkCompleted(t *testing.T) {
 				twoWeeksAgo := now.Add(-15 * 24 * time.Hour)
 				return &domain.Habit{ID: id, Frequency: "weekly", LastCompletedAt: &twoWeeksAgo, CurrentStreak: 7}, nil
 			},
-			mockSafeUpdate: func(h *domain.Habit) error {
+			mockGetCompletions: func(id uint) ([]domain.HabitCompletion, error) {
+				return []domain.HabitCompletion{
+					{HabitID: id, CompletedAt: now.Add(-15 * 24 * time.Hour)},
+				}, nil
+			},
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
 				assert.Equal(t, 1, h.CurrentStreak)
 				return nil
 			},
-			wantErr:      false,
-			expectStreak: 1,
+			wantErr: false,
 		},
 		{
-			name:    "habit not found",
+			name:    "backdated completion fills gap",
 			habitID: 4,
+			input:   usecase.CompletionInput{CompletedAt: &yesterday},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			mockGetCompletions: func(id uint) ([]domain.HabitCompletion, error) {
+				return []domain.HabitCompletion{
+					{HabitID: id, CompletedAt: today.AddDate(0, 0, -2)},
+					{HabitID: id, CompletedAt: now},
+				}, nil
+			},
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
+				assert.Equal(t, yesterday, c.CompletedAt)
+				assert.Equal(t, 3, h.CurrentStreak)
+				assert.Equal(t, now, *h.LastCompletedAt)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:    "completion in the future",
+			habitID: 5,
+			input:   usecase.CompletionInput{CompletedAt: &tomorrow},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			wantErr:     true,
+			errContains: "completion time cannot be in the future",
+		},
+		{
+			name:    "completion before the habit was created",
+			habitID: 5,
+			input:   usecase.CompletionInput{Date: "0001-01-01"},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", CreatedAt: yesterday}, nil
+			},
+			wantErr:     true,
+			errContains: "completion time cannot be before the habit was created",
+		},
+		{
+			name:    "backdated completion on the day the habit was created",
+			habitID: 5,
+			input:   usecase.CompletionInput{Date: yesterday.Format("2006-01-02")},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", CreatedAt: yesterday.Add(time.Hour)}, nil
+			},
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
+				assert.Equal(t, yesterday, c.CompletedAt)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:    "backdated completion by date",
+			habitID: 9,
+			input:   usecase.CompletionInput{Date: yesterday.Format("2006-01-02")},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
+				assert.Equal(t, yesterday, c.CompletedAt)
+				assert.Equal(t, 1, h.CurrentStreak)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:    "backdated completion by date in user timezone",
+			habitID: 10,
+			input:   usecase.CompletionInput{Date: "2024-03-10"},
+			mockSettings: func(uint) (*domain.Settings, error) {
+				return &domain.Settings{Timezone: "Asia/Tokyo", WeekStart: time.Monday}, nil
+			},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
+				// Midday in Tokyo is 03:00 UTC
+				assert.Equal(t, time.Date(2024, time.March, 10, 3, 0, 0, 0, time.UTC), c.CompletedAt.UTC())
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:    "measurable habit reaches target",
+			habitID: 12,
+			input:   usecase.CompletionInput{Quantity: 3},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", TargetValue: 8, Unit: "glasses"}, nil
+			},
+			mockGetCompletions: func(id uint) ([]domain.HabitCompletion, error) {
+				return []domain.HabitCompletion{
+					{HabitID: id, CompletedAt: yesterday, Quantity: 8},
+					{HabitID: id, CompletedAt: now, Quantity: 5},
+				}, nil
+			},
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
+				assert.Equal(t, 3.0, c.Quantity)
+				assert.Equal(t, 2, h.CurrentStreak)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:    "measurable habit below target",
+			habitID: 13,
+			input:   usecase.CompletionInput{Quantity: 2},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", TargetValue: 8, Unit: "glasses"}, nil
+			},
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
+				assert.Equal(t, 0, h.CurrentStreak)
+				assert.Equal(t, 1, h.TotalCompletions)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:    "measurable habit without quantity",
+			habitID: 14,
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", TargetValue: 8}, nil
+			},
+			wantErr:     true,
+			errContains: "quantity is required for measurable habits",
+		},
+		{
+			name:    "quit habit",
+			habitID: 16,
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", Kind: "quit"}, nil
+			},
+			wantErr:     true,
+			errContains: "log a relapse instead",
+		},
+		{
+			name:    "negative quantity",
+			habitID: 15,
+			input:   usecase.CompletionInput{Quantity: -1},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			wantErr:     true,
+			errContains: "quantity must be positive",
+		},
+		{
+			name:    "invalid completion date",
+			habitID: 11,
+			input:   usecase.CompletionInput{Date: "10/03/2024"},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			wantErr:     true,
+			errContains: "invalid completion date",
+		},
+		{
+			name:    "habit not found",
+			habitID: 6,
 			mockGetByID: func(id uint) (*domain.Habit, error) {
 				return nil, errors.New("habit not found")
 			},
-			mockSafeUpdate: nil,
-			wantErr:        true,
-			errContains:    "habit not found",
+			mockAddCompletion: nil,
+			wantErr:           true,
+			errContains:       "habit not found",
 		},
 		{
 			name:    "update fails",
-			habitID: 5,
+			habitID: 7,
 			mockGetByID: func(id uint) (*domain.Habit, error) {
 				return &domain.Habit{ID: id, Frequency: "daily"}, nil
 			},
-			mockSafeUpdate: func(h *domain.Habit) error {
+			mockAddCompletion: func(h *domain.Habit, c *domain.HabitCompletion) error {
 				return errors.New("db error")
 			},
 			wantErr:     true,
@@ -413,12 +887,16 @@ func TestMarkCompleted(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &usecase.MockHabitRepo{
-				GetByIDFn:    tt.mockGetByID,
-				SafeUpdateFn: tt.mockSafeUpdate,
+				GetByIDFn:        tt.mockGetByID,
+				GetCompletionsFn: tt.mockGetCompletions,
+				AddCompletionFn:  tt.mockAddCompletion,
+			}
+			uc := &usecase.HabitUsecase{
+				HabitRepo:    mockRepo,
+				SettingsRepo: &usecase.MockSettingsRepo{GetFn: tt.mockSettings},
 			}
-			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
 
-			err := uc.MarkCompleted(tt.habitID)
+			err := uc.MarkCompleted(testUserID, tt.habitID, tt.input)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -430,13 +908,353 @@ func TestMarkCompleted(t *testing.T) {
 	}
 }
 
-func TestGetStreaks(t *testing.T) {
+func TestMarkCompletedBulk(t *testing.T) {
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+
+	habits := map[uint]*domain.Habit{
+		1: {ID: 1, Frequency: "daily"},
+		2: {ID: 2, Frequency: "daily", TargetValue: 8},
+		3: {ID: 3, Frequency: "daily", Kind: "quit"},
+	}
+	getByID := func(id uint) (*domain.Habit, error) {
+		habit, ok := habits[id]
+		if !ok {
+			return nil, gorm.ErrRecordNotFound
+		}
+		copied := *habit
+		return &copied, nil
+	}
+
 	tests := []struct {
-		name           string
-		mockGetStreaks func() ([]domain.Habit, error)
+		name               string
+		inputs             []usecase.BulkCompletionInput
+		mockAddCompletions func([]*domain.Habit, []*domain.HabitCompletion) error
+		want               []usecase.BulkCompletionResult
+		wantErr            bool
+		errContains        string
+	}{
+		{
+			name: "all completions recorded",
+			inputs: []usecase.BulkCompletionInput{
+				{HabitID: 1, CompletionInput: usecase.CompletionInput{CompletedAt: &yesterday}},
+				{HabitID: 1},
+				{HabitID: 2, CompletionInput: usecase.CompletionInput{Quantity: 8}},
+			},
+			mockAddCompletions: func(h []*domain.Habit, c []*domain.HabitCompletion) error {
+				assert.Len(t, h, 2)
+				assert.Len(t, c, 3)
+				assert.Equal(t, 2, h[0].TotalCompletions)
+				assert.Equal(t, 8.0, c[2].Quantity)
+				return nil
+			},
+			want: []usecase.BulkCompletionResult{
+				{HabitID: 1, Completed: true, CurrentStreak: 2},
+				{HabitID: 1, Completed: true, CurrentStreak: 2},
+				{HabitID: 2, Completed: true, CurrentStreak: 1},
+			},
+		},
+		{
+			name: "bad items are reported without failing the batch",
+			inputs: []usecase.BulkCompletionInput{
+				{HabitID: 1},
+				{HabitID: 9},
+				{HabitID: 2},
+				{HabitID: 3},
+				{HabitID: 1, CompletionInput: usecase.CompletionInput{Date: "10/03/2024"}},
+			},
+			mockAddCompletions: func(h []*domain.Habit, c []*domain.HabitCompletion) error {
+				assert.Len(t, c, 1)
+				return nil
+			},
+			want: []usecase.BulkCompletionResult{
+				{HabitID: 1, Completed: true, CurrentStreak: 1},
+				{HabitID: 9, Error: "habit not found"},
+				{HabitID: 2, Error: "quantity is required for measurable habits"},
+				{HabitID: 3, Error: "quit habits cannot be completed, log a relapse instead"},
+				{HabitID: 1, Error: "invalid completion date"},
+			},
+		},
+		{
+			name:   "nothing to record",
+			inputs: []usecase.BulkCompletionInput{{HabitID: 9}},
+			mockAddCompletions: func(h []*domain.Habit, c []*domain.HabitCompletion) error {
+				t.Error("nothing should be stored")
+				return nil
+			},
+			want: []usecase.BulkCompletionResult{{HabitID: 9, Error: "habit not found"}},
+		},
+		{
+			name:        "empty batch",
+			wantErr:     true,
+			errContains: "no completions given",
+		},
+		{
+			name:        "batch too large",
+			inputs:      make([]usecase.BulkCompletionInput, 101),
+			wantErr:     true,
+			errContains: "too many completions",
+		},
+		{
+			name:   "transaction fails",
+			inputs: []usecase.BulkCompletionInput{{HabitID: 1}},
+			mockAddCompletions: func(h []*domain.Habit, c []*domain.HabitCompletion) error {
+				return errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to mark habits as complete",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &usecase.MockHabitRepo{
+				GetByIDFn:        getByID,
+				AddCompletionsFn: tt.mockAddCompletions,
+			}
+			uc := &usecase.HabitUsecase{HabitRepo: mockRepo, SettingsRepo: &usecase.MockSettingsRepo{}}
+
+			results, err := uc.MarkCompletedBulk(testUserID, tt.inputs)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, results)
+			}
+		})
+	}
+}
+
+func TestRemoveCompletion(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+
+	history := func(id uint) ([]domain.HabitCompletion, error) {
+		return []domain.HabitCompletion{
+			{HabitID: id, CompletedAt: today.AddDate(0, 0, -2)},
+			{HabitID: id, CompletedAt: today.AddDate(0, 0, -1)},
+			{HabitID: id, CompletedAt: today},
+		}, nil
+	}
+
+	tests := []struct {
+		name                  string
+		habitID               uint
+		date                  string
+		mockGetByID           func(uint) (*domain.Habit, error)
+		mockGetCompletions    func(uint) ([]domain.HabitCompletion, error)
+		mockRemoveCompletions func(*domain.Habit, time.Time, time.Time) error
+		wantErr               bool
+		errContains           string
+	}{
+		{
+			name:    "undo latest completion",
+			habitID: 1,
+			date:    today.Format("2006-01-02"),
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", CurrentStreak: 3, TotalCompletions: 3}, nil
+			},
+			mockGetCompletions: history,
+			mockRemoveCompletions: func(h *domain.Habit, from, to time.Time) error {
+				assert.Equal(t, 2, h.CurrentStreak)
+				assert.Equal(t, 2, h.TotalCompletions)
+				assert.Equal(t, today.AddDate(0, 0, -1), *h.LastCompletedAt)
+				assert.Equal(t, 24*time.Hour, to.Sub(from))
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:    "undo breaks streak",
+			habitID: 2,
+			date:    today.AddDate(0, 0, -1).Format("2006-01-02"),
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", CurrentStreak: 3, TotalCompletions: 3}, nil
+			},
+			mockGetCompletions: history,
+			mockRemoveCompletions: func(h *domain.Habit, from, to time.Time) error {
+				assert.Equal(t, 1, h.CurrentStreak)
+				assert.Equal(t, 2, h.TotalCompletions)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:    "no completion on date",
+			habitID: 3,
+			date:    today.AddDate(0, 0, -5).Format("2006-01-02"),
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			mockGetCompletions: history,
+			wantErr:            true,
+			errContains:        "completion not found",
+		},
+		{
+			name:    "invalid date",
+			habitID: 6,
+			date:    "yesterday",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			wantErr:     true,
+			errContains: "invalid completion date",
+		},
+		{
+			name:    "habit not found",
+			habitID: 4,
+			date:    today.Format("2006-01-02"),
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			wantErr:     true,
+			errContains: "habit not found",
+		},
+		{
+			name:    "repository error",
+			habitID: 5,
+			date:    today.Format("2006-01-02"),
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			mockGetCompletions: history,
+			mockRemoveCompletions: func(h *domain.Habit, from, to time.Time) error {
+				return errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to remove completion",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &usecase.MockHabitRepo{
+				GetByIDFn:           tt.mockGetByID,
+				GetCompletionsFn:    tt.mockGetCompletions,
+				RemoveCompletionsFn: tt.mockRemoveCompletions,
+			}
+			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
+
+			err := uc.RemoveCompletion(testUserID, tt.habitID, tt.date)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetToday(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+	created := today.AddDate(0, 0, -10)
+
+	habits := []domain.Habit{
+		{ID: 1, Name: "Read", Frequency: "daily", CreatedAt: created},
+		{ID: 2, Name: "Walk", Frequency: "daily", CreatedAt: created},
+		{ID: 3, Name: "Stretch", Frequency: "daily", CreatedAt: created},
+		{ID: 4, Name: "No sugar", Frequency: "daily", Kind: "quit", CreatedAt: created},
+	}
+	completions := map[uint][]domain.HabitCompletion{
+		1: {{HabitID: 1, CompletedAt: today.AddDate(0, 0, -1)}, {HabitID: 1, CompletedAt: now}},
+		2: {{HabitID: 2, CompletedAt: today.AddDate(0, 0, -1)}},
+		3: {{HabitID: 3, CompletedAt: today.AddDate(0, 0, -3)}},
+	}
+
+	tests := []struct {
+		name          string
+		date          string
+		wantErr       bool
+		errContains   string
+		wantDue       []uint
+		wantOverdue   []uint
+		wantCompleted []uint
+	}{
+		{
+			name:          "today",
+			wantErr:       false,
+			wantDue:       []uint{2},
+			wantOverdue:   []uint{3},
+			wantCompleted: []uint{1},
+		},
+		{
+			name:          "yesterday",
+			date:          today.AddDate(0, 0, -1).Format("2006-01-02"),
+			wantErr:       false,
+			wantOverdue:   []uint{3},
+			wantCompleted: []uint{1, 2},
+		},
+		{
+			name:    "tomorrow is not overdue yet",
+			date:    today.AddDate(0, 0, 1).Format("2006-01-02"),
+			wantErr: false,
+			wantDue: []uint{1, 2, 3},
+		},
+		{
+			name:        "invalid date",
+			date:        "tomorrow",
+			wantErr:     true,
+			errContains: "invalid date",
+		},
+	}
+
+	ids := func(habits []domain.Habit) []uint {
+		result := []uint{}
+		for _, habit := range habits {
+			result = append(result, habit.ID)
+		}
+		return result
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &usecase.MockHabitRepo{
+				GetAllFn: func() ([]domain.Habit, error) {
+					return append([]domain.Habit{}, habits...), nil
+				},
+				GetCompletionsFn: func(id uint) ([]domain.HabitCompletion, error) {
+					return completions[id], nil
+				},
+			}
+			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
+
+			result, err := uc.GetToday(testUserID, tt.date)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.ElementsMatch(t, tt.wantDue, ids(result.Due))
+				assert.ElementsMatch(t, tt.wantOverdue, ids(result.Overdue))
+				assert.ElementsMatch(t, tt.wantCompleted, ids(result.Completed))
+				assert.Empty(t, result.Skipped)
+				for _, habit := range result.Due {
+					assert.NotNil(t, habit.NextDueAt)
+				}
+			}
+		})
+	}
+}
+
+func TestGetStreaks(t *testing.T) {
+	now := time.Now()
+	tenDaysAgo := now.AddDate(0, 0, -10)
+
+	tests := []struct {
+		name           string
+		sortBy         string
+		mockGetStreaks func() ([]domain.Habit, error)
+		mockGetAll     func() ([]domain.Habit, error)
 		wantErr        bool
 		errContains    string
 		expectedCount  int
+		expectedOrder  []uint
 	}{
 		{
 			name: "success - habits returned",
@@ -449,6 +1267,39 @@ func TestGetStreaks(t *testing.T) {
 			wantErr:       false,
 			expectedCount: 2,
 		},
+		{
+			name: "quit habits ranked by days clean",
+			mockGetStreaks: func() ([]domain.Habit, error) {
+				return []domain.Habit{
+					{ID: 1, Name: "Workout", Kind: "build", CurrentStreak: 3},
+					{ID: 2, Name: "No sugar", Kind: "quit", CreatedAt: now.AddDate(0, 0, -30), LastRelapseAt: &tenDaysAgo},
+					{ID: 3, Name: "No smoking", Kind: "quit", CreatedAt: now},
+				}, nil
+			},
+			wantErr:       false,
+			expectedCount: 2,
+			expectedOrder: []uint{2, 1},
+		},
+		{
+			name:   "sorted by longest streak",
+			sortBy: usecase.StreakSortLongest,
+			mockGetAll: func() ([]domain.Habit, error) {
+				return []domain.Habit{
+					{ID: 1, Name: "Workout", CurrentStreak: 3, LongestStreak: 5},
+					{ID: 2, Name: "Read", CurrentStreak: 0, LongestStreak: 12},
+					{ID: 3, Name: "Stretch"},
+				}, nil
+			},
+			wantErr:       false,
+			expectedCount: 2,
+			expectedOrder: []uint{2, 1},
+		},
+		{
+			name:        "invalid sort",
+			sortBy:      "name",
+			wantErr:     true,
+			errContains: "invalid streak sort",
+		},
 		{
 			name: "repository error",
 			mockGetStreaks: func() ([]domain.Habit, error) {
@@ -463,10 +1314,11 @@ func TestGetStreaks(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &usecase.MockHabitRepo{
 				GetStreaksFn: tt.mockGetStreaks,
+				GetAllFn:     tt.mockGetAll,
 			}
 			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
 
-			habits, err := uc.GetStreaks()
+			habits, err := uc.GetStreaks(testUserID, tt.sortBy)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -474,6 +1326,479 @@ func TestGetStreaks(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.Len(t, habits, tt.expectedCount)
+				for i, id := range tt.expectedOrder {
+					assert.Equal(t, id, habits[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestGetCompletions(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name               string
+		habitID            uint
+		mockGetByID        func(uint) (*domain.Habit, error)
+		mockGetCompletions func(uint) ([]domain.HabitCompletion, error)
+		wantErr            bool
+		errContains        string
+		expectedCount      int
+	}{
+		{
+			name:    "completions returned",
+			habitID: 1,
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			mockGetCompletions: func(id uint) ([]domain.HabitCompletion, error) {
+				return []domain.HabitCompletion{
+					{ID: 1, HabitID: id, CompletedAt: now.Add(-24 * time.Hour)},
+					{ID: 2, HabitID: id, CompletedAt: now},
+				}, nil
+			},
+			wantErr:       false,
+			expectedCount: 2,
+		},
+		{
+			name:    "habit not found",
+			habitID: 2,
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			wantErr:     true,
+			errContains: "habit not found",
+		},
+		{
+			name:    "repository error",
+			habitID: 3,
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			mockGetCompletions: func(id uint) ([]domain.HabitCompletion, error) {
+				return nil, errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to get habit completions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &usecase.MockHabitRepo{
+				GetByIDFn:        tt.mockGetByID,
+				GetCompletionsFn: tt.mockGetCompletions,
+			}
+			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
+
+			completions, err := uc.GetCompletions(testUserID, tt.habitID)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, completions, tt.expectedCount)
+			}
+		})
+	}
+}
+
+func TestLogRelapse(t *testing.T) {
+	now := time.Now()
+	lastWeek := now.AddDate(0, 0, -7)
+	twoWeeksAgo := now.AddDate(0, 0, -14)
+
+	tests := []struct {
+		name            string
+		input           usecase.RelapseInput
+		mockGetByID     func(uint) (*domain.Habit, error)
+		mockAddRelapse  func(*domain.Habit, *domain.Relapse) error
+		mockGetRelapses func(uint) ([]domain.Relapse, error)
+		wantErr         bool
+		errContains     string
+		wantDaysClean   int
+		wantLastRelapse *time.Time
+	}{
+		{
+			name:  "relapse resets days clean",
+			input: usecase.RelapseInput{Note: "birthday cake"},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", Kind: "quit", CreatedAt: now.AddDate(0, 0, -30), LastRelapseAt: &lastWeek}, nil
+			},
+			mockGetRelapses: func(id uint) ([]domain.Relapse, error) {
+				return []domain.Relapse{{HabitID: id, OccurredAt: lastWeek}}, nil
+			},
+			mockAddRelapse: func(h *domain.Habit, r *domain.Relapse) error {
+				assert.Equal(t, "birthday cake", r.Note)
+				assert.Equal(t, 23, h.LongestStreak)
+				assert.Len(t, h.StreakRuns, 2)
+				return nil
+			},
+			wantErr:       false,
+			wantDaysClean: 0,
+		},
+		{
+			name:  "backdated relapse before the latest one",
+			input: usecase.RelapseInput{OccurredAt: &twoWeeksAgo},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", Kind: "quit", CreatedAt: now.AddDate(0, 0, -30), LastRelapseAt: &lastWeek}, nil
+			},
+			wantErr:         false,
+			wantDaysClean:   7,
+			wantLastRelapse: &lastWeek,
+		},
+		{
+			name: "not a quit habit",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", Kind: "build"}, nil
+			},
+			wantErr:     true,
+			errContains: "relapses can only be logged for quit habits",
+		},
+		{
+			name: "habit not found",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			wantErr:     true,
+			errContains: "habit not found",
+		},
+		{
+			name: "repository error",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", Kind: "quit"}, nil
+			},
+			mockAddRelapse: func(h *domain.Habit, r *domain.Relapse) error {
+				return errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to log relapse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *domain.Habit
+			mockRepo := &usecase.MockHabitRepo{
+				GetByIDFn:     tt.mockGetByID,
+				GetRelapsesFn: tt.mockGetRelapses,
+				AddRelapseFn: func(h *domain.Habit, r *domain.Relapse) error {
+					saved = h
+					if tt.mockAddRelapse != nil {
+						return tt.mockAddRelapse(h, r)
+					}
+					return nil
+				},
+			}
+			uc := &usecase.HabitUsecase{HabitRepo: mockRepo}
+
+			err := uc.LogRelapse(testUserID, 1, tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantDaysClean, saved.CurrentStreak)
+				if tt.wantLastRelapse != nil {
+					assert.Equal(t, *tt.wantLastRelapse, *saved.LastRelapseAt)
+				}
+			}
+		})
+	}
+}
+
+func TestStartTimer(t *testing.T) {
+	now := time.Now()
+	pausedAt := now.Add(-time.Hour)
+	meditate := func(id uint) (*domain.Habit, error) {
+		return &domain.Habit{ID: id, Name: "Meditate", Frequency: "daily", TargetValue: 20, Unit: "minutes"}, nil
+	}
+
+	tests := []struct {
+		name          string
+		mockGetByID   func(uint) (*domain.Habit, error)
+		mockGetActive func(uint) (*domain.TimerSession, error)
+		mockSave      func(*domain.TimerSession) error
+		wantErr       bool
+		errContains   string
+		wantElapsed   int64
+	}{
+		{
+			name:          "start new session",
+			mockGetByID:   meditate,
+			mockGetActive: func(id uint) (*domain.TimerSession, error) { return nil, gorm.ErrRecordNotFound },
+			wantErr:       false,
+		},
+		{
+			name:        "resume paused session",
+			mockGetByID: meditate,
+			mockGetActive: func(id uint) (*domain.TimerSession, error) {
+				return &domain.TimerSession{ID: 4, HabitID: id, Status: domain.TimerPaused, StartedAt: pausedAt, ElapsedSeconds: 300}, nil
+			},
+			wantErr:     false,
+			wantElapsed: 300,
+		},
+		{
+			name:        "already running",
+			mockGetByID: meditate,
+			mockGetActive: func(id uint) (*domain.TimerSession, error) {
+				return &domain.TimerSession{ID: 4, HabitID: id, Status: domain.TimerRunning, StartedAt: now, ResumedAt: &now}, nil
+			},
+			wantErr:     true,
+			errContains: "timer already running",
+		},
+		{
+			name: "habit is not timed",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Name: "Drink water", Frequency: "daily", TargetValue: 8, Unit: "glasses"}, nil
+			},
+			wantErr:     true,
+			errContains: "habit is not timed",
+		},
+		{
+			name:          "save fails",
+			mockGetByID:   meditate,
+			mockGetActive: func(id uint) (*domain.TimerSession, error) { return nil, gorm.ErrRecordNotFound },
+			mockSave:      func(s *domain.TimerSession) error { return errors.New("db error") },
+			wantErr:       true,
+			errContains:   "failed to start timer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &usecase.HabitUsecase{
+				HabitRepo: &usecase.MockHabitRepo{GetByIDFn: tt.mockGetByID},
+				TimerRepo: &usecase.MockTimerRepo{GetActiveFn: tt.mockGetActive, SaveFn: tt.mockSave},
+			}
+
+			session, err := uc.StartTimer(testUserID, 1)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, domain.TimerRunning, session.Status)
+				assert.NotNil(t, session.ResumedAt)
+				assert.Equal(t, tt.wantElapsed, session.ElapsedSeconds)
+			}
+		})
+	}
+}
+
+func TestPauseTimer(t *testing.T) {
+	now := time.Now()
+	resumedAt := now.Add(-5 * time.Minute)
+
+	tests := []struct {
+		name          string
+		mockGetActive func(uint) (*domain.TimerSession, error)
+		mockComplete  func(*domain.TimerSession, *domain.Habit, *domain.HabitCompletion) error
+		wantErr       bool
+		errContains   string
+		wantStatus    domain.TimerStatus
+	}{
+		{
+			name: "pause running session",
+			mockGetActive: func(id uint) (*domain.TimerSession, error) {
+				return &domain.TimerSession{HabitID: id, Status: domain.TimerRunning, StartedAt: resumedAt, ResumedAt: &resumedAt, ElapsedSeconds: 60}, nil
+			},
+			wantErr:    false,
+			wantStatus: domain.TimerPaused,
+		},
+		{
+			name: "target reached before pausing",
+			mockGetActive: func(id uint) (*domain.TimerSession, error) {
+				return &domain.TimerSession{HabitID: id, Status: domain.TimerRunning, StartedAt: resumedAt, ResumedAt: &resumedAt, ElapsedSeconds: 1140}, nil
+			},
+			mockComplete: func(s *domain.TimerSession, h *domain.Habit, c *domain.HabitCompletion) error {
+				assert.Equal(t, 20.0, c.Quantity)
+				assert.Equal(t, 1, h.CurrentStreak)
+				return nil
+			},
+			wantErr:    false,
+			wantStatus: domain.TimerStopped,
+		},
+		{
+			name:          "no running session",
+			mockGetActive: func(id uint) (*domain.TimerSession, error) { return nil, gorm.ErrRecordNotFound },
+			wantErr:       true,
+			errContains:   "no running timer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &usecase.HabitUsecase{
+				HabitRepo: &usecase.MockHabitRepo{GetByIDFn: func(id uint) (*domain.Habit, error) {
+					return &domain.Habit{ID: id, Name: "Meditate", Frequency: "daily", TargetValue: 20, Unit: "minutes"}, nil
+				}},
+				TimerRepo: &usecase.MockTimerRepo{GetActiveFn: tt.mockGetActive, CompleteFn: tt.mockComplete},
+			}
+
+			session, err := uc.PauseTimer(testUserID, 1)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantStatus, session.Status)
+				assert.Nil(t, session.ResumedAt)
+			}
+		})
+	}
+}
+
+func TestStopTimer(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name               string
+		mockGetActive      func(uint) (*domain.TimerSession, error)
+		mockGetCompletions func(uint) ([]domain.HabitCompletion, error)
+		mockComplete       func(*domain.TimerSession, *domain.Habit, *domain.HabitCompletion) error
+		wantErr            bool
+		errContains        string
+	}{
+		{
+			name: "stop paused session below target",
+			mockGetActive: func(id uint) (*domain.TimerSession, error) {
+				return &domain.TimerSession{HabitID: id, Status: domain.TimerPaused, StartedAt: now, ElapsedSeconds: 600}, nil
+			},
+			mockComplete: func(s *domain.TimerSession, h *domain.Habit, c *domain.HabitCompletion) error {
+				assert.Equal(t, 10.0, c.Quantity)
+				assert.Equal(t, 0, h.CurrentStreak)
+				assert.Equal(t, 1, h.TotalCompletions)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "stop session that tops up the period",
+			mockGetActive: func(id uint) (*domain.TimerSession, error) {
+				return &domain.TimerSession{HabitID: id, Status: domain.TimerPaused, StartedAt: now, ElapsedSeconds: 600}, nil
+			},
+			mockGetCompletions: func(id uint) ([]domain.HabitCompletion, error) {
+				return []domain.HabitCompletion{{HabitID: id, CompletedAt: now, Quantity: 10}}, nil
+			},
+			mockComplete: func(s *domain.TimerSession, h *domain.Habit, c *domain.HabitCompletion) error {
+				assert.Equal(t, 1, h.CurrentStreak)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:          "no active session",
+			mockGetActive: func(id uint) (*domain.TimerSession, error) { return nil, gorm.ErrRecordNotFound },
+			wantErr:       true,
+			errContains:   "no active timer",
+		},
+		{
+			name: "recording completion fails",
+			mockGetActive: func(id uint) (*domain.TimerSession, error) {
+				return &domain.TimerSession{HabitID: id, Status: domain.TimerPaused, StartedAt: now, ElapsedSeconds: 600}, nil
+			},
+			mockComplete: func(s *domain.TimerSession, h *domain.Habit, c *domain.HabitCompletion) error {
+				return errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to stop timer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &usecase.HabitUsecase{
+				HabitRepo: &usecase.MockHabitRepo{
+					GetByIDFn: func(id uint) (*domain.Habit, error) {
+						return &domain.Habit{ID: id, Name: "Meditate", Frequency: "daily", TargetValue: 20, Unit: "minutes"}, nil
+					},
+					GetCompletionsFn: tt.mockGetCompletions,
+				},
+				TimerRepo: &usecase.MockTimerRepo{GetActiveFn: tt.mockGetActive, CompleteFn: tt.mockComplete},
+			}
+
+			session, err := uc.StopTimer(testUserID, 1)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, domain.TimerStopped, session.Status)
+				assert.NotNil(t, session.StoppedAt)
+			}
+		})
+	}
+}
+
+func TestGetTimer(t *testing.T) {
+	now := time.Now()
+	resumedAt := now.Add(-5 * time.Minute)
+
+	tests := []struct {
+		name          string
+		mockGetActive func(uint) (*domain.TimerSession, error)
+		wantErr       bool
+		errContains   string
+		wantStatus    domain.TimerStatus
+	}{
+		{
+			name: "running session below target",
+			mockGetActive: func(id uint) (*domain.TimerSession, error) {
+				return &domain.TimerSession{HabitID: id, Status: domain.TimerRunning, StartedAt: resumedAt, ResumedAt: &resumedAt, ElapsedSeconds: 60}, nil
+			},
+			wantErr:    false,
+			wantStatus: domain.TimerRunning,
+		},
+		{
+			name: "target reached is shown without recording it",
+			mockGetActive: func(id uint) (*domain.TimerSession, error) {
+				return &domain.TimerSession{HabitID: id, Status: domain.TimerRunning, StartedAt: resumedAt, ResumedAt: &resumedAt, ElapsedSeconds: 1140}, nil
+			},
+			wantErr:    false,
+			wantStatus: domain.TimerStopped,
+		},
+		{
+			name:          "no active session",
+			mockGetActive: func(id uint) (*domain.TimerSession, error) { return nil, gorm.ErrRecordNotFound },
+			wantErr:       true,
+			errContains:   "no active timer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &usecase.HabitUsecase{
+				HabitRepo: &usecase.MockHabitRepo{GetByIDForMemberFn: func(id, userID uint) (*domain.Habit, domain.HabitRole, error) {
+					return &domain.Habit{ID: id, UserID: 2, Name: "Meditate", Frequency: "daily", TargetValue: 20, Unit: "minutes"}, domain.RoleViewer, nil
+				}},
+				TimerRepo: &usecase.MockTimerRepo{
+					GetActiveFn: tt.mockGetActive,
+					SaveFn: func(s *domain.TimerSession) error {
+						t.Error("viewing the timer must not save it")
+						return nil
+					},
+					CompleteFn: func(s *domain.TimerSession, h *domain.Habit, c *domain.HabitCompletion) error {
+						t.Error("viewing the timer must not record a completion")
+						return nil
+					},
+				},
+			}
+
+			session, err := uc.GetTimer(testUserID, 1)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantStatus, session.Status)
 			}
 		})
 	}
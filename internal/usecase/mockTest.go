@@ -1,6 +1,9 @@
 package usecase
 
 import (
+	"context"
+	"time"
+
 	"github.com/jt00721/habit-tracker/internal/domain"
 )
 
@@ -13,6 +16,25 @@ type MockHabitRepo struct {
 	DeleteFn     func(uint) error
 	SafeUpdateFn func(*domain.Habit) error
 	GetStreaksFn func() ([]domain.Habit, error)
+
+	// The ForUser lookups fall back to their unscoped counterparts when unset,
+	// owner filtering is covered by the repository tests. GetByIDForMember
+	// falls back to GetByIDForUser with the user as owner.
+	GetByIDForUserFn       func(uint, uint) (*domain.Habit, error)
+	GetByIDForMemberFn     func(uint, uint) (*domain.Habit, domain.HabitRole, error)
+	GetAllForUserFn        func(uint) ([]domain.Habit, error)
+	GetAllSharedWithUserFn func(uint) ([]domain.Habit, error)
+	GetStreaksForUserFn    func(uint) ([]domain.Habit, error)
+
+	AddCompletionFn     func(*domain.Habit, *domain.HabitCompletion) error
+	AddCompletionsFn    func([]*domain.Habit, []*domain.HabitCompletion) error
+	GetCompletionsFn    func(uint) ([]domain.HabitCompletion, error)
+	GetCompletionFn     func(uint) (*domain.HabitCompletion, error)
+	RemoveCompletionsFn func(*domain.Habit, time.Time, time.Time) error
+
+	AddRelapseFn    func(*domain.Habit, *domain.Relapse) error
+	GetRelapsesFn   func(uint) ([]domain.Relapse, error)
+	GetStreakRunsFn func(uint) ([]domain.StreakRun, error)
 }
 
 // Implement each method to call the corresponding function if set
@@ -38,6 +60,42 @@ func (m *MockHabitRepo) GetByID(id uint) (*domain.Habit, error) {
 	return nil, nil
 }
 
+func (m *MockHabitRepo) GetByIDForUser(id, userID uint) (*domain.Habit, error) {
+	if m.GetByIDForUserFn != nil {
+		return m.GetByIDForUserFn(id, userID)
+	}
+	return m.GetByID(id)
+}
+
+func (m *MockHabitRepo) GetByIDForMember(id, userID uint) (*domain.Habit, domain.HabitRole, error) {
+	if m.GetByIDForMemberFn != nil {
+		return m.GetByIDForMemberFn(id, userID)
+	}
+	habit, err := m.GetByIDForUser(id, userID)
+	return habit, domain.RoleOwner, err
+}
+
+func (m *MockHabitRepo) GetAllSharedWithUser(userID uint) ([]domain.Habit, error) {
+	if m.GetAllSharedWithUserFn != nil {
+		return m.GetAllSharedWithUserFn(userID)
+	}
+	return nil, nil
+}
+
+func (m *MockHabitRepo) GetAllForUser(userID uint) ([]domain.Habit, error) {
+	if m.GetAllForUserFn != nil {
+		return m.GetAllForUserFn(userID)
+	}
+	return m.GetAll()
+}
+
+func (m *MockHabitRepo) GetStreaksForUser(userID uint) ([]domain.Habit, error) {
+	if m.GetStreaksForUserFn != nil {
+		return m.GetStreaksForUserFn(userID)
+	}
+	return m.GetStreaks()
+}
+
 func (m *MockHabitRepo) Update(h *domain.Habit) error {
 	if m.UpdateFn != nil {
 		return m.UpdateFn(h)
@@ -65,3 +123,602 @@ func (m *MockHabitRepo) GetStreaks() ([]domain.Habit, error) {
 	}
 	return nil, nil
 }
+
+func (m *MockHabitRepo) AddCompletions(habits []*domain.Habit, completions []*domain.HabitCompletion) error {
+	if m.AddCompletionsFn != nil {
+		return m.AddCompletionsFn(habits, completions)
+	}
+	return nil
+}
+
+func (m *MockHabitRepo) AddCompletion(h *domain.Habit, c *domain.HabitCompletion) error {
+	if m.AddCompletionFn != nil {
+		return m.AddCompletionFn(h, c)
+	}
+	return nil
+}
+
+func (m *MockHabitRepo) GetCompletions(habitID uint) ([]domain.HabitCompletion, error) {
+	if m.GetCompletionsFn != nil {
+		return m.GetCompletionsFn(habitID)
+	}
+	return nil, nil
+}
+
+func (m *MockHabitRepo) GetCompletion(id uint) (*domain.HabitCompletion, error) {
+	if m.GetCompletionFn != nil {
+		return m.GetCompletionFn(id)
+	}
+	return nil, nil
+}
+
+func (m *MockHabitRepo) RemoveCompletions(h *domain.Habit, from, to time.Time) error {
+	if m.RemoveCompletionsFn != nil {
+		return m.RemoveCompletionsFn(h, from, to)
+	}
+	return nil
+}
+
+func (m *MockHabitRepo) AddRelapse(h *domain.Habit, r *domain.Relapse) error {
+	if m.AddRelapseFn != nil {
+		return m.AddRelapseFn(h, r)
+	}
+	return nil
+}
+
+func (m *MockHabitRepo) GetRelapses(habitID uint) ([]domain.Relapse, error) {
+	if m.GetRelapsesFn != nil {
+		return m.GetRelapsesFn(habitID)
+	}
+	return nil, nil
+}
+
+func (m *MockHabitRepo) GetStreakRuns(habitID uint) ([]domain.StreakRun, error) {
+	if m.GetStreakRunsFn != nil {
+		return m.GetStreakRunsFn(habitID)
+	}
+	return nil, nil
+}
+
+// MockSettingsRepo satisfies the SettingsRepository interface
+type MockSettingsRepo struct {
+	GetFn  func(uint) (*domain.Settings, error)
+	SaveFn func(*domain.Settings) error
+}
+
+func (m *MockSettingsRepo) Get(userID uint) (*domain.Settings, error) {
+	if m.GetFn != nil {
+		return m.GetFn(userID)
+	}
+	return nil, nil
+}
+
+func (m *MockSettingsRepo) Save(s *domain.Settings) error {
+	if m.SaveFn != nil {
+		return m.SaveFn(s)
+	}
+	return nil
+}
+
+// MockTimerRepo satisfies the TimerSessionRepository interface
+type MockTimerRepo struct {
+	GetActiveFn  func(uint) (*domain.TimerSession, error)
+	GetRunningFn func() ([]domain.TimerSession, error)
+	SaveFn       func(*domain.TimerSession) error
+	CompleteFn   func(*domain.TimerSession, *domain.Habit, *domain.HabitCompletion) error
+}
+
+func (m *MockTimerRepo) GetActive(habitID uint) (*domain.TimerSession, error) {
+	if m.GetActiveFn != nil {
+		return m.GetActiveFn(habitID)
+	}
+	return nil, nil
+}
+
+func (m *MockTimerRepo) GetRunning() ([]domain.TimerSession, error) {
+	if m.GetRunningFn != nil {
+		return m.GetRunningFn()
+	}
+	return nil, nil
+}
+
+func (m *MockTimerRepo) Save(s *domain.TimerSession) error {
+	if m.SaveFn != nil {
+		return m.SaveFn(s)
+	}
+	return nil
+}
+
+func (m *MockTimerRepo) Complete(s *domain.TimerSession, h *domain.Habit, c *domain.HabitCompletion) error {
+	if m.CompleteFn != nil {
+		return m.CompleteFn(s, h, c)
+	}
+	return nil
+}
+
+// MockSkipRepo satisfies the SkipRepository interface
+type MockSkipRepo struct {
+	CreateFn      func(*domain.Skip) error
+	GetByIDFn     func(uint) (*domain.Skip, error)
+	GetForUserFn  func(uint) ([]domain.Skip, error)
+	GetForHabitFn func(uint, uint) ([]domain.Skip, error)
+	DeleteFn      func(uint) error
+}
+
+func (m *MockSkipRepo) Create(s *domain.Skip) error {
+	if m.CreateFn != nil {
+		return m.CreateFn(s)
+	}
+	return nil
+}
+
+func (m *MockSkipRepo) GetByID(id uint) (*domain.Skip, error) {
+	if m.GetByIDFn != nil {
+		return m.GetByIDFn(id)
+	}
+	return nil, nil
+}
+
+func (m *MockSkipRepo) GetForUser(userID uint) ([]domain.Skip, error) {
+	if m.GetForUserFn != nil {
+		return m.GetForUserFn(userID)
+	}
+	return nil, nil
+}
+
+func (m *MockSkipRepo) GetForHabit(habitID, ownerID uint) ([]domain.Skip, error) {
+	if m.GetForHabitFn != nil {
+		return m.GetForHabitFn(habitID, ownerID)
+	}
+	return nil, nil
+}
+
+func (m *MockSkipRepo) Delete(id uint) error {
+	if m.DeleteFn != nil {
+		return m.DeleteFn(id)
+	}
+	return nil
+}
+
+// MockJobLock satisfies the JobLock interface
+type MockJobLock struct {
+	TryRunFn func(int64, func() error) (bool, error)
+}
+
+func (m *MockJobLock) TryRun(key int64, fn func() error) (bool, error) {
+	if m.TryRunFn != nil {
+		return m.TryRunFn(key, fn)
+	}
+	return true, fn()
+}
+
+// MockUserRepo satisfies the UserRepository interface
+type MockUserRepo struct {
+	CreateFn     func(*domain.User) error
+	GetByIDFn    func(uint) (*domain.User, error)
+	GetByEmailFn func(string) (*domain.User, error)
+
+	GetByIdentityFn      func(string, string) (*domain.User, error)
+	CreateWithIdentityFn func(*domain.User, *domain.UserIdentity) error
+	AddIdentityFn        func(*domain.UserIdentity) error
+	PromoteFn            func(uint) error
+}
+
+func (m *MockUserRepo) Create(u *domain.User) error {
+	if m.CreateFn != nil {
+		return m.CreateFn(u)
+	}
+	return nil
+}
+
+func (m *MockUserRepo) GetByID(id uint) (*domain.User, error) {
+	if m.GetByIDFn != nil {
+		return m.GetByIDFn(id)
+	}
+	return nil, nil
+}
+
+func (m *MockUserRepo) GetByEmail(email string) (*domain.User, error) {
+	if m.GetByEmailFn != nil {
+		return m.GetByEmailFn(email)
+	}
+	return nil, nil
+}
+
+func (m *MockUserRepo) GetByIdentity(issuer, subject string) (*domain.User, error) {
+	if m.GetByIdentityFn != nil {
+		return m.GetByIdentityFn(issuer, subject)
+	}
+	return nil, nil
+}
+
+func (m *MockUserRepo) CreateWithIdentity(u *domain.User, identity *domain.UserIdentity) error {
+	if m.CreateWithIdentityFn != nil {
+		return m.CreateWithIdentityFn(u, identity)
+	}
+	return nil
+}
+
+func (m *MockUserRepo) AddIdentity(identity *domain.UserIdentity) error {
+	if m.AddIdentityFn != nil {
+		return m.AddIdentityFn(identity)
+	}
+	return nil
+}
+
+func (m *MockUserRepo) Promote(id uint) error {
+	if m.PromoteFn != nil {
+		return m.PromoteFn(id)
+	}
+	return nil
+}
+
+// MockSessionRepo satisfies the SessionRepository interface
+type MockSessionRepo struct {
+	CreateFn         func(*domain.Session) error
+	GetByTokenHashFn func(string) (*domain.Session, error)
+	DeleteFn         func(uint) error
+	DeleteForUserFn  func(uint, uint) error
+	DeleteExpiredFn  func(time.Time) error
+}
+
+func (m *MockSessionRepo) Create(s *domain.Session) error {
+	if m.CreateFn != nil {
+		return m.CreateFn(s)
+	}
+	return nil
+}
+
+func (m *MockSessionRepo) GetByTokenHash(hash string) (*domain.Session, error) {
+	if m.GetByTokenHashFn != nil {
+		return m.GetByTokenHashFn(hash)
+	}
+	return nil, nil
+}
+
+func (m *MockSessionRepo) Delete(id uint) error {
+	if m.DeleteFn != nil {
+		return m.DeleteFn(id)
+	}
+	return nil
+}
+
+func (m *MockSessionRepo) DeleteForUser(userID, exceptID uint) error {
+	if m.DeleteForUserFn != nil {
+		return m.DeleteForUserFn(userID, exceptID)
+	}
+	return nil
+}
+
+func (m *MockSessionRepo) DeleteExpired(before time.Time) error {
+	if m.DeleteExpiredFn != nil {
+		return m.DeleteExpiredFn(before)
+	}
+	return nil
+}
+
+// MockAccessTokenRepo satisfies the AccessTokenRepository interface
+type MockAccessTokenRepo struct {
+	CreateFn         func(*domain.AccessToken) error
+	GetByIDFn        func(uint) (*domain.AccessToken, error)
+	GetByTokenHashFn func(string) (*domain.AccessToken, error)
+	GetForUserFn     func(uint) ([]domain.AccessToken, error)
+	UpdateLastUsedFn func(uint, time.Time) error
+	DeleteFn         func(uint) error
+}
+
+func (m *MockAccessTokenRepo) Create(t *domain.AccessToken) error {
+	if m.CreateFn != nil {
+		return m.CreateFn(t)
+	}
+	return nil
+}
+
+func (m *MockAccessTokenRepo) GetByID(id uint) (*domain.AccessToken, error) {
+	if m.GetByIDFn != nil {
+		return m.GetByIDFn(id)
+	}
+	return nil, nil
+}
+
+func (m *MockAccessTokenRepo) GetByTokenHash(hash string) (*domain.AccessToken, error) {
+	if m.GetByTokenHashFn != nil {
+		return m.GetByTokenHashFn(hash)
+	}
+	return nil, nil
+}
+
+func (m *MockAccessTokenRepo) GetForUser(userID uint) ([]domain.AccessToken, error) {
+	if m.GetForUserFn != nil {
+		return m.GetForUserFn(userID)
+	}
+	return nil, nil
+}
+
+func (m *MockAccessTokenRepo) UpdateLastUsed(id uint, at time.Time) error {
+	if m.UpdateLastUsedFn != nil {
+		return m.UpdateLastUsedFn(id, at)
+	}
+	return nil
+}
+
+func (m *MockAccessTokenRepo) Delete(id uint) error {
+	if m.DeleteFn != nil {
+		return m.DeleteFn(id)
+	}
+	return nil
+}
+
+// MockIdentityProvider satisfies the IdentityProvider interface
+type MockIdentityProvider struct {
+	AuthCodeURLFn func(state, nonce, codeChallenge string) (string, error)
+	ExchangeFn    func(code, codeVerifier, nonce string) (*domain.ExternalIdentity, error)
+}
+
+func (m *MockIdentityProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	if m.AuthCodeURLFn != nil {
+		return m.AuthCodeURLFn(state, nonce, codeChallenge)
+	}
+	return "", nil
+}
+
+func (m *MockIdentityProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*domain.ExternalIdentity, error) {
+	if m.ExchangeFn != nil {
+		return m.ExchangeFn(code, codeVerifier, nonce)
+	}
+	return nil, nil
+}
+
+// MockTwoFactorRepo satisfies the TwoFactorRepository interface
+type MockTwoFactorRepo struct {
+	SetPendingSecretFn        func(uint, string) error
+	EnableFn                  func(uint, int64, []domain.RecoveryCode) error
+	ResetFn                   func(uint) error
+	UseCounterFn              func(uint, int64) (bool, error)
+	UseRecoveryCodeFn         func(uint, string) (bool, error)
+	CreateChallengeFn         func(*domain.LoginChallenge) error
+	GetChallengeByTokenHashFn func(string) (*domain.LoginChallenge, error)
+	AddChallengeAttemptFn     func(uint) error
+	DeleteChallengeFn         func(uint) error
+	DeleteExpiredChallengesFn func(time.Time) error
+}
+
+func (m *MockTwoFactorRepo) SetPendingSecret(userID uint, secret string) error {
+	if m.SetPendingSecretFn != nil {
+		return m.SetPendingSecretFn(userID, secret)
+	}
+	return nil
+}
+
+func (m *MockTwoFactorRepo) Enable(userID uint, counter int64, codes []domain.RecoveryCode) error {
+	if m.EnableFn != nil {
+		return m.EnableFn(userID, counter, codes)
+	}
+	return nil
+}
+
+func (m *MockTwoFactorRepo) Reset(userID uint) error {
+	if m.ResetFn != nil {
+		return m.ResetFn(userID)
+	}
+	return nil
+}
+
+func (m *MockTwoFactorRepo) UseCounter(userID uint, counter int64) (bool, error) {
+	if m.UseCounterFn != nil {
+		return m.UseCounterFn(userID, counter)
+	}
+	return false, nil
+}
+
+func (m *MockTwoFactorRepo) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	if m.UseRecoveryCodeFn != nil {
+		return m.UseRecoveryCodeFn(userID, codeHash)
+	}
+	return false, nil
+}
+
+func (m *MockTwoFactorRepo) CreateChallenge(c *domain.LoginChallenge) error {
+	if m.CreateChallengeFn != nil {
+		return m.CreateChallengeFn(c)
+	}
+	return nil
+}
+
+func (m *MockTwoFactorRepo) GetChallengeByTokenHash(hash string) (*domain.LoginChallenge, error) {
+	if m.GetChallengeByTokenHashFn != nil {
+		return m.GetChallengeByTokenHashFn(hash)
+	}
+	return nil, nil
+}
+
+func (m *MockTwoFactorRepo) AddChallengeAttempt(id uint) error {
+	if m.AddChallengeAttemptFn != nil {
+		return m.AddChallengeAttemptFn(id)
+	}
+	return nil
+}
+
+func (m *MockTwoFactorRepo) DeleteChallenge(id uint) error {
+	if m.DeleteChallengeFn != nil {
+		return m.DeleteChallengeFn(id)
+	}
+	return nil
+}
+
+func (m *MockTwoFactorRepo) DeleteExpiredChallenges(before time.Time) error {
+	if m.DeleteExpiredChallengesFn != nil {
+		return m.DeleteExpiredChallengesFn(before)
+	}
+	return nil
+}
+
+// MockSharingRepo satisfies the SharingRepository interface
+type MockSharingRepo struct {
+	CreateMemberFn     func(*domain.HabitMember) error
+	GetMemberFn        func(uint, uint) (*domain.HabitMember, error)
+	GetMembersFn       func(uint) ([]domain.HabitMember, error)
+	UpdateMemberRoleFn func(uint, uint, domain.HabitRole) error
+	DeleteMemberFn     func(uint, uint) error
+
+	AddReactionFn    func(*domain.CompletionReaction) error
+	RemoveReactionFn func(uint, uint, string) error
+	GetReactionsFn   func(uint) ([]domain.CompletionReaction, error)
+	AddCommentFn     func(*domain.CompletionComment) error
+	GetCommentFn     func(uint) (*domain.CompletionComment, error)
+	GetCommentsFn    func(uint) ([]domain.CompletionComment, error)
+	DeleteCommentFn  func(uint) error
+}
+
+func (m *MockSharingRepo) CreateMember(member *domain.HabitMember) error {
+	if m.CreateMemberFn != nil {
+		return m.CreateMemberFn(member)
+	}
+	return nil
+}
+
+func (m *MockSharingRepo) GetMember(habitID, userID uint) (*domain.HabitMember, error) {
+	if m.GetMemberFn != nil {
+		return m.GetMemberFn(habitID, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockSharingRepo) GetMembers(habitID uint) ([]domain.HabitMember, error) {
+	if m.GetMembersFn != nil {
+		return m.GetMembersFn(habitID)
+	}
+	return nil, nil
+}
+
+func (m *MockSharingRepo) UpdateMemberRole(habitID, userID uint, role domain.HabitRole) error {
+	if m.UpdateMemberRoleFn != nil {
+		return m.UpdateMemberRoleFn(habitID, userID, role)
+	}
+	return nil
+}
+
+func (m *MockSharingRepo) DeleteMember(habitID, userID uint) error {
+	if m.DeleteMemberFn != nil {
+		return m.DeleteMemberFn(habitID, userID)
+	}
+	return nil
+}
+
+func (m *MockSharingRepo) AddReaction(reaction *domain.CompletionReaction) error {
+	if m.AddReactionFn != nil {
+		return m.AddReactionFn(reaction)
+	}
+	return nil
+}
+
+func (m *MockSharingRepo) RemoveReaction(completionID, userID uint, emoji string) error {
+	if m.RemoveReactionFn != nil {
+		return m.RemoveReactionFn(completionID, userID, emoji)
+	}
+	return nil
+}
+
+func (m *MockSharingRepo) GetReactions(completionID uint) ([]domain.CompletionReaction, error) {
+	if m.GetReactionsFn != nil {
+		return m.GetReactionsFn(completionID)
+	}
+	return nil, nil
+}
+
+func (m *MockSharingRepo) AddComment(comment *domain.CompletionComment) error {
+	if m.AddCommentFn != nil {
+		return m.AddCommentFn(comment)
+	}
+	return nil
+}
+
+func (m *MockSharingRepo) GetComment(id uint) (*domain.CompletionComment, error) {
+	if m.GetCommentFn != nil {
+		return m.GetCommentFn(id)
+	}
+	return nil, nil
+}
+
+func (m *MockSharingRepo) GetComments(completionID uint) ([]domain.CompletionComment, error) {
+	if m.GetCommentsFn != nil {
+		return m.GetCommentsFn(completionID)
+	}
+	return nil, nil
+}
+
+func (m *MockSharingRepo) DeleteComment(id uint) error {
+	if m.DeleteCommentFn != nil {
+		return m.DeleteCommentFn(id)
+	}
+	return nil
+}
+
+// MockChallengeRepo satisfies the ChallengeRepository interface
+type MockChallengeRepo struct {
+	CreateFn  func(*domain.Challenge) error
+	GetAllFn  func() ([]domain.Challenge, error)
+	GetByIDFn func(uint) (*domain.Challenge, error)
+	DeleteFn  func(uint) error
+
+	JoinFn            func(*domain.ChallengeParticipant, *domain.Habit) error
+	LeaveFn           func(uint, uint) error
+	GetParticipantFn  func(uint, uint) (*domain.ChallengeParticipant, error)
+	GetParticipantsFn func(uint) ([]domain.ChallengeParticipant, error)
+}
+
+func (m *MockChallengeRepo) Create(c *domain.Challenge) error {
+	if m.CreateFn != nil {
+		return m.CreateFn(c)
+	}
+	return nil
+}
+
+func (m *MockChallengeRepo) GetAll() ([]domain.Challenge, error) {
+	if m.GetAllFn != nil {
+		return m.GetAllFn()
+	}
+	return nil, nil
+}
+
+func (m *MockChallengeRepo) GetByID(id uint) (*domain.Challenge, error) {
+	if m.GetByIDFn != nil {
+		return m.GetByIDFn(id)
+	}
+	return nil, nil
+}
+
+func (m *MockChallengeRepo) Delete(id uint) error {
+	if m.DeleteFn != nil {
+		return m.DeleteFn(id)
+	}
+	return nil
+}
+
+func (m *MockChallengeRepo) Join(p *domain.ChallengeParticipant, habit *domain.Habit) error {
+	if m.JoinFn != nil {
+		return m.JoinFn(p, habit)
+	}
+	return nil
+}
+
+func (m *MockChallengeRepo) Leave(challengeID, userID uint) error {
+	if m.LeaveFn != nil {
+		return m.LeaveFn(challengeID, userID)
+	}
+	return nil
+}
+
+func (m *MockChallengeRepo) GetParticipant(challengeID, userID uint) (*domain.ChallengeParticipant, error) {
+	if m.GetParticipantFn != nil {
+		return m.GetParticipantFn(challengeID, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockChallengeRepo) GetParticipants(challengeID uint) ([]domain.ChallengeParticipant, error) {
+	if m.GetParticipantsFn != nil {
+		return m.GetParticipantsFn(challengeID)
+	}
+	return nil, nil
+}
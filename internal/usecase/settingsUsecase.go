@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+type SettingsUsecase struct {
+	SettingsRepo domain.SettingsRepository
+	HabitRepo    domain.HabitRepository
+	SkipRepo     domain.SkipRepository
+}
+
+// SettingsInput changes the settings, keeping the current value of any field
+// left out. It has no json tags so clients can keep sending the field names of
+// domain.Settings, matched case-insensitively.
+type SettingsInput struct {
+	Timezone           *string
+	WeekStart          *time.Weekday
+	DayEndHour         *int
+	FreezeEarnInterval *int
+	MaxStreakFreezes   *int
+}
+
+func (usecase *SettingsUsecase) GetSettings(userID uint) (*domain.Settings, error) {
+	return loadSettings(usecase.SettingsRepo, userID)
+}
+
+// UpdateSettings saves the changes to the user's settings. A new timezone, week
+// start or day end hour moves the periods completions fall in, so the streaks of
+// the user's habits are recalculated first.
+func (usecase *SettingsUsecase) UpdateSettings(userID uint, input SettingsInput) (*domain.Settings, error) {
+	existingSettings, err := loadSettings(usecase.SettingsRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve existing settings")
+	}
+
+	settings := *existingSettings
+	if input.Timezone != nil {
+		settings.Timezone = *input.Timezone
+	}
+	if input.WeekStart != nil {
+		settings.WeekStart = *input.WeekStart
+	}
+	if input.DayEndHour != nil {
+		settings.DayEndHour = *input.DayEndHour
+	}
+	if input.FreezeEarnInterval != nil {
+		settings.FreezeEarnInterval = *input.FreezeEarnInterval
+	}
+	if input.MaxStreakFreezes != nil {
+		settings.MaxStreakFreezes = *input.MaxStreakFreezes
+	}
+
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	if settings.Timezone != existingSettings.Timezone || settings.WeekStart != existingSettings.WeekStart ||
+		settings.DayEndHour != existingSettings.DayEndHour {
+		if err := usecase.recalculateStreaks(userID, settings); err != nil {
+			return nil, fmt.Errorf("failed to update settings")
+		}
+	}
+
+	if err := usecase.SettingsRepo.Save(&settings); err != nil {
+		log.Println("Error saving settings:", err)
+		return nil, fmt.Errorf("failed to update settings")
+	}
+
+	log.Printf("Settings updated successfully for user with ID(%d). Timezone: %s", userID, settings.Timezone)
+	return &settings, nil
+}
+
+// recalculateStreaks replays the history of the user's habits under the settings
+// and saves their streaks.
+func (usecase *SettingsUsecase) recalculateStreaks(userID uint, settings domain.Settings) error {
+	habits, err := usecase.HabitRepo.GetAllForUser(userID)
+	if err != nil {
+		log.Printf("Error retrieving habits of user with ID(%d): %v", userID, err)
+		return err
+	}
+
+	now := time.Now()
+	for i := range habits {
+		habit := &habits[i]
+		if habit.IsQuit() {
+			relapses, err := usecase.HabitRepo.GetRelapses(habit.ID)
+			if err != nil {
+				log.Printf("Error retrieving relapses for habit with ID(%d): %v", habit.ID, err)
+				return err
+			}
+			engine := domain.NewStreakEngine(domain.HabitSchedule(*habit), settings)
+			habit.StreakRuns = engine.CleanRuns(habit.CreatedAt, relapses)
+			habit.LongestStreak = domain.LongestRun(habit.StreakRuns)
+			refreshDaysClean(habit, settings, now)
+		} else {
+			completions, err := usecase.HabitRepo.GetCompletions(habit.ID)
+			if err != nil {
+				log.Printf("Error fetching completion history for habit with ID(%d): %v", habit.ID, err)
+				return err
+			}
+			engine, err := newStreakEngine(habit, settings, usecase.SkipRepo)
+			if err != nil {
+				return err
+			}
+			recalculateStreak(habit, completions, engine)
+		}
+
+		if err := usecase.HabitRepo.Update(habit); err != nil {
+			log.Printf("Error updating habit with ID(%d): %v", habit.ID, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// loadSettings returns the user's stored settings, or the defaults if they have
+// not saved any yet. Habits are always tracked in their owner's settings.
+func loadSettings(repo domain.SettingsRepository, userID uint) (*domain.Settings, error) {
+	defaults := domain.DefaultSettings()
+	defaults.UserID = userID
+	if repo == nil {
+		return &defaults, nil
+	}
+
+	settings, err := repo.Get(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &defaults, nil
+		}
+		log.Printf("Error retrieving settings for user with ID(%d): %v", userID, err)
+		return nil, fmt.Errorf("failed to retrieve settings")
+	}
+	if settings == nil {
+		return &defaults, nil
+	}
+	return settings, nil
+}
+
+// settingsCache loads each user's settings at most once, for work that spans
+// habits of different owners.
+type settingsCache struct {
+	repo   domain.SettingsRepository
+	byUser map[uint]*domain.Settings
+}
+
+func newSettingsCache(repo domain.SettingsRepository) *settingsCache {
+	return &settingsCache{repo: repo, byUser: map[uint]*domain.Settings{}}
+}
+
+func (cache *settingsCache) get(userID uint) (*domain.Settings, error) {
+	if settings, ok := cache.byUser[userID]; ok {
+		return settings, nil
+	}
+
+	settings, err := loadSettings(cache.repo, userID)
+	if err != nil {
+		return nil, err
+	}
+	cache.byUser[userID] = settings
+	return settings, nil
+}
@@ -0,0 +1,252 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetSettings(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockGet      func(uint) (*domain.Settings, error)
+		wantErr      bool
+		errContains  string
+		wantTimezone string
+	}{
+		{
+			name: "stored settings",
+			mockGet: func(userID uint) (*domain.Settings, error) {
+				assert.Equal(t, testUserID, userID)
+				return &domain.Settings{ID: 1, UserID: userID, Timezone: "Europe/London", WeekStart: time.Sunday}, nil
+			},
+			wantErr:      false,
+			wantTimezone: "Europe/London",
+		},
+		{
+			name: "no settings saved yet",
+			mockGet: func(uint) (*domain.Settings, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			wantErr:      false,
+			wantTimezone: "UTC",
+		},
+		{
+			name: "repository error",
+			mockGet: func(uint) (*domain.Settings, error) {
+				return nil, errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to retrieve settings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &usecase.SettingsUsecase{SettingsRepo: &usecase.MockSettingsRepo{GetFn: tt.mockGet}}
+
+			settings, err := uc.GetSettings(testUserID)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantTimezone, settings.Timezone)
+			}
+		})
+	}
+}
+
+func TestUpdateSettings(t *testing.T) {
+	timezone := func(name string) *string { return &name }
+	number := func(n int) *int { return &n }
+	weekday := func(d time.Weekday) *time.Weekday { return &d }
+
+	tests := []struct {
+		name        string
+		input       usecase.SettingsInput
+		mockGet     func(uint) (*domain.Settings, error)
+		mockSave    func(*domain.Settings) error
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "first save",
+			input: usecase.SettingsInput{
+				Timezone:           timezone("America/New_York"),
+				WeekStart:          weekday(time.Sunday),
+				DayEndHour:         number(3),
+				FreezeEarnInterval: number(5),
+				MaxStreakFreezes:   number(3),
+			},
+			mockGet: func(uint) (*domain.Settings, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			mockSave: func(s *domain.Settings) error {
+				assert.Equal(t, uint(0), s.ID)
+				assert.Equal(t, testUserID, s.UserID)
+				assert.Equal(t, "America/New_York", s.Timezone)
+				assert.Equal(t, time.Sunday, s.WeekStart)
+				assert.Equal(t, 3, s.DayEndHour)
+				assert.Equal(t, 5, s.FreezeEarnInterval)
+				assert.Equal(t, 3, s.MaxStreakFreezes)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:  "omitted fields keep their value",
+			input: usecase.SettingsInput{Timezone: timezone("Asia/Tokyo"), DayEndHour: number(0)},
+			mockGet: func(uint) (*domain.Settings, error) {
+				return &domain.Settings{ID: 1, Timezone: "UTC", WeekStart: time.Sunday, DayEndHour: 2, FreezeEarnInterval: 10, MaxStreakFreezes: 4}, nil
+			},
+			mockSave: func(s *domain.Settings) error {
+				assert.Equal(t, uint(1), s.ID)
+				assert.Equal(t, "Asia/Tokyo", s.Timezone)
+				assert.Equal(t, time.Sunday, s.WeekStart)
+				assert.Equal(t, 0, s.DayEndHour)
+				assert.Equal(t, 10, s.FreezeEarnInterval)
+				assert.Equal(t, 4, s.MaxStreakFreezes)
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:        "invalid timezone",
+			input:       usecase.SettingsInput{Timezone: timezone("Nowhere/Special")},
+			wantErr:     true,
+			errContains: "invalid timezone",
+		},
+		{
+			name:        "invalid day end hour",
+			input:       usecase.SettingsInput{DayEndHour: number(18)},
+			wantErr:     true,
+			errContains: "invalid day end hour",
+		},
+		{
+			name:  "repository error",
+			input: usecase.SettingsInput{WeekStart: weekday(time.Monday)},
+			mockSave: func(s *domain.Settings) error {
+				return errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to update settings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &usecase.MockSettingsRepo{
+				GetFn:  tt.mockGet,
+				SaveFn: tt.mockSave,
+			}
+			uc := &usecase.SettingsUsecase{SettingsRepo: mockRepo, HabitRepo: &usecase.MockHabitRepo{}, SkipRepo: &usecase.MockSkipRepo{}}
+
+			_, err := uc.UpdateSettings(testUserID, tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUpdateSettingsRecalculatesStreaks(t *testing.T) {
+	timezone := func(name string) *string { return &name }
+	number := func(n int) *int { return &n }
+
+	tests := []struct {
+		name        string
+		input       usecase.SettingsInput
+		mockUpdate  func(*domain.Habit) error
+		wantUpdated []uint
+		wantErr     bool
+	}{
+		{
+			name:        "timezone change",
+			input:       usecase.SettingsInput{Timezone: timezone("Asia/Tokyo")},
+			wantUpdated: []uint{1, 2},
+		},
+		{
+			name:        "day end hour change",
+			input:       usecase.SettingsInput{DayEndHour: number(3)},
+			wantUpdated: []uint{1, 2},
+		},
+		{
+			name:  "freeze settings change",
+			input: usecase.SettingsInput{FreezeEarnInterval: number(5), MaxStreakFreezes: number(1)},
+		},
+		{
+			name:  "habit update error",
+			input: usecase.SettingsInput{Timezone: timezone("Asia/Tokyo")},
+			mockUpdate: func(*domain.Habit) error {
+				return errors.New("db error")
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			createdAt := time.Now().AddDate(0, 0, -10)
+			var updated []uint
+			saved := false
+			habitRepo := &usecase.MockHabitRepo{
+				GetAllForUserFn: func(userID uint) ([]domain.Habit, error) {
+					assert.Equal(t, testUserID, userID)
+					return []domain.Habit{
+						{ID: 1, Name: "Read", Frequency: "daily", CreatedAt: createdAt, CurrentStreak: 7, LongestStreak: 7},
+						{ID: 2, Name: "Smoking", Frequency: "daily", Kind: "quit", CreatedAt: createdAt},
+					}, nil
+				},
+				GetCompletionsFn: func(uint) ([]domain.HabitCompletion, error) {
+					return []domain.HabitCompletion{{HabitID: 1, CompletedAt: time.Now()}}, nil
+				},
+				UpdateFn: func(h *domain.Habit) error {
+					if tt.mockUpdate != nil {
+						return tt.mockUpdate(h)
+					}
+					assert.NotNil(t, h.StreakRuns)
+					if h.ID == 1 {
+						assert.Equal(t, 1, h.CurrentStreak)
+					} else {
+						assert.Equal(t, 10, h.CurrentStreak)
+					}
+					updated = append(updated, h.ID)
+					return nil
+				},
+			}
+			settingsRepo := &usecase.MockSettingsRepo{
+				GetFn: func(uint) (*domain.Settings, error) {
+					return &domain.Settings{ID: 1, Timezone: "UTC", DayEndHour: 0, FreezeEarnInterval: 7, MaxStreakFreezes: 2}, nil
+				},
+				SaveFn: func(*domain.Settings) error {
+					saved = true
+					return nil
+				},
+			}
+			uc := &usecase.SettingsUsecase{SettingsRepo: settingsRepo, HabitRepo: habitRepo, SkipRepo: &usecase.MockSkipRepo{}}
+
+			_, err := uc.UpdateSettings(testUserID, tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "failed to update settings")
+				assert.False(t, saved)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, saved)
+				assert.Equal(t, tt.wantUpdated, updated)
+			}
+		})
+	}
+}
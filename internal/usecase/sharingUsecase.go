@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+// SharingUsecase lets a habit's owner invite accountability partners, who can
+// follow the habit and react to and comment on its completions.
+type SharingUsecase struct {
+	HabitRepo   domain.HabitRepository
+	SharingRepo domain.SharingRepository
+	UserRepo    domain.UserRepository
+}
+
+// ShareInput invites the user registered with Email as a viewer or co-owner.
+// Role defaults to viewer.
+type ShareInput struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type RoleInput struct {
+	Role string `json:"role"`
+}
+
+type ReactionInput struct {
+	Emoji string `json:"emoji"`
+}
+
+type CommentInput struct {
+	Body string `json:"body"`
+}
+
+// Partner is someone with access to a habit, its owner included.
+type Partner struct {
+	UserID    uint
+	Email     string
+	Role      domain.HabitRole
+	CreatedAt time.Time
+}
+
+// CompletionFeedback is what partners left on a completion.
+type CompletionFeedback struct {
+	Reactions []domain.CompletionReaction
+	Comments  []domain.CompletionComment
+}
+
+// ShareHabit gives another user access to the owner's habit.
+func (usecase *SharingUsecase) ShareHabit(ownerID, habitID uint, input ShareInput) (*Partner, error) {
+	habit, err := usecase.getHabit(ownerID, habitID)
+	if err != nil {
+		return nil, err
+	}
+	if habit.Role != domain.RoleOwner {
+		return nil, fmt.Errorf("permission denied")
+	}
+
+	role, err := domain.ParseMemberRole(input.Role)
+	if err != nil {
+		return nil, fmt.Errorf("invalid role")
+	}
+
+	email, err := domain.NormalizeEmail(input.Email)
+	if err != nil {
+		return nil, err
+	}
+	user, err := usecase.UserRepo.GetByEmail(email)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("user not found")
+		}
+		log.Println("Error retrieving user by email:", err)
+		return nil, fmt.Errorf("failed to share habit")
+	}
+	if user.ID == habit.UserID {
+		return nil, fmt.Errorf("cannot share a habit with its owner")
+	}
+
+	if _, err := usecase.SharingRepo.GetMember(habitID, user.ID); err == nil {
+		return nil, fmt.Errorf("user is already a member")
+	} else if err != gorm.ErrRecordNotFound {
+		log.Printf("Error retrieving member of habit with ID(%d): %v", habitID, err)
+		return nil, fmt.Errorf("failed to share habit")
+	}
+
+	member := &domain.HabitMember{HabitID: habitID, UserID: user.ID, Role: role}
+	if err := usecase.SharingRepo.CreateMember(member); err != nil {
+		log.Printf("Error sharing habit with ID(%d): %v", habitID, err)
+		return nil, fmt.Errorf("failed to share habit")
+	}
+
+	log.Printf("Habit with ID(%d) shared with user with ID(%d) as %s", habitID, user.ID, role)
+	return &Partner{UserID: user.ID, Email: user.Email, Role: role, CreatedAt: member.CreatedAt}, nil
+}
+
+// GetPartners lists everyone with access to the habit, starting with its owner.
+func (usecase *SharingUsecase) GetPartners(userID, habitID uint) ([]Partner, error) {
+	habit, err := usecase.getHabit(userID, habitID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := usecase.SharingRepo.GetMembers(habitID)
+	if err != nil {
+		log.Printf("Error retrieving members of habit with ID(%d): %v", habitID, err)
+		return nil, fmt.Errorf("failed to get partners")
+	}
+
+	partners := make([]Partner, 0, len(members)+1)
+	owner := domain.HabitMember{UserID: habit.UserID, Role: domain.RoleOwner, CreatedAt: habit.CreatedAt}
+	for _, member := range append([]domain.HabitMember{owner}, members...) {
+		partner := Partner{UserID: member.UserID, Role: member.Role, CreatedAt: member.CreatedAt}
+		if user, err := usecase.UserRepo.GetByID(member.UserID); err == nil {
+			partner.Email = user.Email
+		} else {
+			log.Printf("Error retrieving user with ID(%d): %v", member.UserID, err)
+		}
+		partners = append(partners, partner)
+	}
+	return partners, nil
+}
+
+// UpdatePartnerRole switches a partner between viewer and co-owner.
+func (usecase *SharingUsecase) UpdatePartnerRole(ownerID, habitID, memberID uint, input RoleInput) error {
+	if _, err := usecase.ownedMember(ownerID, habitID, memberID); err != nil {
+		return err
+	}
+
+	role, err := domain.ParseMemberRole(input.Role)
+	if err != nil {
+		return fmt.Errorf("invalid role")
+	}
+
+	if err := usecase.SharingRepo.UpdateMemberRole(habitID, memberID, role); err != nil {
+		log.Printf("Error updating role of user with ID(%d) in habit with ID(%d): %v", memberID, habitID, err)
+		return fmt.Errorf("failed to update partner")
+	}
+	return nil
+}
+
+// RemovePartner takes a partner's access away. The owner can remove anyone,
+// partners can only leave themselves.
+func (usecase *SharingUsecase) RemovePartner(userID, habitID, memberID uint) error {
+	if userID == memberID {
+		if _, err := usecase.getHabit(userID, habitID); err != nil {
+			return err
+		}
+		if _, err := usecase.getMember(habitID, memberID); err != nil {
+			return err
+		}
+	} else if _, err := usecase.ownedMember(userID, habitID, memberID); err != nil {
+		return err
+	}
+
+	if err := usecase.SharingRepo.DeleteMember(habitID, memberID); err != nil {
+		log.Printf("Error removing user with ID(%d) from habit with ID(%d): %v", memberID, habitID, err)
+		return fmt.Errorf("failed to remove partner")
+	}
+
+	log.Printf("User with ID(%d) removed from habit with ID(%d)", memberID, habitID)
+	return nil
+}
+
+// React adds the user's reaction to a completion of a habit they can see.
+func (usecase *SharingUsecase) React(userID, completionID uint, input ReactionInput) (*domain.CompletionReaction, error) {
+	if err := domain.ValidateReaction(input.Emoji); err != nil {
+		return nil, err
+	}
+	if _, err := usecase.getCompletion(userID, completionID); err != nil {
+		return nil, err
+	}
+
+	reaction := &domain.CompletionReaction{CompletionID: completionID, UserID: userID, Emoji: input.Emoji}
+	if err := usecase.SharingRepo.AddReaction(reaction); err != nil {
+		log.Printf("Error adding reaction to completion with ID(%d): %v", completionID, err)
+		return nil, fmt.Errorf("failed to add reaction")
+	}
+	return reaction, nil
+}
+
+// RemoveReaction takes back one of the user's own reactions.
+func (usecase *SharingUsecase) RemoveReaction(userID, completionID uint, emoji string) error {
+	if _, err := usecase.getCompletion(userID, completionID); err != nil {
+		return err
+	}
+
+	if err := usecase.SharingRepo.RemoveReaction(completionID, userID, emoji); err != nil {
+		log.Printf("Error removing reaction from completion with ID(%d): %v", completionID, err)
+		return fmt.Errorf("failed to remove reaction")
+	}
+	return nil
+}
+
+// Comment leaves the user's comment on a completion of a habit they can see.
+func (usecase *SharingUsecase) Comment(userID, completionID uint, input CommentInput) (*domain.CompletionComment, error) {
+	body, err := domain.NormalizeComment(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := usecase.getCompletion(userID, completionID); err != nil {
+		return nil, err
+	}
+
+	comment := &domain.CompletionComment{CompletionID: completionID, UserID: userID, Body: body}
+	if err := usecase.SharingRepo.AddComment(comment); err != nil {
+		log.Printf("Error adding comment to completion with ID(%d): %v", completionID, err)
+		return nil, fmt.Errorf("failed to add comment")
+	}
+	return comment, nil
+}
+
+// DeleteComment removes a comment. Its author and the habit's owner may do this.
+func (usecase *SharingUsecase) DeleteComment(userID, commentID uint) error {
+	comment, err := usecase.SharingRepo.GetComment(commentID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("comment not found")
+		}
+		log.Printf("Error retrieving comment with ID(%d): %v", commentID, err)
+		return fmt.Errorf("failed to delete comment")
+	}
+
+	habit, err := usecase.getCompletion(userID, comment.CompletionID)
+	if err != nil {
+		if err.Error() == "completion not found" {
+			return fmt.Errorf("comment not found")
+		}
+		return err
+	}
+	if comment.UserID != userID && habit.Role != domain.RoleOwner {
+		return fmt.Errorf("permission denied")
+	}
+
+	if err := usecase.SharingRepo.DeleteComment(commentID); err != nil {
+		log.Printf("Error deleting comment with ID(%d): %v", commentID, err)
+		return fmt.Errorf("failed to delete comment")
+	}
+	return nil
+}
+
+// GetFeedback returns the reactions and comments on a completion, oldest first.
+func (usecase *SharingUsecase) GetFeedback(userID, completionID uint) (*CompletionFeedback, error) {
+	if _, err := usecase.getCompletion(userID, completionID); err != nil {
+		return nil, err
+	}
+
+	reactions, err := usecase.SharingRepo.GetReactions(completionID)
+	if err != nil {
+		log.Printf("Error retrieving reactions to completion with ID(%d): %v", completionID, err)
+		return nil, fmt.Errorf("failed to get feedback")
+	}
+	comments, err := usecase.SharingRepo.GetComments(completionID)
+	if err != nil {
+		log.Printf("Error retrieving comments on completion with ID(%d): %v", completionID, err)
+		return nil, fmt.Errorf("failed to get feedback")
+	}
+	return &CompletionFeedback{Reactions: reactions, Comments: comments}, nil
+}
+
+// getHabit returns the habit with the user's role if they have access to it
+func (usecase *SharingUsecase) getHabit(userID, habitID uint) (*domain.Habit, error) {
+	habit, role, err := usecase.HabitRepo.GetByIDForMember(habitID, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("habit not found")
+		}
+		log.Printf("Error retrieving habit with ID(%d): %v", habitID, err)
+		return nil, fmt.Errorf("failed to retrieve habit")
+	}
+	habit.Role = role
+	return habit, nil
+}
+
+// ownedMember checks the user owns the habit and memberID is one of its partners
+func (usecase *SharingUsecase) ownedMember(ownerID, habitID, memberID uint) (*domain.HabitMember, error) {
+	habit, err := usecase.getHabit(ownerID, habitID)
+	if err != nil {
+		return nil, err
+	}
+	if habit.Role != domain.RoleOwner {
+		return nil, fmt.Errorf("permission denied")
+	}
+	return usecase.getMember(habitID, memberID)
+}
+
+func (usecase *SharingUsecase) getMember(habitID, memberID uint) (*domain.HabitMember, error) {
+	member, err := usecase.SharingRepo.GetMember(habitID, memberID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("partner not found")
+		}
+		log.Printf("Error retrieving member of habit with ID(%d): %v", habitID, err)
+		return nil, fmt.Errorf("failed to retrieve partner")
+	}
+	return member, nil
+}
+
+// getCompletion returns the habit of the completion if the user has access to
+// it. Completions of habits the user cannot see are reported as not found.
+func (usecase *SharingUsecase) getCompletion(userID, completionID uint) (*domain.Habit, error) {
+	completion, err := usecase.HabitRepo.GetCompletion(completionID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("completion not found")
+		}
+		log.Printf("Error retrieving completion with ID(%d): %v", completionID, err)
+		return nil, fmt.Errorf("failed to retrieve completion")
+	}
+
+	habit, err := usecase.getHabit(userID, completion.HabitID)
+	if err != nil {
+		if err.Error() == "habit not found" {
+			return nil, fmt.Errorf("completion not found")
+		}
+		return nil, err
+	}
+	return habit, nil
+}
@@ -0,0 +1,270 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// memberLookup returns a GetByIDForMember mock where user 1 owns habit 1 and
+// the given roles were granted to other users
+func memberLookup(roles map[uint]domain.HabitRole) func(uint, uint) (*domain.Habit, domain.HabitRole, error) {
+	return func(id, userID uint) (*domain.Habit, domain.HabitRole, error) {
+		if id != 1 {
+			return nil, "", gorm.ErrRecordNotFound
+		}
+		habit := &domain.Habit{ID: 1, UserID: testUserID, Name: "Run"}
+		if userID == testUserID {
+			return habit, domain.RoleOwner, nil
+		}
+		if role, ok := roles[userID]; ok {
+			return habit, role, nil
+		}
+		return nil, "", gorm.ErrRecordNotFound
+	}
+}
+
+func TestShareHabit(t *testing.T) {
+	users := map[string]*domain.User{
+		"test@example.com":  {ID: testUserID, Email: "test@example.com"},
+		"other@example.com": {ID: 2, Email: "other@example.com"},
+		"third@example.com": {ID: 3, Email: "third@example.com"},
+	}
+
+	tests := []struct {
+		name        string
+		ownerID     uint
+		habitID     uint
+		input       usecase.ShareInput
+		errContains string
+		wantRole    domain.HabitRole
+	}{
+		{
+			name:     "viewer by default",
+			ownerID:  testUserID,
+			habitID:  1,
+			input:    usecase.ShareInput{Email: "Other@Example.com "},
+			wantRole: domain.RoleViewer,
+		},
+		{
+			name:     "co-owner",
+			ownerID:  testUserID,
+			habitID:  1,
+			input:    usecase.ShareInput{Email: "other@example.com", Role: "co-owner"},
+			wantRole: domain.RoleCoOwner,
+		},
+		{
+			name:        "co-owner cannot invite",
+			ownerID:     3,
+			habitID:     1,
+			input:       usecase.ShareInput{Email: "other@example.com"},
+			errContains: "permission denied",
+		},
+		{
+			name:        "unknown habit",
+			ownerID:     testUserID,
+			habitID:     9,
+			input:       usecase.ShareInput{Email: "other@example.com"},
+			errContains: "habit not found",
+		},
+		{
+			name:        "invalid role",
+			ownerID:     testUserID,
+			habitID:     1,
+			input:       usecase.ShareInput{Email: "other@example.com", Role: "owner"},
+			errContains: "invalid role",
+		},
+		{
+			name:        "unknown user",
+			ownerID:     testUserID,
+			habitID:     1,
+			input:       usecase.ShareInput{Email: "nobody@example.com"},
+			errContains: "user not found",
+		},
+		{
+			name:        "owner",
+			ownerID:     testUserID,
+			habitID:     1,
+			input:       usecase.ShareInput{Email: "test@example.com"},
+			errContains: "cannot share a habit with its owner",
+		},
+		{
+			name:        "already a member",
+			ownerID:     testUserID,
+			habitID:     1,
+			input:       usecase.ShareInput{Email: "third@example.com"},
+			errContains: "user is already a member",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *domain.HabitMember
+			uc := &usecase.SharingUsecase{
+				HabitRepo: &usecase.MockHabitRepo{GetByIDForMemberFn: memberLookup(map[uint]domain.HabitRole{3: domain.RoleCoOwner})},
+				UserRepo: &usecase.MockUserRepo{
+					GetByEmailFn: func(email string) (*domain.User, error) {
+						if user, ok := users[email]; ok {
+							return user, nil
+						}
+						return nil, gorm.ErrRecordNotFound
+					},
+				},
+				SharingRepo: &usecase.MockSharingRepo{
+					GetMemberFn: func(habitID, userID uint) (*domain.HabitMember, error) {
+						if userID == 3 {
+							return &domain.HabitMember{HabitID: habitID, UserID: userID, Role: domain.RoleCoOwner}, nil
+						}
+						return nil, gorm.ErrRecordNotFound
+					},
+					CreateMemberFn: func(m *domain.HabitMember) error {
+						created = m
+						return nil
+					},
+				},
+			}
+
+			partner, err := uc.ShareHabit(tt.ownerID, tt.habitID, tt.input)
+
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				assert.Nil(t, created)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, &domain.HabitMember{HabitID: 1, UserID: 2, Role: tt.wantRole}, created)
+			assert.Equal(t, "other@example.com", partner.Email)
+			assert.Equal(t, tt.wantRole, partner.Role)
+		})
+	}
+}
+
+func TestRemovePartner(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      uint
+		memberID    uint
+		errContains string
+	}{
+		{name: "owner removes partner", userID: testUserID, memberID: 2},
+		{name: "partner leaves", userID: 2, memberID: 2},
+		{name: "partner cannot remove others", userID: 2, memberID: 3, errContains: "permission denied"},
+		{name: "not a partner", userID: testUserID, memberID: 4, errContains: "partner not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed := uint(0)
+			uc := &usecase.SharingUsecase{
+				HabitRepo: &usecase.MockHabitRepo{
+					GetByIDForMemberFn: memberLookup(map[uint]domain.HabitRole{2: domain.RoleViewer, 3: domain.RoleViewer}),
+				},
+				SharingRepo: &usecase.MockSharingRepo{
+					GetMemberFn: func(habitID, userID uint) (*domain.HabitMember, error) {
+						if userID == 2 || userID == 3 {
+							return &domain.HabitMember{HabitID: habitID, UserID: userID, Role: domain.RoleViewer}, nil
+						}
+						return nil, gorm.ErrRecordNotFound
+					},
+					DeleteMemberFn: func(habitID, userID uint) error {
+						removed = userID
+						return nil
+					},
+				},
+			}
+
+			err := uc.RemovePartner(tt.userID, 1, tt.memberID)
+
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				assert.Zero(t, removed)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.memberID, removed)
+		})
+	}
+}
+
+func TestCompletionFeedback(t *testing.T) {
+	habitRepo := &usecase.MockHabitRepo{
+		GetByIDForMemberFn: memberLookup(map[uint]domain.HabitRole{2: domain.RoleViewer}),
+		GetCompletionFn: func(id uint) (*domain.HabitCompletion, error) {
+			if id != 10 {
+				return nil, gorm.ErrRecordNotFound
+			}
+			return &domain.HabitCompletion{ID: 10, HabitID: 1}, nil
+		},
+	}
+
+	t.Run("partners react and comment", func(t *testing.T) {
+		var reactions []*domain.CompletionReaction
+		var comments []*domain.CompletionComment
+		uc := &usecase.SharingUsecase{
+			HabitRepo: habitRepo,
+			SharingRepo: &usecase.MockSharingRepo{
+				AddReactionFn: func(r *domain.CompletionReaction) error {
+					reactions = append(reactions, r)
+					return nil
+				},
+				AddCommentFn: func(c *domain.CompletionComment) error {
+					comments = append(comments, c)
+					return nil
+				},
+			},
+		}
+
+		_, err := uc.React(2, 10, usecase.ReactionInput{Emoji: "🔥"})
+		assert.NoError(t, err)
+		comment, err := uc.Comment(2, 10, usecase.CommentInput{Body: " Nice run! "})
+		assert.NoError(t, err)
+		assert.Equal(t, "Nice run!", comment.Body)
+		assert.Equal(t, []*domain.CompletionReaction{{CompletionID: 10, UserID: 2, Emoji: "🔥"}}, reactions)
+		assert.Len(t, comments, 1)
+
+		_, err = uc.React(2, 10, usecase.ReactionInput{Emoji: "two words"})
+		assert.ErrorContains(t, err, "invalid reaction")
+		_, err = uc.Comment(2, 10, usecase.CommentInput{Body: ""})
+		assert.ErrorContains(t, err, "comment cannot be empty")
+	})
+
+	t.Run("strangers cannot see the completion", func(t *testing.T) {
+		uc := &usecase.SharingUsecase{HabitRepo: habitRepo, SharingRepo: &usecase.MockSharingRepo{}}
+
+		_, err := uc.React(5, 10, usecase.ReactionInput{Emoji: "🔥"})
+		assert.ErrorContains(t, err, "completion not found")
+		_, err = uc.GetFeedback(5, 10)
+		assert.ErrorContains(t, err, "completion not found")
+		_, err = uc.GetFeedback(testUserID, 11)
+		assert.ErrorContains(t, err, "completion not found")
+	})
+
+	t.Run("comment deletion", func(t *testing.T) {
+		deleted := uint(0)
+		uc := &usecase.SharingUsecase{
+			HabitRepo: habitRepo,
+			SharingRepo: &usecase.MockSharingRepo{
+				GetCommentFn: func(id uint) (*domain.CompletionComment, error) {
+					if id == 99 {
+						return nil, errors.New("db error")
+					}
+					return &domain.CompletionComment{ID: id, CompletionID: 10, UserID: testUserID}, nil
+				},
+				DeleteCommentFn: func(id uint) error {
+					deleted = id
+					return nil
+				},
+			},
+		}
+
+		assert.ErrorContains(t, uc.DeleteComment(2, 7), "permission denied", "viewers cannot delete the owner's comments")
+		assert.Zero(t, deleted)
+		assert.NoError(t, uc.DeleteComment(testUserID, 7))
+		assert.Equal(t, uint(7), deleted)
+		assert.ErrorContains(t, uc.DeleteComment(testUserID, 99), "failed to delete comment")
+	})
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+type SkipUsecase struct {
+	SkipRepo     domain.SkipRepository
+	HabitRepo    domain.HabitRepository
+	SettingsRepo domain.SettingsRepository
+}
+
+// SkipInput describes a range of calendar days (YYYY-MM-DD, inclusive) to skip
+// for one habit, or for every habit the user owns when HabitID is omitted. EndDate defaults to
+// StartDate for a single day.
+type SkipInput struct {
+	HabitID   *uint  `json:"habit_id"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Reason    string `json:"reason"`
+	Note      string `json:"note"`
+}
+
+// CreateSkip stores the skip, which may lie in the future such as a planned
+// vacation, and recalculates the streaks of the habits it applies to. Skipping
+// a single habit needs permission to edit it.
+func (usecase *SkipUsecase) CreateSkip(userID uint, input SkipInput) (*domain.Skip, error) {
+	skip := &domain.Skip{UserID: userID, HabitID: input.HabitID, Reason: input.Reason, Note: input.Note}
+
+	var err error
+	if skip.StartDate, err = parseSkipDate(input.StartDate); err != nil {
+		return nil, err
+	}
+	if input.EndDate != "" {
+		if skip.EndDate, err = parseSkipDate(input.EndDate); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := domain.ValidateSkip(skip); err != nil {
+		return nil, err
+	}
+
+	if skip.HabitID != nil {
+		_, role, err := usecase.HabitRepo.GetByIDForMember(*skip.HabitID, userID)
+		if err != nil {
+			log.Println("Error fetching habit for skip", err)
+			return nil, fmt.Errorf("habit not found")
+		}
+		if !role.CanEdit() {
+			return nil, fmt.Errorf("permission denied")
+		}
+	}
+
+	if err := usecase.SkipRepo.Create(skip); err != nil {
+		log.Println("Error creating skip:", err)
+		return nil, fmt.Errorf("failed to create skip")
+	}
+
+	if err := usecase.recalculateStreaks(userID, skip.HabitID); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Skip (%s) created from %s to %s", skip.Reason, skip.StartDate.Format("2006-01-02"), skip.EndDate.Format("2006-01-02"))
+	return skip, nil
+}
+
+func (usecase *SkipUsecase) GetSkips(userID uint) ([]domain.Skip, error) {
+	skips, err := usecase.SkipRepo.GetForUser(userID)
+	if err != nil {
+		log.Printf("Error retrieving skips for user with ID(%d): %v", userID, err)
+		return nil, fmt.Errorf("failed to get skips")
+	}
+	return skips, nil
+}
+
+// DeleteSkip removes one of the user's skips and recalculates the streaks it applied to.
+func (usecase *SkipUsecase) DeleteSkip(userID, id uint) error {
+	skip, err := usecase.SkipRepo.GetByID(id)
+	if err != nil || skip.UserID != userID {
+		log.Println("Error: Tried to delete non-existing skip with ID:", id)
+		return fmt.Errorf("skip not found")
+	}
+
+	if err := usecase.SkipRepo.Delete(id); err != nil {
+		log.Println("Error deleting skip:", err)
+		return fmt.Errorf("failed to delete skip")
+	}
+
+	return usecase.recalculateStreaks(userID, skip.HabitID)
+}
+
+// recalculateStreaks replays the completion history of the given habit, or of
+// every habit the user owns when habitID is nil, so a change in skips is
+// reflected straight away.
+func (usecase *SkipUsecase) recalculateStreaks(userID uint, habitID *uint) error {
+	var habits []domain.Habit
+	if habitID != nil {
+		habit, err := usecase.HabitRepo.GetByID(*habitID)
+		if err != nil {
+			log.Printf("Error retrieving habit with ID(%d): %v", *habitID, err)
+			return fmt.Errorf("failed to update streaks")
+		}
+		habits = append(habits, *habit)
+	} else {
+		var err error
+		if habits, err = usecase.HabitRepo.GetAllForUser(userID); err != nil {
+			log.Printf("Error retrieving habits for user with ID(%d): %v", userID, err)
+			return fmt.Errorf("failed to update streaks")
+		}
+	}
+
+	cache := newSettingsCache(usecase.SettingsRepo)
+	for i := range habits {
+		habit := &habits[i]
+		if habit.IsQuit() {
+			continue
+		}
+
+		settings, err := cache.get(habit.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to update streaks")
+		}
+
+		completions, err := usecase.HabitRepo.GetCompletions(habit.ID)
+		if err != nil {
+			log.Printf("Error fetching completion history for habit with ID(%d): %v", habit.ID, err)
+			return fmt.Errorf("failed to update streaks")
+		}
+
+		engine, err := newStreakEngine(habit, *settings, usecase.SkipRepo)
+		if err != nil {
+			return fmt.Errorf("failed to update streaks")
+		}
+		recalculateStreak(habit, completions, engine)
+
+		if err := usecase.HabitRepo.Update(habit); err != nil {
+			log.Printf("Error updating habit with ID(%d): %v", habit.ID, err)
+			return fmt.Errorf("failed to update streaks")
+		}
+	}
+	return nil
+}
+
+func parseSkipDate(value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid skip date")
+	}
+	return date, nil
+}
+
+// loadSkips returns the skips that apply to the habit, none if skips are not configured.
+func loadSkips(repo domain.SkipRepository, habit *domain.Habit) ([]domain.Skip, error) {
+	if repo == nil {
+		return nil, nil
+	}
+
+	skips, err := repo.GetForHabit(habit.ID, habit.UserID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		log.Printf("Error retrieving skips for habit with ID(%d): %v", habit.ID, err)
+		return nil, fmt.Errorf("failed to retrieve skips")
+	}
+	return skips, nil
+}
@@ -0,0 +1,208 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateSkip(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+	habitID := uint(1)
+
+	tests := []struct {
+		name        string
+		input       usecase.SkipInput
+		mockGetByID func(uint) (*domain.Habit, error)
+		role        domain.HabitRole
+		mockCreate  func(*domain.Skip) error
+		wantErr     bool
+		errContains string
+		wantUpdates int
+		wantStreak  int
+	}{
+		{
+			name: "sick days keep the streak going",
+			input: usecase.SkipInput{
+				HabitID:   &habitID,
+				StartDate: today.AddDate(0, 0, -2).Format("2006-01-02"),
+				EndDate:   today.Format("2006-01-02"),
+				Reason:    "sick",
+			},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			wantErr:     false,
+			wantUpdates: 1,
+			wantStreak:  2,
+		},
+		{
+			name:        "vacation for every habit",
+			input:       usecase.SkipInput{StartDate: today.AddDate(0, 0, 7).Format("2006-01-02"), EndDate: today.AddDate(0, 0, 21).Format("2006-01-02"), Reason: "vacation"},
+			wantErr:     false,
+			wantUpdates: 2,
+		},
+		{
+			name:        "invalid date",
+			input:       usecase.SkipInput{StartDate: "next week"},
+			wantErr:     true,
+			errContains: "invalid skip date",
+		},
+		{
+			name:        "end before start",
+			input:       usecase.SkipInput{StartDate: "2024-07-14", EndDate: "2024-07-01"},
+			wantErr:     true,
+			errContains: "skip end date cannot be before its start date",
+		},
+		{
+			name:        "viewers cannot skip a shared habit",
+			input:       usecase.SkipInput{HabitID: &habitID, StartDate: "2024-07-01"},
+			role:        domain.RoleViewer,
+			wantErr:     true,
+			errContains: "permission denied",
+		},
+		{
+			name:  "habit not found",
+			input: usecase.SkipInput{HabitID: &habitID, StartDate: "2024-07-01"},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			wantErr:     true,
+			errContains: "habit not found",
+		},
+		{
+			name:        "repository error",
+			input:       usecase.SkipInput{StartDate: "2024-07-01"},
+			mockCreate:  func(s *domain.Skip) error { return errors.New("db error") },
+			wantErr:     true,
+			errContains: "failed to create skip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []domain.Skip
+			var updated []*domain.Habit
+
+			skipRepo := &usecase.MockSkipRepo{
+				CreateFn: func(s *domain.Skip) error {
+					if tt.mockCreate != nil {
+						return tt.mockCreate(s)
+					}
+					assert.Equal(t, testUserID, s.UserID)
+					created = append(created, *s)
+					return nil
+				},
+				GetForHabitFn: func(id, ownerID uint) ([]domain.Skip, error) { return created, nil },
+			}
+			habitRepo := &usecase.MockHabitRepo{
+				GetByIDFn: tt.mockGetByID,
+				GetAllForUserFn: func(userID uint) ([]domain.Habit, error) {
+					assert.Equal(t, testUserID, userID)
+					return []domain.Habit{{ID: 1, Frequency: "daily"}, {ID: 2, Frequency: "weekly"}, {ID: 3, Kind: "quit"}}, nil
+				},
+				GetCompletionsFn: func(id uint) ([]domain.HabitCompletion, error) {
+					return []domain.HabitCompletion{
+						{HabitID: id, CompletedAt: today.AddDate(0, 0, -4)},
+						{HabitID: id, CompletedAt: today.AddDate(0, 0, -3)},
+					}, nil
+				},
+				UpdateFn: func(h *domain.Habit) error {
+					updated = append(updated, h)
+					return nil
+				},
+			}
+			if tt.role != "" {
+				habitRepo.GetByIDForMemberFn = func(id, userID uint) (*domain.Habit, domain.HabitRole, error) {
+					return &domain.Habit{ID: id, UserID: 2, Frequency: "daily"}, tt.role, nil
+				}
+			}
+			uc := &usecase.SkipUsecase{SkipRepo: skipRepo, HabitRepo: habitRepo}
+
+			skip, err := uc.CreateSkip(testUserID, tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, skip)
+				assert.Len(t, updated, tt.wantUpdates)
+				if tt.wantStreak > 0 {
+					assert.Equal(t, tt.wantStreak, updated[0].CurrentStreak)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteSkip(t *testing.T) {
+	habitID := uint(1)
+
+	tests := []struct {
+		name        string
+		mockGetByID func(uint) (*domain.Skip, error)
+		mockDelete  func(uint) error
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "successful delete",
+			mockGetByID: func(id uint) (*domain.Skip, error) {
+				return &domain.Skip{ID: id, UserID: testUserID, HabitID: &habitID}, nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "another user's skip",
+			mockGetByID: func(id uint) (*domain.Skip, error) {
+				return &domain.Skip{ID: id, UserID: 2}, nil
+			},
+			wantErr:     true,
+			errContains: "skip not found",
+		},
+		{
+			name: "skip not found",
+			mockGetByID: func(id uint) (*domain.Skip, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			wantErr:     true,
+			errContains: "skip not found",
+		},
+		{
+			name: "repository error",
+			mockGetByID: func(id uint) (*domain.Skip, error) {
+				return &domain.Skip{ID: id, UserID: testUserID}, nil
+			},
+			mockDelete:  func(id uint) error { return errors.New("db error") },
+			wantErr:     true,
+			errContains: "failed to delete skip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &usecase.SkipUsecase{
+				SkipRepo: &usecase.MockSkipRepo{GetByIDFn: tt.mockGetByID, DeleteFn: tt.mockDelete},
+				HabitRepo: &usecase.MockHabitRepo{GetByIDFn: func(id uint) (*domain.Habit, error) {
+					return &domain.Habit{ID: id, Frequency: "daily"}, nil
+				}},
+			}
+
+			err := uc.DeleteSkip(testUserID, 1)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
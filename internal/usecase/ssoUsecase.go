@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+// SSOUsecase logs users in through an OpenID Connect provider, alongside local
+// accounts. Provider is nil when single sign-on is not configured.
+type SSOUsecase struct {
+	Provider   domain.IdentityProvider
+	UserRepo   domain.UserRepository
+	AdminEmail string // see UserUsecase.AdminEmail
+}
+
+// SSOLogin is a login started at the identity provider. The client keeps State,
+// Nonce and CodeVerifier until the provider sends the user back with a code.
+type SSOLogin struct {
+	URL          string
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+func (usecase *SSOUsecase) Enabled() bool {
+	return usecase.Provider != nil
+}
+
+// StartLogin generates the state, nonce and PKCE verifier for a new login and
+// returns the provider's login page to redirect to.
+func (usecase *SSOUsecase) StartLogin(ctx context.Context) (*SSOLogin, error) {
+	if !usecase.Enabled() {
+		return nil, fmt.Errorf("single sign-on is not configured")
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		secret, _, err := domain.NewToken()
+		if err != nil {
+			log.Println("Error generating single sign-on secrets:", err)
+			return nil, fmt.Errorf("failed to start single sign-on")
+		}
+		secrets[i] = secret
+	}
+	login := &SSOLogin{State: secrets[0], Nonce: secrets[1], CodeVerifier: secrets[2]}
+
+	url, err := usecase.Provider.AuthCodeURL(ctx, login.State, login.Nonce, domain.CodeChallenge(login.CodeVerifier))
+	if err != nil {
+		log.Println("Error building identity provider login URL:", err)
+		return nil, fmt.Errorf("failed to start single sign-on")
+	}
+	login.URL = url
+	return login, nil
+}
+
+// CompleteLogin redeems the code the provider sent the user back with and
+// returns the user it belongs to. A user signing in for the first time is
+// linked to the local account with the same email if the provider verified the
+// email, or gets a new account without a password otherwise.
+func (usecase *SSOUsecase) CompleteLogin(ctx context.Context, code, codeVerifier, nonce string) (*domain.User, error) {
+	if !usecase.Enabled() {
+		return nil, fmt.Errorf("single sign-on is not configured")
+	}
+
+	identity, err := usecase.Provider.Exchange(ctx, code, codeVerifier, nonce)
+	if err != nil {
+		log.Println("Error verifying identity provider login:", err)
+		return nil, fmt.Errorf("single sign-on failed")
+	}
+
+	user, err := usecase.UserRepo.GetByIdentity(identity.Issuer, identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		log.Println("Error retrieving user by identity:", err)
+		return nil, fmt.Errorf("failed to log in")
+	}
+
+	return usecase.provisionUser(identity)
+}
+
+func (usecase *SSOUsecase) provisionUser(identity *domain.ExternalIdentity) (*domain.User, error) {
+	email, err := domain.NormalizeEmail(identity.Email)
+	if err != nil {
+		return nil, fmt.Errorf("identity provider did not share an email address")
+	}
+	link := &domain.UserIdentity{Issuer: identity.Issuer, Subject: identity.Subject}
+
+	existing, err := usecase.UserRepo.GetByEmail(email)
+	if err == nil {
+		// Without a verified email anyone could claim the account by setting its
+		// address at the provider
+		if !identity.EmailVerified {
+			return nil, fmt.Errorf("email is already registered")
+		}
+
+		link.UserID = existing.ID
+		if err := usecase.UserRepo.AddIdentity(link); err != nil {
+			log.Printf("Error linking user with ID(%d) to identity provider: %v", existing.ID, err)
+			return nil, fmt.Errorf("failed to log in")
+		}
+		log.Printf("User with ID(%d) linked to identity provider", existing.ID)
+		return existing, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		log.Println("Error checking for existing user:", err)
+		return nil, fmt.Errorf("failed to log in")
+	}
+
+	// Only an address the provider verified may claim the admin account
+	user := &domain.User{Email: email, IsAdmin: identity.EmailVerified && isAdminEmail(usecase.AdminEmail, email)}
+	if err := usecase.UserRepo.CreateWithIdentity(user, link); err != nil {
+		log.Println("Error provisioning user from identity provider:", err)
+		return nil, fmt.Errorf("failed to log in")
+	}
+
+	log.Printf("User provisioned from identity provider with ID(%d)", user.ID)
+	return user, nil
+}
@@ -0,0 +1,160 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestStartSSOLogin(t *testing.T) {
+	var challenge string
+	provider := &usecase.MockIdentityProvider{
+		AuthCodeURLFn: func(state, nonce, codeChallenge string) (string, error) {
+			challenge = codeChallenge
+			return "https://idp.example.com/authorize?state=" + state, nil
+		},
+	}
+	uc := &usecase.SSOUsecase{Provider: provider}
+
+	login, err := uc.StartLogin(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "https://idp.example.com/authorize?state="+login.State, login.URL)
+	assert.NotEmpty(t, login.Nonce)
+	assert.NotEmpty(t, login.CodeVerifier)
+	assert.NotEqual(t, login.State, login.Nonce)
+	assert.Equal(t, domain.CodeChallenge(login.CodeVerifier), challenge)
+
+	provider.AuthCodeURLFn = func(state, nonce, codeChallenge string) (string, error) {
+		return "", errors.New("provider down")
+	}
+	_, err = uc.StartLogin(context.Background())
+	assert.Error(t, err)
+
+	_, err = (&usecase.SSOUsecase{}).StartLogin(context.Background())
+	assert.ErrorContains(t, err, "single sign-on is not configured")
+}
+
+func TestCompleteSSOLogin(t *testing.T) {
+	verified := &domain.ExternalIdentity{Issuer: "https://idp.example.com", Subject: "alice", Email: "Alice@Example.com", EmailVerified: true}
+	unverified := &domain.ExternalIdentity{Issuer: "https://idp.example.com", Subject: "alice", Email: "alice@example.com"}
+	notFound := func(string, string) (*domain.User, error) { return nil, gorm.ErrRecordNotFound }
+
+	tests := []struct {
+		name                   string
+		identity               *domain.ExternalIdentity
+		exchangeErr            error
+		mockGetByIdentity      func(string, string) (*domain.User, error)
+		mockGetByEmail         func(string) (*domain.User, error)
+		wantUserID             uint
+		wantLinked, wantCreate bool
+		errContains            string
+	}{
+		{
+			name:     "returning user",
+			identity: verified,
+			mockGetByIdentity: func(issuer, subject string) (*domain.User, error) {
+				assert.Equal(t, "https://idp.example.com", issuer)
+				assert.Equal(t, "alice", subject)
+				return &domain.User{ID: 2}, nil
+			},
+			wantUserID: 2,
+		},
+		{
+			name:              "first login creates user",
+			identity:          verified,
+			mockGetByIdentity: notFound,
+			mockGetByEmail: func(email string) (*domain.User, error) {
+				assert.Equal(t, "alice@example.com", email)
+				return nil, gorm.ErrRecordNotFound
+			},
+			wantUserID: 3,
+			wantCreate: true,
+		},
+		{
+			name:              "first login links account with verified email",
+			identity:          verified,
+			mockGetByIdentity: notFound,
+			mockGetByEmail: func(email string) (*domain.User, error) {
+				return &domain.User{ID: 1, Email: email}, nil
+			},
+			wantUserID: 1,
+			wantLinked: true,
+		},
+		{
+			name:              "unverified email does not take over account",
+			identity:          unverified,
+			mockGetByIdentity: notFound,
+			mockGetByEmail: func(email string) (*domain.User, error) {
+				return &domain.User{ID: 1, Email: email}, nil
+			},
+			errContains: "email is already registered",
+		},
+		{
+			name:              "no email",
+			identity:          &domain.ExternalIdentity{Issuer: "https://idp.example.com", Subject: "alice"},
+			mockGetByIdentity: notFound,
+			errContains:       "did not share an email address",
+		},
+		{
+			name:        "invalid id token",
+			exchangeErr: errors.New("invalid id token signature"),
+			errContains: "single sign-on failed",
+		},
+		{
+			name:     "repository error",
+			identity: verified,
+			mockGetByIdentity: func(string, string) (*domain.User, error) {
+				return nil, errors.New("db error")
+			},
+			errContains: "failed to log in",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linked, created := false, false
+			userRepo := &usecase.MockUserRepo{
+				GetByIdentityFn: tt.mockGetByIdentity,
+				GetByEmailFn:    tt.mockGetByEmail,
+				AddIdentityFn: func(identity *domain.UserIdentity) error {
+					assert.Equal(t, tt.wantUserID, identity.UserID)
+					linked = true
+					return nil
+				},
+				CreateWithIdentityFn: func(u *domain.User, identity *domain.UserIdentity) error {
+					assert.Equal(t, "alice@example.com", u.Email)
+					assert.Empty(t, u.PasswordHash)
+					assert.Equal(t, "alice", identity.Subject)
+					u.ID = 3
+					created = true
+					return nil
+				},
+			}
+			provider := &usecase.MockIdentityProvider{
+				ExchangeFn: func(code, codeVerifier, nonce string) (*domain.ExternalIdentity, error) {
+					assert.Equal(t, "code", code)
+					assert.Equal(t, "verifier", codeVerifier)
+					assert.Equal(t, "nonce", nonce)
+					return tt.identity, tt.exchangeErr
+				},
+			}
+			uc := &usecase.SSOUsecase{Provider: provider, UserRepo: userRepo}
+
+			user, err := uc.CompleteLogin(context.Background(), "code", "verifier", "nonce")
+
+			assert.Equal(t, tt.wantLinked, linked)
+			assert.Equal(t, tt.wantCreate, created)
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantUserID, user.ID)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+type StatsUsecase struct {
+	HabitRepo    domain.HabitRepository
+	SettingsRepo domain.SettingsRepository
+	SkipRepo     domain.SkipRepository
+}
+
+// HabitStats holds a habit's completion statistics for each requested window.
+type HabitStats struct {
+	HabitID uint
+	Name    string
+	Windows []domain.PeriodStats
+}
+
+// Stats holds the completion statistics of every build habit, and their totals per window.
+type Stats struct {
+	Windows []domain.PeriodStats
+	Habits  []HabitStats
+}
+
+// HeatmapInput selects a heatmap's range, as calendar days (YYYY-MM-DD), and the
+// timezone its days are bucketed in. The range defaults to the year up to today
+// and the timezone to the one in the habit owner's settings.
+type HeatmapInput struct {
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Timezone string `form:"tz"`
+}
+
+// ParseStatsWindows parses a comma-separated list of windows, e.g. "7d,month".
+// An empty list selects every window.
+func ParseStatsWindows(value string) ([]domain.StatsWindow, error) {
+	if strings.TrimSpace(value) == "" {
+		return domain.StatsWindows, nil
+	}
+
+	var windows []domain.StatsWindow
+	for _, part := range strings.Split(value, ",") {
+		window := domain.StatsWindow(strings.ToLower(strings.TrimSpace(part)))
+		if !domain.IsValidStatsWindow(window) {
+			return nil, fmt.Errorf("invalid stats window: %s", part)
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// GetHabitStats returns the statistics of a habit the user owns or was shared with.
+func (usecase *StatsUsecase) GetHabitStats(userID, id uint, windows []domain.StatsWindow) (*HabitStats, error) {
+	habit, _, err := usecase.HabitRepo.GetByIDForMember(id, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("habit not found")
+		}
+		log.Printf("Error retrieving habit with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to get habit stats")
+	}
+
+	if habit.IsQuit() {
+		return nil, fmt.Errorf("stats are not available for quit habits")
+	}
+
+	settings, err := loadSettings(usecase.SettingsRepo, habit.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get habit stats")
+	}
+
+	stats, err := usecase.habitStats(habit, *settings, windows, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get habit stats")
+	}
+	return stats, nil
+}
+
+// GetStats returns the statistics of every build habit. Quit habits have no
+// periods to complete and are left out.
+func (usecase *StatsUsecase) GetStats(userID uint, windows []domain.StatsWindow) (*Stats, error) {
+	habits, err := usecase.HabitRepo.GetAllForUser(userID)
+	if err != nil {
+		log.Println("Error retrieving all habits:", err)
+		return nil, fmt.Errorf("failed to get stats")
+	}
+
+	settings, err := loadSettings(usecase.SettingsRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats")
+	}
+
+	now := time.Now()
+	stats := &Stats{Habits: []HabitStats{}}
+
+	engine := domain.NewStreakEngine(domain.Schedule{Frequency: domain.Daily}, *settings)
+	for _, window := range windows {
+		from, err := engine.WindowStart(window, now)
+		if err != nil {
+			return nil, err
+		}
+		stats.Windows = append(stats.Windows, domain.PeriodStats{Window: window, From: from, To: now})
+	}
+
+	for i := range habits {
+		if habits[i].IsQuit() {
+			continue
+		}
+
+		habitStats, err := usecase.habitStats(&habits[i], *settings, windows, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stats")
+		}
+		for j, windowStats := range habitStats.Windows {
+			stats.Windows[j].Add(windowStats)
+		}
+		stats.Habits = append(stats.Habits, *habitStats)
+	}
+	return stats, nil
+}
+
+func (usecase *StatsUsecase) habitStats(habit *domain.Habit, settings domain.Settings, windows []domain.StatsWindow, now time.Time) (*HabitStats, error) {
+	completions, err := usecase.HabitRepo.GetCompletions(habit.ID)
+	if err != nil {
+		log.Printf("Error fetching completion history for habit with ID(%d): %v", habit.ID, err)
+		return nil, err
+	}
+
+	engine, err := newStreakEngine(habit, settings, usecase.SkipRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := trackedSince(habit, completions)
+	stats := &HabitStats{HabitID: habit.ID, Name: habit.Name}
+	for _, window := range windows {
+		from, err := engine.WindowStart(window, now)
+		if err != nil {
+			return nil, err
+		}
+
+		since := from
+		if !tracked.IsZero() && tracked.After(from) {
+			since = tracked
+		}
+		windowStats := engine.Stats(completions, since, now)
+		windowStats.Window, windowStats.From = window, from
+		stats.Windows = append(stats.Windows, windowStats)
+	}
+	return stats, nil
+}
+
+// GetHabitHeatmap returns the heatmap of a habit the user owns or was shared with.
+func (usecase *StatsUsecase) GetHabitHeatmap(userID, id uint, input HeatmapInput) (*domain.Heatmap, error) {
+	habit, _, err := usecase.HabitRepo.GetByIDForMember(id, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("habit not found")
+		}
+		log.Printf("Error retrieving habit with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to get heatmap")
+	}
+
+	if habit.IsQuit() {
+		return nil, fmt.Errorf("heatmap is not available for quit habits")
+	}
+
+	heatmap, settings, err := usecase.newHeatmap(habit.UserID, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := usecase.addToHeatmap(heatmap, habit, *settings); err != nil {
+		return nil, fmt.Errorf("failed to get heatmap")
+	}
+	return heatmap, nil
+}
+
+// GetHeatmap returns the heatmap of every build habit combined.
+func (usecase *StatsUsecase) GetHeatmap(userID uint, input HeatmapInput) (*domain.Heatmap, error) {
+	heatmap, settings, err := usecase.newHeatmap(userID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	habits, err := usecase.HabitRepo.GetAllForUser(userID)
+	if err != nil {
+		log.Println("Error retrieving all habits:", err)
+		return nil, fmt.Errorf("failed to get heatmap")
+	}
+
+	for i := range habits {
+		if habits[i].IsQuit() {
+			continue
+		}
+		if err := usecase.addToHeatmap(heatmap, &habits[i], *settings); err != nil {
+			return nil, fmt.Errorf("failed to get heatmap")
+		}
+	}
+	return heatmap, nil
+}
+
+// newHeatmap returns an empty heatmap for the input's range along with the
+// user's settings, with the input's timezone applied.
+func (usecase *StatsUsecase) newHeatmap(userID uint, input HeatmapInput) (*domain.Heatmap, *domain.Settings, error) {
+	settings, err := loadSettings(usecase.SettingsRepo, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get heatmap")
+	}
+
+	if input.Timezone != "" {
+		settings.Timezone = input.Timezone
+		if err := settings.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	today := civilToday(*settings, time.Now())
+	to, err := parseHeatmapDate(input.To, today)
+	if err != nil {
+		return nil, nil, err
+	}
+	from, err := parseHeatmapDate(input.From, to.AddDate(0, 0, -364))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	heatmap, err := domain.NewHeatmap(from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	return heatmap, settings, nil
+}
+
+func (usecase *StatsUsecase) addToHeatmap(heatmap *domain.Heatmap, habit *domain.Habit, settings domain.Settings) error {
+	completions, err := usecase.HabitRepo.GetCompletions(habit.ID)
+	if err != nil {
+		log.Printf("Error fetching completion history for habit with ID(%d): %v", habit.ID, err)
+		return err
+	}
+
+	engine, err := newStreakEngine(habit, settings, usecase.SkipRepo)
+	if err != nil {
+		return err
+	}
+	heatmap.Add(engine, completions, trackedSince(habit, completions))
+	return nil
+}
+
+// trackedSince returns when the habit started being tracked: its creation, or its
+// earliest completion if completions were backdated. Periods before it are not due.
+func trackedSince(habit *domain.Habit, completions []domain.HabitCompletion) time.Time {
+	tracked := habit.CreatedAt
+	for _, completion := range completions {
+		if tracked.IsZero() || completion.CompletedAt.Before(tracked) {
+			tracked = completion.CompletedAt
+		}
+	}
+	return tracked
+}
+
+// civilToday returns the calendar day now counts towards, as midnight UTC.
+func civilToday(settings domain.Settings, now time.Time) time.Time {
+	year, month, day := now.In(settings.Location()).Add(-time.Duration(settings.DayEndHour) * time.Hour).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func parseHeatmapDate(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid heatmap date")
+	}
+	return date, nil
+}
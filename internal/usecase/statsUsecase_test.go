@@ -0,0 +1,247 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestParseStatsWindows(t *testing.T) {
+	windows, err := usecase.ParseStatsWindows("")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatsWindows, windows)
+
+	windows, err = usecase.ParseStatsWindows("7d, Month")
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.StatsWindow{domain.Window7Days, domain.WindowMonth}, windows)
+
+	_, err = usecase.ParseStatsWindows("7d,forever")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid stats window")
+}
+
+func TestGetHabitStats(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		mockGetByID   func(uint) (*domain.Habit, error)
+		wantErr       bool
+		errContains   string
+		wantDue       int
+		wantCompleted int
+	}{
+		{
+			name: "daily habit over the last 30 days",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Name: "Read", Frequency: "daily", CreatedAt: today.AddDate(0, 0, -30)}, nil
+			},
+			wantErr:       false,
+			wantDue:       29,
+			wantCompleted: 3,
+		},
+		{
+			name: "periods before the habit was tracked are not due",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Name: "Read", Frequency: "daily", CreatedAt: today.AddDate(0, 0, -3)}, nil
+			},
+			wantErr:       false,
+			wantDue:       5, // from the backdated completion five days ago
+			wantCompleted: 3,
+		},
+		{
+			name: "quit habit",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", Kind: "quit"}, nil
+			},
+			wantErr:     true,
+			errContains: "stats are not available for quit habits",
+		},
+		{
+			name: "habit not found",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			wantErr:     true,
+			errContains: "habit not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &usecase.MockHabitRepo{
+				GetByIDFn: tt.mockGetByID,
+				GetCompletionsFn: func(id uint) ([]domain.HabitCompletion, error) {
+					return []domain.HabitCompletion{
+						{HabitID: id, CompletedAt: today.AddDate(0, 0, -5)},
+						{HabitID: id, CompletedAt: today.AddDate(0, 0, -3)},
+						{HabitID: id, CompletedAt: today.AddDate(0, 0, -1)},
+					}, nil
+				},
+			}
+			uc := &usecase.StatsUsecase{HabitRepo: mockRepo}
+
+			stats, err := uc.GetHabitStats(testUserID, 1, []domain.StatsWindow{domain.Window30Days})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, stats.Windows, 1)
+				assert.Equal(t, tt.wantDue, stats.Windows[0].DuePeriods)
+				assert.Equal(t, tt.wantCompleted, stats.Windows[0].CompletedPeriods)
+			}
+		})
+	}
+}
+
+func TestGetStats(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		mockGetAll  func() ([]domain.Habit, error)
+		wantErr     bool
+		errContains string
+		wantHabits  int
+		wantDue     int
+	}{
+		{
+			name: "totals across build habits",
+			mockGetAll: func() ([]domain.Habit, error) {
+				return []domain.Habit{
+					{ID: 1, Name: "Read", Frequency: "daily", CreatedAt: today.AddDate(0, 0, -30)},
+					{ID: 2, Name: "Walk", Frequency: "daily", CreatedAt: today.AddDate(0, 0, -30)},
+					{ID: 3, Name: "No sugar", Frequency: "daily", Kind: "quit"},
+				}, nil
+			},
+			wantErr:    false,
+			wantHabits: 2,
+			wantDue:    13, // six ended days each, plus today for the habit already completed
+		},
+		{
+			name: "repository error",
+			mockGetAll: func() ([]domain.Habit, error) {
+				return nil, errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to get stats",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &usecase.MockHabitRepo{
+				GetAllFn: tt.mockGetAll,
+				GetCompletionsFn: func(id uint) ([]domain.HabitCompletion, error) {
+					if id == 1 {
+						return []domain.HabitCompletion{{HabitID: id, CompletedAt: now}}, nil
+					}
+					return nil, nil
+				},
+			}
+			uc := &usecase.StatsUsecase{HabitRepo: mockRepo}
+
+			stats, err := uc.GetStats(testUserID, []domain.StatsWindow{domain.Window7Days})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, stats.Habits, tt.wantHabits)
+				assert.Equal(t, tt.wantDue, stats.Windows[0].DuePeriods)
+				assert.Equal(t, 1, stats.Windows[0].CompletedPeriods)
+			}
+		})
+	}
+}
+
+func TestGetHabitHeatmap(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       usecase.HeatmapInput
+		mockGetByID func(uint) (*domain.Habit, error)
+		wantErr     bool
+		errContains string
+		wantDays    int
+	}{
+		{
+			name:  "chosen range and timezone",
+			input: usecase.HeatmapInput{From: "2024-01-01", To: "2024-01-31", Timezone: "Asia/Tokyo"},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			wantErr:  false,
+			wantDays: 31,
+		},
+		{
+			name: "defaults to the last year",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			wantErr:  false,
+			wantDays: 365,
+		},
+		{
+			name:  "invalid timezone",
+			input: usecase.HeatmapInput{Timezone: "Mars/Olympus"},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			wantErr:     true,
+			errContains: "invalid timezone",
+		},
+		{
+			name:  "invalid date",
+			input: usecase.HeatmapInput{From: "last year"},
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily"}, nil
+			},
+			wantErr:     true,
+			errContains: "invalid heatmap date",
+		},
+		{
+			name: "quit habit",
+			mockGetByID: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", Kind: "quit"}, nil
+			},
+			wantErr:     true,
+			errContains: "heatmap is not available for quit habits",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &usecase.MockHabitRepo{
+				GetByIDFn: tt.mockGetByID,
+				GetCompletionsFn: func(id uint) ([]domain.HabitCompletion, error) {
+					return []domain.HabitCompletion{{HabitID: id, CompletedAt: time.Date(2024, time.January, 10, 20, 0, 0, 0, time.UTC)}}, nil
+				},
+			}
+			uc := &usecase.StatsUsecase{HabitRepo: mockRepo}
+
+			heatmap, err := uc.GetHabitHeatmap(testUserID, 1, tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, heatmap.Completions, tt.wantDays)
+				if tt.input.Timezone == "Asia/Tokyo" {
+					// 20:00 UTC is the next morning in Tokyo
+					assert.Equal(t, 1, heatmap.Completions[10])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+// streakResetLockKey identifies the streak reset job's advisory lock
+const streakResetLockKey int64 = 710_013
+
+// StreakResetUsecase resets the streaks of habits whose period lapsed without a
+// completion, which are otherwise only recalculated on the next completion. It
+// first completes the running timers that reached their habit's target.
+type StreakResetUsecase struct {
+	HabitRepo    domain.HabitRepository
+	SettingsRepo domain.SettingsRepository
+	SkipRepo     domain.SkipRepository
+	TimerRepo    domain.TimerSessionRepository
+	Lock         domain.JobLock
+}
+
+// StreakResetResult summarises a run of the streak reset job.
+type StreakResetResult struct {
+	Checked int // habits with a streak that were checked
+	Reset   int // habits whose streak had lapsed
+	Timers  int // running timers completed as they reached the target
+}
+
+// Start runs the streak reset job now and then every interval until ctx is cancelled.
+func (usecase *StreakResetUsecase) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := usecase.ResetLapsedStreaks(); err != nil {
+				log.Println("Error resetting lapsed streaks:", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// ResetLapsedStreaks recalculates every habit with a streak and saves the ones
+// whose streak has lapsed. Only one app instance runs it at a time.
+func (usecase *StreakResetUsecase) ResetLapsedStreaks() (*StreakResetResult, error) {
+	result := &StreakResetResult{}
+	reset := func() error { return usecase.resetLapsedStreaks(result) }
+
+	if usecase.Lock == nil {
+		if err := reset(); err != nil {
+			return nil, err
+		}
+	} else {
+		ran, err := usecase.Lock.TryRun(streakResetLockKey, reset)
+		if err != nil {
+			log.Println("Error running streak reset:", err)
+			return nil, fmt.Errorf("failed to reset streaks")
+		}
+		if !ran {
+			return nil, fmt.Errorf("streak reset already running")
+		}
+	}
+
+	log.Printf("Streak reset checked %d habits, reset %d, completed %d timers", result.Checked, result.Reset, result.Timers)
+	return result, nil
+}
+
+func (usecase *StreakResetUsecase) resetLapsedStreaks(result *StreakResetResult) error {
+	if err := usecase.completeReachedTimers(result); err != nil {
+		return err
+	}
+
+	habits, err := usecase.HabitRepo.GetStreaks()
+	if err != nil {
+		log.Println("Error retrieving all habit streaks:", err)
+		return fmt.Errorf("failed to reset streaks")
+	}
+
+	cache := newSettingsCache(usecase.SettingsRepo)
+	now := time.Now()
+	for i := range habits {
+		habit := &habits[i]
+		// Quit habits count days clean when read, so they never lapse
+		if habit.IsQuit() || habit.CurrentStreak == 0 {
+			continue
+		}
+		result.Checked++
+
+		completions, err := usecase.HabitRepo.GetCompletions(habit.ID)
+		if err != nil {
+			log.Printf("Error fetching completion history for habit with ID(%d): %v", habit.ID, err)
+			return fmt.Errorf("failed to reset streaks")
+		}
+
+		settings, err := cache.get(habit.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to reset streaks")
+		}
+		engine, err := newStreakEngine(habit, *settings, usecase.SkipRepo)
+		if err != nil {
+			return fmt.Errorf("failed to reset streaks")
+		}
+		if engine.Calculate(completions, now).CurrentStreak == habit.CurrentStreak {
+			continue
+		}
+
+		recalculateStreak(habit, completions, engine)
+		if err := usecase.HabitRepo.Update(habit); err != nil {
+			log.Printf("Error updating habit with ID(%d): %v", habit.ID, err)
+			return fmt.Errorf("failed to reset streaks")
+		}
+		result.Reset++
+	}
+	return nil
+}
+
+// completeReachedTimers records the running timers that reached their habit's
+// target as completions at the moment they did, as starting, pausing or stopping
+// them would, so the completion counts even if the timer is left running.
+func (usecase *StreakResetUsecase) completeReachedTimers(result *StreakResetResult) error {
+	if usecase.TimerRepo == nil {
+		return nil
+	}
+
+	sessions, err := usecase.TimerRepo.GetRunning()
+	if err != nil {
+		log.Println("Error retrieving running timers:", err)
+		return fmt.Errorf("failed to reset streaks")
+	}
+
+	timers := &HabitUsecase{HabitRepo: usecase.HabitRepo, SettingsRepo: usecase.SettingsRepo, TimerRepo: usecase.TimerRepo, SkipRepo: usecase.SkipRepo}
+	now := time.Now()
+	for i := range sessions {
+		session := &sessions[i]
+		habit, err := usecase.HabitRepo.GetByID(session.HabitID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				continue
+			}
+			log.Printf("Error retrieving habit with ID(%d): %v", session.HabitID, err)
+			return fmt.Errorf("failed to reset streaks")
+		}
+
+		reachedAt, reached, err := timers.timerTargetReachedAt(habit, session, now)
+		if err != nil {
+			return fmt.Errorf("failed to reset streaks")
+		}
+		if !reached {
+			continue
+		}
+		if err := timers.completeTimer(habit, session, reachedAt); err != nil {
+			return fmt.Errorf("failed to reset streaks")
+		}
+		result.Timers++
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResetLapsedStreaks(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		mockGetStreaks func() ([]domain.Habit, error)
+		lockHeld       bool
+		wantErr        bool
+		errContains    string
+		wantChecked    int
+		wantReset      int
+		wantUpdated    []uint
+	}{
+		{
+			name: "lapsed streak reset",
+			mockGetStreaks: func() ([]domain.Habit, error) {
+				return []domain.Habit{
+					{ID: 1, Frequency: "daily", CurrentStreak: 40},
+					{ID: 2, Frequency: "daily", CurrentStreak: 1},
+					{ID: 3, Frequency: "daily", Kind: "quit", CurrentStreak: 12},
+				}, nil
+			},
+			wantErr:     false,
+			wantChecked: 2,
+			wantReset:   1,
+			wantUpdated: []uint{1},
+		},
+		{
+			name:        "lock held by another instance",
+			lockHeld:    true,
+			wantErr:     true,
+			errContains: "streak reset already running",
+		},
+		{
+			name: "repository error",
+			mockGetStreaks: func() ([]domain.Habit, error) {
+				return nil, errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to reset streaks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated []uint
+			mockRepo := &usecase.MockHabitRepo{
+				GetStreaksFn: tt.mockGetStreaks,
+				GetCompletionsFn: func(id uint) ([]domain.HabitCompletion, error) {
+					// Habit 1 was abandoned weeks ago, habit 2 was completed today
+					if id == 1 {
+						return []domain.HabitCompletion{{HabitID: id, CompletedAt: today.AddDate(0, 0, -21)}}, nil
+					}
+					return []domain.HabitCompletion{{HabitID: id, CompletedAt: today}}, nil
+				},
+				UpdateFn: func(h *domain.Habit) error {
+					assert.Equal(t, 0, h.CurrentStreak)
+					updated = append(updated, h.ID)
+					return nil
+				},
+			}
+			lock := &usecase.MockJobLock{}
+			if tt.lockHeld {
+				lock.TryRunFn = func(key int64, fn func() error) (bool, error) { return false, nil }
+			}
+			uc := &usecase.StreakResetUsecase{HabitRepo: mockRepo, Lock: lock}
+
+			result, err := uc.ResetLapsedStreaks()
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantChecked, result.Checked)
+				assert.Equal(t, tt.wantReset, result.Reset)
+				assert.Equal(t, tt.wantUpdated, updated)
+			}
+		})
+	}
+}
+
+func TestResetLapsedStreaksCompletesReachedTimers(t *testing.T) {
+	now := time.Now()
+	startedAt := now.Add(-time.Hour)
+	sessions := []domain.TimerSession{
+		{ID: 1, HabitID: 1, Status: domain.TimerRunning, StartedAt: startedAt, ResumedAt: &startedAt},
+		{ID: 2, HabitID: 2, Status: domain.TimerRunning, StartedAt: now, ResumedAt: &now},
+	}
+
+	var completions []*domain.HabitCompletion
+	uc := &usecase.StreakResetUsecase{
+		HabitRepo: &usecase.MockHabitRepo{
+			GetByIDFn: func(id uint) (*domain.Habit, error) {
+				return &domain.Habit{ID: id, Frequency: "daily", TargetValue: 20, Unit: "minutes"}, nil
+			},
+			GetStreaksFn: func() ([]domain.Habit, error) { return nil, nil },
+		},
+		TimerRepo: &usecase.MockTimerRepo{
+			GetRunningFn: func() ([]domain.TimerSession, error) { return sessions, nil },
+			CompleteFn: func(s *domain.TimerSession, h *domain.Habit, c *domain.HabitCompletion) error {
+				completions = append(completions, c)
+				return nil
+			},
+		},
+	}
+
+	result, err := uc.ResetLapsedStreaks()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Timers)
+
+	// Only the timer that ran for an hour reached the 20 minute target, it is
+	// recorded when it did rather than now
+	assert.Len(t, completions, 1)
+	assert.Equal(t, uint(1), completions[0].HabitID)
+	assert.Equal(t, 20.0, completions[0].Quantity)
+	assert.WithinDuration(t, startedAt.Add(20*time.Minute), completions[0].CompletedAt, time.Second)
+}
@@ -0,0 +1,295 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultTOTPIssuer names the account in authenticator apps when Issuer is not set
+	defaultTOTPIssuer = "Habit Tracker"
+	// challengeTTL is how long a user has to enter their code after the password
+	challengeTTL = 5 * time.Minute
+	// maxChallengeAttempts is how many wrong codes a login challenge takes
+	// before the password has to be entered again
+	maxChallengeAttempts = 5
+)
+
+// TwoFactorUsecase handles optional TOTP two-factor authentication: enrolling
+// an authenticator app, the second login step and recovery codes.
+type TwoFactorUsecase struct {
+	UserRepo      domain.UserRepository
+	TwoFactorRepo domain.TwoFactorRepository
+	SessionRepo   domain.SessionRepository
+	Issuer        string
+}
+
+// TOTPEnrolment is the secret to add to an authenticator app, as is and as an
+// otpauth URI for a QR code.
+type TOTPEnrolment struct {
+	Secret string
+	URI    string
+}
+
+// TwoFactorChallenge is returned instead of a session when the password was
+// right but the user has 2FA enabled. ChallengeToken is sent back with a code.
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool
+	ChallengeToken    string
+	ExpiresAt         time.Time
+}
+
+type TOTPCodeInput struct {
+	Code string `json:"code"`
+}
+
+// ChallengeInput answers a login challenge with a code from the authenticator
+// app or a recovery code.
+type ChallengeInput struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// Enroll starts enrolling the user with a new secret. 2FA is not enforced until
+// the user confirms they can generate codes for it, see Confirm.
+func (usecase *TwoFactorUsecase) Enroll(userID uint) (*TOTPEnrolment, error) {
+	user, err := usecase.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	secret, err := domain.NewTOTPSecret()
+	if err != nil {
+		log.Println("Error generating totp secret:", err)
+		return nil, fmt.Errorf("failed to enroll in two-factor authentication")
+	}
+
+	if err := usecase.TwoFactorRepo.SetPendingSecret(userID, secret); err != nil {
+		log.Printf("Error storing totp secret of user with ID(%d): %v", userID, err)
+		return nil, fmt.Errorf("failed to enroll in two-factor authentication")
+	}
+
+	return &TOTPEnrolment{Secret: secret, URI: domain.TOTPURI(usecase.issuer(), user.Email, secret)}, nil
+}
+
+// Confirm checks a code from the newly enrolled app and turns 2FA on. The
+// user's other sessions, which were started with the password alone, are
+// revoked and sessionID is kept. It returns the recovery codes, which are only
+// stored hashed, so this is the only time the user sees them.
+func (usecase *TwoFactorUsecase) Confirm(userID, sessionID uint, input TOTPCodeInput) ([]string, error) {
+	user, err := usecase.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, fmt.Errorf("two-factor enrolment has not been started")
+	}
+
+	counter, ok := domain.MatchTOTP(user.TOTPSecret, input.Code, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("invalid code")
+	}
+
+	codes := make([]string, domain.RecoveryCodeCount)
+	stored := make([]domain.RecoveryCode, domain.RecoveryCodeCount)
+	for i := range codes {
+		code, hash, err := domain.NewRecoveryCode()
+		if err != nil {
+			log.Println("Error generating recovery code:", err)
+			return nil, fmt.Errorf("failed to enable two-factor authentication")
+		}
+		codes[i] = code
+		stored[i] = domain.RecoveryCode{UserID: userID, CodeHash: hash}
+	}
+
+	// Enable first, so a failure never leaves the user logged out with 2FA still off
+	if err := usecase.TwoFactorRepo.Enable(userID, counter, stored); err != nil {
+		log.Printf("Error enabling two-factor authentication for user with ID(%d): %v", userID, err)
+		return nil, fmt.Errorf("failed to enable two-factor authentication")
+	}
+
+	if err := usecase.SessionRepo.DeleteForUser(userID, sessionID); err != nil {
+		log.Printf("Error deleting sessions of user with ID(%d): %v", userID, err)
+		return nil, fmt.Errorf("failed to enable two-factor authentication")
+	}
+
+	log.Printf("Two-factor authentication enabled for user with ID(%d)", userID)
+	return codes, nil
+}
+
+// StartChallenge starts the second login step for a user with 2FA enabled.
+// Expired challenges are cleaned up on the way.
+func (usecase *TwoFactorUsecase) StartChallenge(user *domain.User) (*TwoFactorChallenge, error) {
+	now := time.Now()
+	if err := usecase.TwoFactorRepo.DeleteExpiredChallenges(now); err != nil {
+		log.Println("Error deleting expired login challenges:", err)
+	}
+
+	token, hash, err := domain.NewToken()
+	if err != nil {
+		log.Println("Error generating login challenge token:", err)
+		return nil, fmt.Errorf("failed to start login challenge")
+	}
+
+	challenge := &domain.LoginChallenge{UserID: user.ID, TokenHash: hash, ExpiresAt: now.Add(challengeTTL)}
+	if err := usecase.TwoFactorRepo.CreateChallenge(challenge); err != nil {
+		log.Println("Error creating login challenge:", err)
+		return nil, fmt.Errorf("failed to start login challenge")
+	}
+
+	return &TwoFactorChallenge{TwoFactorRequired: true, ChallengeToken: token, ExpiresAt: challenge.ExpiresAt}, nil
+}
+
+// VerifyChallenge answers a login challenge and returns the user if the code is
+// right. A recovery code works once only, as does each TOTP code.
+func (usecase *TwoFactorUsecase) VerifyChallenge(input ChallengeInput) (*domain.User, error) {
+	challenge, err := usecase.TwoFactorRepo.GetChallengeByTokenHash(domain.HashToken(input.ChallengeToken))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("invalid or expired challenge")
+		}
+		log.Println("Error retrieving login challenge:", err)
+		return nil, fmt.Errorf("failed to verify code")
+	}
+
+	if challenge.IsExpired(time.Now()) || challenge.Attempts >= maxChallengeAttempts {
+		if err := usecase.TwoFactorRepo.DeleteChallenge(challenge.ID); err != nil {
+			log.Printf("Error deleting login challenge with ID(%d): %v", challenge.ID, err)
+		}
+		return nil, fmt.Errorf("invalid or expired challenge")
+	}
+
+	user, err := usecase.getUser(challenge.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, fmt.Errorf("invalid or expired challenge")
+		}
+		return nil, fmt.Errorf("failed to verify code")
+	}
+
+	ok, err := usecase.checkCode(user, input.Code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify code")
+	}
+	if !ok {
+		if err := usecase.TwoFactorRepo.AddChallengeAttempt(challenge.ID); err != nil {
+			log.Printf("Error counting attempt on login challenge with ID(%d): %v", challenge.ID, err)
+		}
+		return nil, fmt.Errorf("invalid code")
+	}
+
+	if err := usecase.TwoFactorRepo.DeleteChallenge(challenge.ID); err != nil {
+		log.Printf("Error deleting login challenge with ID(%d): %v", challenge.ID, err)
+	}
+	return user, nil
+}
+
+// Disable turns 2FA off for the user, who proves they still have their
+// authenticator with a current code. As with Confirm, the user's other
+// sessions are revoked and sessionID is kept.
+func (usecase *TwoFactorUsecase) Disable(userID, sessionID uint, input TOTPCodeInput) error {
+	user, err := usecase.getUser(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	counter, ok := domain.MatchTOTP(user.TOTPSecret, input.Code, time.Now())
+	if !ok {
+		return fmt.Errorf("invalid code")
+	}
+	fresh, err := usecase.TwoFactorRepo.UseCounter(userID, counter)
+	if err != nil {
+		log.Printf("Error recording totp use of user with ID(%d): %v", userID, err)
+		return fmt.Errorf("failed to disable two-factor authentication")
+	}
+	if !fresh {
+		return fmt.Errorf("invalid code")
+	}
+
+	if err := usecase.TwoFactorRepo.Reset(userID); err != nil {
+		log.Printf("Error disabling two-factor authentication for user with ID(%d): %v", userID, err)
+		return fmt.Errorf("failed to disable two-factor authentication")
+	}
+
+	if err := usecase.SessionRepo.DeleteForUser(userID, sessionID); err != nil {
+		log.Printf("Error deleting sessions of user with ID(%d): %v", userID, err)
+		return fmt.Errorf("failed to disable two-factor authentication")
+	}
+
+	log.Printf("Two-factor authentication disabled by user with ID(%d)", userID)
+	return nil
+}
+
+// Reset turns 2FA off for a user who lost their authenticator and recovery
+// codes, and revokes their sessions. Only admins may do this.
+func (usecase *TwoFactorUsecase) Reset(adminID, userID uint) error {
+	if _, err := usecase.getUser(userID); err != nil {
+		return err
+	}
+
+	if err := usecase.TwoFactorRepo.Reset(userID); err != nil {
+		log.Printf("Error resetting two-factor authentication for user with ID(%d): %v", userID, err)
+		return fmt.Errorf("failed to reset two-factor authentication")
+	}
+
+	if err := usecase.SessionRepo.DeleteForUser(userID, 0); err != nil {
+		log.Printf("Error deleting sessions of user with ID(%d): %v", userID, err)
+		return fmt.Errorf("failed to reset two-factor authentication")
+	}
+
+	log.Printf("Two-factor authentication of user with ID(%d) reset by admin with ID(%d)", userID, adminID)
+	return nil
+}
+
+// checkCode accepts a current TOTP code or one of the user's recovery codes
+func (usecase *TwoFactorUsecase) checkCode(user *domain.User, code string) (bool, error) {
+	if counter, ok := domain.MatchTOTP(user.TOTPSecret, code, time.Now()); ok {
+		fresh, err := usecase.TwoFactorRepo.UseCounter(user.ID, counter)
+		if err != nil {
+			log.Printf("Error recording totp use of user with ID(%d): %v", user.ID, err)
+		}
+		return fresh, err
+	}
+
+	used, err := usecase.TwoFactorRepo.UseRecoveryCode(user.ID, domain.HashRecoveryCode(code))
+	if err != nil {
+		log.Printf("Error using recovery code of user with ID(%d): %v", user.ID, err)
+		return false, err
+	}
+	if used {
+		log.Printf("Recovery code used by user with ID(%d)", user.ID)
+	}
+	return used, nil
+}
+
+func (usecase *TwoFactorUsecase) getUser(id uint) (*domain.User, error) {
+	user, err := usecase.UserRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("user not found")
+		}
+		log.Printf("Error retrieving user with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to retrieve user")
+	}
+	return user, nil
+}
+
+func (usecase *TwoFactorUsecase) issuer() string {
+	if usecase.Issuer == "" {
+		return defaultTOTPIssuer
+	}
+	return usecase.Issuer
+}
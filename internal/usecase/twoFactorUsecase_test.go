@@ -0,0 +1,365 @@
+package usecase_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func currentTOTPCode(t *testing.T) (string, int64) {
+	counter := domain.TOTPCounter(time.Now())
+	code, err := domain.TOTPCode(testTOTPSecret, counter)
+	assert.NoError(t, err)
+	return code, counter
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "alice@example.com"}
+	var stored string
+	uc := &usecase.TwoFactorUsecase{
+		UserRepo: &usecase.MockUserRepo{GetByIDFn: func(id uint) (*domain.User, error) { return user, nil }},
+		TwoFactorRepo: &usecase.MockTwoFactorRepo{
+			SetPendingSecretFn: func(userID uint, secret string) error {
+				stored = secret
+				return nil
+			},
+		},
+	}
+
+	enrolment, err := uc.Enroll(1)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, enrolment.Secret)
+	assert.True(t, strings.HasPrefix(enrolment.URI, "otpauth://totp/Habit%20Tracker:alice@example.com?"))
+	assert.Contains(t, enrolment.URI, "secret="+stored)
+
+	user.TOTPEnabled = true
+	_, err = uc.Enroll(1)
+	assert.ErrorContains(t, err, "already enabled")
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	code, counter := currentTOTPCode(t)
+
+	tests := []struct {
+		name        string
+		user        *domain.User
+		code        string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "valid code",
+			user: &domain.User{ID: 1, TOTPSecret: testTOTPSecret},
+			code: code,
+		},
+		{
+			name:        "wrong code",
+			user:        &domain.User{ID: 1, TOTPSecret: testTOTPSecret},
+			code:        "000000",
+			wantErr:     true,
+			errContains: "invalid code",
+		},
+		{
+			name:        "not enrolled",
+			user:        &domain.User{ID: 1},
+			code:        code,
+			wantErr:     true,
+			errContains: "has not been started",
+		},
+		{
+			name:        "already enabled",
+			user:        &domain.User{ID: 1, TOTPSecret: testTOTPSecret, TOTPEnabled: true},
+			code:        code,
+			wantErr:     true,
+			errContains: "already enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored []domain.RecoveryCode
+			enabled := false
+			var kept *uint
+			uc := &usecase.TwoFactorUsecase{
+				UserRepo: &usecase.MockUserRepo{GetByIDFn: func(id uint) (*domain.User, error) { return tt.user, nil }},
+				TwoFactorRepo: &usecase.MockTwoFactorRepo{
+					EnableFn: func(userID uint, c int64, codes []domain.RecoveryCode) error {
+						assert.Equal(t, counter, c)
+						assert.Nil(t, kept, "sessions are revoked once 2FA is on")
+						stored = codes
+						enabled = true
+						return nil
+					},
+				},
+				SessionRepo: &usecase.MockSessionRepo{
+					DeleteForUserFn: func(userID, exceptID uint) error {
+						assert.Equal(t, uint(1), userID)
+						assert.True(t, enabled, "sessions are revoked once 2FA is on")
+						kept = &exceptID
+						return nil
+					},
+				},
+			}
+
+			codes, err := uc.Confirm(1, 7, usecase.TOTPCodeInput{Code: tt.code})
+
+			if tt.wantErr {
+				assert.ErrorContains(t, err, tt.errContains)
+				assert.False(t, enabled)
+				assert.Nil(t, kept)
+				return
+			}
+			assert.NoError(t, err)
+			// Sessions started with the password alone are revoked, bar the current one
+			assert.Equal(t, uint(7), *kept)
+			assert.Len(t, codes, domain.RecoveryCodeCount)
+			assert.Len(t, stored, domain.RecoveryCodeCount)
+			for i, code := range codes {
+				assert.Equal(t, domain.HashRecoveryCode(code), stored[i].CodeHash, "only hashes are stored")
+			}
+		})
+	}
+}
+
+func TestVerifyChallenge(t *testing.T) {
+	code, counter := currentTOTPCode(t)
+	user := &domain.User{ID: 1, TOTPSecret: testTOTPSecret, TOTPEnabled: true}
+	valid := func(hash string) (*domain.LoginChallenge, error) {
+		assert.Equal(t, domain.HashToken("challenge"), hash)
+		return &domain.LoginChallenge{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(time.Minute)}, nil
+	}
+
+	tests := []struct {
+		name             string
+		code             string
+		mockGetChallenge func(string) (*domain.LoginChallenge, error)
+		mockUseCounter   func(uint, int64) (bool, error)
+		mockUseRecovery  func(uint, string) (bool, error)
+		wantAttempt      bool
+		wantDeleted      bool
+		errContains      string
+	}{
+		{
+			name:             "totp code",
+			code:             code,
+			mockGetChallenge: valid,
+			mockUseCounter: func(userID uint, c int64) (bool, error) {
+				assert.Equal(t, counter, c)
+				return true, nil
+			},
+			wantDeleted: true,
+		},
+		{
+			name:             "replayed totp code",
+			code:             code,
+			mockGetChallenge: valid,
+			mockUseCounter:   func(uint, int64) (bool, error) { return false, nil },
+			wantAttempt:      true,
+			errContains:      "invalid code",
+		},
+		{
+			name:             "recovery code",
+			code:             "ABCDE-fghij",
+			mockGetChallenge: valid,
+			mockUseRecovery: func(userID uint, hash string) (bool, error) {
+				assert.Equal(t, domain.HashRecoveryCode("abcdefghij"), hash)
+				return true, nil
+			},
+			wantDeleted: true,
+		},
+		{
+			name:             "wrong code counts an attempt",
+			code:             "000000",
+			mockGetChallenge: valid,
+			wantAttempt:      true,
+			errContains:      "invalid code",
+		},
+		{
+			name: "too many attempts",
+			code: code,
+			mockGetChallenge: func(string) (*domain.LoginChallenge, error) {
+				return &domain.LoginChallenge{ID: 5, UserID: 1, Attempts: 5, ExpiresAt: time.Now().Add(time.Minute)}, nil
+			},
+			wantDeleted: true,
+			errContains: "invalid or expired challenge",
+		},
+		{
+			name: "expired challenge",
+			code: code,
+			mockGetChallenge: func(string) (*domain.LoginChallenge, error) {
+				return &domain.LoginChallenge{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil
+			},
+			wantDeleted: true,
+			errContains: "invalid or expired challenge",
+		},
+		{
+			name:             "unknown challenge",
+			code:             code,
+			mockGetChallenge: func(string) (*domain.LoginChallenge, error) { return nil, gorm.ErrRecordNotFound },
+			errContains:      "invalid or expired challenge",
+		},
+		{
+			name:             "repository error",
+			code:             code,
+			mockGetChallenge: func(string) (*domain.LoginChallenge, error) { return nil, errors.New("db error") },
+			errContains:      "failed to verify code",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempted, deleted := false, false
+			uc := &usecase.TwoFactorUsecase{
+				UserRepo: &usecase.MockUserRepo{GetByIDFn: func(id uint) (*domain.User, error) { return user, nil }},
+				TwoFactorRepo: &usecase.MockTwoFactorRepo{
+					GetChallengeByTokenHashFn: tt.mockGetChallenge,
+					UseCounterFn:              tt.mockUseCounter,
+					UseRecoveryCodeFn:         tt.mockUseRecovery,
+					AddChallengeAttemptFn: func(id uint) error {
+						attempted = true
+						return nil
+					},
+					DeleteChallengeFn: func(id uint) error {
+						deleted = true
+						return nil
+					},
+				},
+			}
+
+			got, err := uc.VerifyChallenge(usecase.ChallengeInput{ChallengeToken: "challenge", Code: tt.code})
+
+			assert.Equal(t, tt.wantAttempt, attempted)
+			assert.Equal(t, tt.wantDeleted, deleted)
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, user, got)
+		})
+	}
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	code, counter := currentTOTPCode(t)
+
+	tests := []struct {
+		name        string
+		user        *domain.User
+		code        string
+		used        bool
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "current code",
+			user: &domain.User{ID: 1, TOTPSecret: testTOTPSecret, TOTPEnabled: true},
+			code: code,
+		},
+		{
+			name:        "wrong code",
+			user:        &domain.User{ID: 1, TOTPSecret: testTOTPSecret, TOTPEnabled: true},
+			code:        "000000",
+			wantErr:     true,
+			errContains: "invalid code",
+		},
+		{
+			name:        "replayed code",
+			user:        &domain.User{ID: 1, TOTPSecret: testTOTPSecret, TOTPEnabled: true},
+			code:        code,
+			used:        true,
+			wantErr:     true,
+			errContains: "invalid code",
+		},
+		{
+			name:        "not enabled",
+			user:        &domain.User{ID: 1, TOTPSecret: testTOTPSecret},
+			code:        code,
+			wantErr:     true,
+			errContains: "not enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disabled := false
+			var kept *uint
+			uc := &usecase.TwoFactorUsecase{
+				UserRepo: &usecase.MockUserRepo{GetByIDFn: func(id uint) (*domain.User, error) { return tt.user, nil }},
+				TwoFactorRepo: &usecase.MockTwoFactorRepo{
+					UseCounterFn: func(userID uint, c int64) (bool, error) {
+						assert.Equal(t, counter, c)
+						return !tt.used, nil
+					},
+					ResetFn: func(userID uint) error {
+						disabled = true
+						return nil
+					},
+				},
+				SessionRepo: &usecase.MockSessionRepo{
+					DeleteForUserFn: func(userID, exceptID uint) error {
+						assert.Equal(t, uint(1), userID)
+						assert.True(t, disabled, "sessions are revoked once 2FA is off")
+						kept = &exceptID
+						return nil
+					},
+				},
+			}
+
+			err := uc.Disable(1, 7, usecase.TOTPCodeInput{Code: tt.code})
+
+			if tt.wantErr {
+				assert.ErrorContains(t, err, tt.errContains)
+				assert.False(t, disabled)
+				assert.Nil(t, kept)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, disabled)
+			// The user's other sessions are revoked, bar the current one
+			assert.Equal(t, uint(7), *kept)
+		})
+	}
+}
+
+func TestResetTwoFactor(t *testing.T) {
+	reset := uint(0)
+	revoked := uint(0)
+	uc := &usecase.TwoFactorUsecase{
+		UserRepo: &usecase.MockUserRepo{
+			GetByIDFn: func(id uint) (*domain.User, error) {
+				if id != 2 {
+					return nil, gorm.ErrRecordNotFound
+				}
+				return &domain.User{ID: 2, TOTPEnabled: true}, nil
+			},
+		},
+		TwoFactorRepo: &usecase.MockTwoFactorRepo{
+			ResetFn: func(userID uint) error {
+				reset = userID
+				return nil
+			},
+		},
+		SessionRepo: &usecase.MockSessionRepo{
+			DeleteForUserFn: func(userID, exceptID uint) error {
+				assert.Equal(t, uint(0), exceptID)
+				revoked = userID
+				return nil
+			},
+		},
+	}
+
+	assert.NoError(t, uc.Reset(1, 2))
+	assert.Equal(t, uint(2), reset)
+	assert.Equal(t, uint(2), revoked)
+
+	assert.ErrorContains(t, uc.Reset(1, 9), "user not found")
+}
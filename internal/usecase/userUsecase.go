@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// dummyPasswordHash is compared against when logging in with an unknown email,
+// so the response time does not reveal which emails have an account.
+const dummyPasswordHash = "$2a$10$oK97SZTD8HXiacaDEhSmQu.ZcdWnkrPB86.rIcWVs01L6FtWdDD7G"
+
+// defaultSessionTTL is how long a session lasts when SessionTTL is not set
+const defaultSessionTTL = 24 * time.Hour
+
+type UserUsecase struct {
+	UserRepo    domain.UserRepository
+	SessionRepo domain.SessionRepository
+	SessionTTL  time.Duration
+	AdminEmail  string // normalised email of the account that becomes admin, none if empty
+}
+
+// SessionToken is the token of a new session. The token itself is not stored,
+// so this is the only time it is available.
+type SessionToken struct {
+	Token     string
+	ExpiresAt time.Time
+	User      *domain.User
+}
+
+// CredentialsInput is the email and password used to register or log in.
+type CredentialsInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Register creates an account, storing only a bcrypt hash of the password.
+func (usecase *UserUsecase) Register(input CredentialsInput) (*domain.User, error) {
+	email, err := domain.NormalizeEmail(input.Email)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.ValidatePassword(input.Password); err != nil {
+		return nil, err
+	}
+
+	if _, err := usecase.UserRepo.GetByEmail(email); err == nil {
+		return nil, fmt.Errorf("email is already registered")
+	} else if err != gorm.ErrRecordNotFound {
+		log.Println("Error checking for existing user:", err)
+		return nil, fmt.Errorf("failed to register user")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("Error hashing password:", err)
+		return nil, fmt.Errorf("failed to register user")
+	}
+
+	user := &domain.User{Email: email, PasswordHash: string(hash), IsAdmin: isAdminEmail(usecase.AdminEmail, email)}
+	if err := usecase.UserRepo.Create(user); err != nil {
+		log.Println("Error creating user:", err)
+		return nil, fmt.Errorf("failed to register user")
+	}
+
+	log.Printf("User registered with ID(%d)", user.ID)
+	return user, nil
+}
+
+// BootstrapAdmin promotes the existing account with AdminEmail to admin. An
+// account registered with it later is made admin when it is created.
+func (usecase *UserUsecase) BootstrapAdmin() error {
+	if usecase.AdminEmail == "" {
+		return nil
+	}
+
+	user, err := usecase.UserRepo.GetByEmail(usecase.AdminEmail)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			log.Printf("Admin account %s will be made admin when it registers", usecase.AdminEmail)
+			return nil
+		}
+		log.Println("Error retrieving admin account:", err)
+		return fmt.Errorf("failed to bootstrap admin")
+	}
+	if user.IsAdmin {
+		return nil
+	}
+
+	if err := usecase.UserRepo.Promote(user.ID); err != nil {
+		log.Printf("Error promoting user with ID(%d) to admin: %v", user.ID, err)
+		return fmt.Errorf("failed to bootstrap admin")
+	}
+	log.Printf("User with ID(%d) promoted to admin", user.ID)
+	return nil
+}
+
+// isAdminEmail reports whether email is the configured admin email. Emails are
+// unique, so only one account can ever match.
+func isAdminEmail(adminEmail, email string) bool {
+	return adminEmail != "" && email == adminEmail
+}
+
+// Login returns the user with the given email if the password matches. Unknown
+// emails and wrong passwords give the same error.
+func (usecase *UserUsecase) Login(input CredentialsInput) (*domain.User, error) {
+	email, err := domain.NormalizeEmail(input.Email)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email or password")
+	}
+
+	user, err := usecase.UserRepo.GetByEmail(email)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Println("Error retrieving user for login:", err)
+			return nil, fmt.Errorf("failed to log in")
+		}
+		bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(input.Password))
+		return nil, fmt.Errorf("invalid email or password")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
+		return nil, fmt.Errorf("invalid email or password")
+	}
+	return user, nil
+}
+
+func (usecase *UserUsecase) GetUser(id uint) (*domain.User, error) {
+	user, err := usecase.UserRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("user not found")
+		}
+		log.Printf("Error retrieving user with ID(%d): %v", id, err)
+		return nil, fmt.Errorf("failed to retrieve user")
+	}
+	return user, nil
+}
+
+// StartSession logs the user in with a new session, valid for SessionTTL.
+// Expired sessions are cleaned up on the way.
+func (usecase *UserUsecase) StartSession(user *domain.User) (*SessionToken, error) {
+	now := time.Now()
+	if err := usecase.SessionRepo.DeleteExpired(now); err != nil {
+		log.Println("Error deleting expired sessions:", err)
+	}
+
+	token, hash, err := domain.NewToken()
+	if err != nil {
+		log.Println("Error generating session token:", err)
+		return nil, fmt.Errorf("failed to start session")
+	}
+
+	session := &domain.Session{UserID: user.ID, TokenHash: hash, ExpiresAt: now.Add(usecase.sessionTTL())}
+	if err := usecase.SessionRepo.Create(session); err != nil {
+		log.Println("Error creating session:", err)
+		return nil, fmt.Errorf("failed to start session")
+	}
+
+	return &SessionToken{Token: token, ExpiresAt: session.ExpiresAt, User: user}, nil
+}
+
+// Authenticate returns the session with the given token and its user, as long
+// as it has not expired or been revoked.
+func (usecase *UserUsecase) Authenticate(token string) (*domain.User, *domain.Session, error) {
+	session, err := usecase.SessionRepo.GetByTokenHash(domain.HashToken(token))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, fmt.Errorf("invalid or expired session")
+		}
+		log.Println("Error retrieving session:", err)
+		return nil, nil, fmt.Errorf("failed to authenticate")
+	}
+
+	if session.IsExpired(time.Now()) {
+		if err := usecase.SessionRepo.Delete(session.ID); err != nil {
+			log.Printf("Error deleting expired session with ID(%d): %v", session.ID, err)
+		}
+		return nil, nil, fmt.Errorf("invalid or expired session")
+	}
+
+	user, err := usecase.GetUser(session.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, nil, fmt.Errorf("invalid or expired session")
+		}
+		return nil, nil, fmt.Errorf("failed to authenticate")
+	}
+	return user, session, nil
+}
+
+// RefreshSession replaces a session that has not expired yet with a new one, so
+// an active client can stay logged in without its token living forever.
+func (usecase *UserUsecase) RefreshSession(userID, sessionID uint) (*SessionToken, error) {
+	user, err := usecase.GetUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh session")
+	}
+
+	token, err := usecase.StartSession(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh session")
+	}
+
+	if err := usecase.Logout(sessionID); err != nil {
+		return nil, fmt.Errorf("failed to refresh session")
+	}
+	return token, nil
+}
+
+// Logout revokes a single session.
+func (usecase *UserUsecase) Logout(sessionID uint) error {
+	if err := usecase.SessionRepo.Delete(sessionID); err != nil {
+		log.Printf("Error deleting session with ID(%d): %v", sessionID, err)
+		return fmt.Errorf("failed to log out")
+	}
+	return nil
+}
+
+// LogoutAll revokes every session of the user, e.g. after a lost device.
+func (usecase *UserUsecase) LogoutAll(userID uint) error {
+	if err := usecase.SessionRepo.DeleteForUser(userID, 0); err != nil {
+		log.Printf("Error deleting sessions of user with ID(%d): %v", userID, err)
+		return fmt.Errorf("failed to log out")
+	}
+	return nil
+}
+
+func (usecase *UserUsecase) sessionTTL() time.Duration {
+	if usecase.SessionTTL <= 0 {
+		return defaultSessionTTL
+	}
+	return usecase.SessionTTL
+}
@@ -0,0 +1,343 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/internal/domain"
+	"github.com/jt00721/habit-tracker/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          usecase.CredentialsInput
+		mockGetByEmail func(string) (*domain.User, error)
+		mockCreate     func(*domain.User) error
+		wantErr        bool
+		errContains    string
+	}{
+		{
+			name:  "new account",
+			input: usecase.CredentialsInput{Email: " Alice@Example.com", Password: "correct horse"},
+			mockGetByEmail: func(email string) (*domain.User, error) {
+				assert.Equal(t, "alice@example.com", email)
+				return nil, gorm.ErrRecordNotFound
+			},
+			mockCreate: func(u *domain.User) error {
+				assert.Equal(t, "alice@example.com", u.Email)
+				assert.NotEqual(t, "correct horse", u.PasswordHash)
+				assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("correct horse")))
+				u.ID = 1
+				return nil
+			},
+			wantErr: false,
+		},
+		{
+			name:        "invalid email",
+			input:       usecase.CredentialsInput{Email: "alice", Password: "correct horse"},
+			wantErr:     true,
+			errContains: "invalid email address",
+		},
+		{
+			name:        "short password",
+			input:       usecase.CredentialsInput{Email: "alice@example.com", Password: "hunter2"},
+			wantErr:     true,
+			errContains: "password must be at least",
+		},
+		{
+			name:  "email already registered",
+			input: usecase.CredentialsInput{Email: "alice@example.com", Password: "correct horse"},
+			mockGetByEmail: func(email string) (*domain.User, error) {
+				return &domain.User{ID: 1, Email: email}, nil
+			},
+			wantErr:     true,
+			errContains: "email is already registered",
+		},
+		{
+			name:  "create fails",
+			input: usecase.CredentialsInput{Email: "alice@example.com", Password: "correct horse"},
+			mockGetByEmail: func(email string) (*domain.User, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			mockCreate: func(u *domain.User) error {
+				return errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to register user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &usecase.UserUsecase{UserRepo: &usecase.MockUserRepo{GetByEmailFn: tt.mockGetByEmail, CreateFn: tt.mockCreate}}
+
+			user, err := uc.Register(tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), user.ID)
+			}
+		})
+	}
+}
+
+func TestAdminBootstrap(t *testing.T) {
+	t.Run("only the configured email registers as admin", func(t *testing.T) {
+		var created []*domain.User
+		uc := &usecase.UserUsecase{
+			AdminEmail: "admin@example.com",
+			UserRepo: &usecase.MockUserRepo{
+				GetByEmailFn: func(string) (*domain.User, error) { return nil, gorm.ErrRecordNotFound },
+				CreateFn: func(u *domain.User) error {
+					created = append(created, u)
+					return nil
+				},
+			},
+		}
+
+		_, err := uc.Register(usecase.CredentialsInput{Email: "first@example.com", Password: "correct horse"})
+		assert.NoError(t, err)
+		_, err = uc.Register(usecase.CredentialsInput{Email: "Admin@Example.com", Password: "correct horse"})
+		assert.NoError(t, err)
+
+		assert.False(t, created[0].IsAdmin, "registering first does not make an admin")
+		assert.True(t, created[1].IsAdmin)
+	})
+
+	t.Run("existing account is promoted", func(t *testing.T) {
+		promoted := uint(0)
+		users := map[string]*domain.User{
+			"admin@example.com": {ID: 4, Email: "admin@example.com"},
+			"root@example.com":  {ID: 5, Email: "root@example.com", IsAdmin: true},
+		}
+		repo := &usecase.MockUserRepo{
+			GetByEmailFn: func(email string) (*domain.User, error) {
+				if user, ok := users[email]; ok {
+					return user, nil
+				}
+				return nil, gorm.ErrRecordNotFound
+			},
+			PromoteFn: func(id uint) error {
+				promoted = id
+				return nil
+			},
+		}
+
+		assert.NoError(t, (&usecase.UserUsecase{UserRepo: repo}).BootstrapAdmin())
+		assert.NoError(t, (&usecase.UserUsecase{UserRepo: repo, AdminEmail: "nobody@example.com"}).BootstrapAdmin())
+		assert.NoError(t, (&usecase.UserUsecase{UserRepo: repo, AdminEmail: "root@example.com"}).BootstrapAdmin())
+		assert.Zero(t, promoted)
+
+		assert.NoError(t, (&usecase.UserUsecase{UserRepo: repo, AdminEmail: "admin@example.com"}).BootstrapAdmin())
+		assert.Equal(t, uint(4), promoted)
+	})
+}
+
+func TestLogin(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	getByEmail := func(email string) (*domain.User, error) {
+		if email != "alice@example.com" {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return &domain.User{ID: 1, Email: email, PasswordHash: string(hash)}, nil
+	}
+
+	tests := []struct {
+		name        string
+		input       usecase.CredentialsInput
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:    "correct password",
+			input:   usecase.CredentialsInput{Email: "ALICE@example.com", Password: "correct horse"},
+			wantErr: false,
+		},
+		{
+			name:        "wrong password",
+			input:       usecase.CredentialsInput{Email: "alice@example.com", Password: "battery staple"},
+			wantErr:     true,
+			errContains: "invalid email or password",
+		},
+		{
+			name:        "unknown email",
+			input:       usecase.CredentialsInput{Email: "bob@example.com", Password: "correct horse"},
+			wantErr:     true,
+			errContains: "invalid email or password",
+		},
+		{
+			name:        "malformed email",
+			input:       usecase.CredentialsInput{Email: "alice", Password: "correct horse"},
+			wantErr:     true,
+			errContains: "invalid email or password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &usecase.UserUsecase{UserRepo: &usecase.MockUserRepo{GetByEmailFn: getByEmail}}
+
+			user, err := uc.Login(tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), user.ID)
+			}
+		})
+	}
+}
+
+func TestStartSession(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "alice@example.com"}
+
+	var stored *domain.Session
+	sessionRepo := &usecase.MockSessionRepo{
+		CreateFn: func(s *domain.Session) error {
+			stored = s
+			return nil
+		},
+	}
+	uc := &usecase.UserUsecase{SessionRepo: sessionRepo, SessionTTL: time.Hour}
+
+	token, err := uc.StartSession(user)
+	assert.NoError(t, err)
+	assert.Equal(t, user, token.User)
+	assert.Equal(t, uint(1), stored.UserID)
+	assert.Equal(t, domain.HashToken(token.Token), stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, time.Minute)
+
+	sessionRepo.CreateFn = func(s *domain.Session) error { return errors.New("db error") }
+	_, err = uc.StartSession(user)
+	assert.ErrorContains(t, err, "failed to start session")
+}
+
+func TestAuthenticate(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		mockGetByHash func(string) (*domain.Session, error)
+		mockGetUser   func(uint) (*domain.User, error)
+		wantDeleted   bool
+		wantErr       bool
+		errContains   string
+	}{
+		{
+			name: "valid session",
+			mockGetByHash: func(hash string) (*domain.Session, error) {
+				assert.Equal(t, domain.HashToken("token"), hash)
+				return &domain.Session{ID: 3, UserID: 1, ExpiresAt: now.Add(time.Hour)}, nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown token",
+			mockGetByHash: func(hash string) (*domain.Session, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			wantErr:     true,
+			errContains: "invalid or expired session",
+		},
+		{
+			name: "expired session is deleted",
+			mockGetByHash: func(hash string) (*domain.Session, error) {
+				return &domain.Session{ID: 3, UserID: 1, ExpiresAt: now.Add(-time.Minute)}, nil
+			},
+			wantDeleted: true,
+			wantErr:     true,
+			errContains: "invalid or expired session",
+		},
+		{
+			name: "user deleted",
+			mockGetByHash: func(hash string) (*domain.Session, error) {
+				return &domain.Session{ID: 3, UserID: 9, ExpiresAt: now.Add(time.Hour)}, nil
+			},
+			mockGetUser: func(id uint) (*domain.User, error) {
+				return nil, gorm.ErrRecordNotFound
+			},
+			wantErr:     true,
+			errContains: "invalid or expired session",
+		},
+		{
+			name: "repository error",
+			mockGetByHash: func(hash string) (*domain.Session, error) {
+				return nil, errors.New("db error")
+			},
+			wantErr:     true,
+			errContains: "failed to authenticate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted := false
+			sessionRepo := &usecase.MockSessionRepo{
+				GetByTokenHashFn: tt.mockGetByHash,
+				DeleteFn: func(id uint) error {
+					deleted = true
+					return nil
+				},
+			}
+			getUser := tt.mockGetUser
+			if getUser == nil {
+				getUser = func(id uint) (*domain.User, error) {
+					return &domain.User{ID: id}, nil
+				}
+			}
+			uc := &usecase.UserUsecase{UserRepo: &usecase.MockUserRepo{GetByIDFn: getUser}, SessionRepo: sessionRepo}
+
+			user, session, err := uc.Authenticate("token")
+
+			assert.Equal(t, tt.wantDeleted, deleted)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), user.ID)
+				assert.Equal(t, uint(3), session.ID)
+			}
+		})
+	}
+}
+
+func TestRefreshSession(t *testing.T) {
+	var created *domain.Session
+	var deleted uint
+	sessionRepo := &usecase.MockSessionRepo{
+		CreateFn: func(s *domain.Session) error {
+			s.ID = 4
+			created = s
+			return nil
+		},
+		DeleteFn: func(id uint) error {
+			deleted = id
+			return nil
+		},
+	}
+	userRepo := &usecase.MockUserRepo{
+		GetByIDFn: func(id uint) (*domain.User, error) {
+			return &domain.User{ID: id}, nil
+		},
+	}
+	uc := &usecase.UserUsecase{UserRepo: userRepo, SessionRepo: sessionRepo}
+
+	token, err := uc.RefreshSession(1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), created.UserID)
+	assert.Equal(t, domain.HashToken(token.Token), created.TokenHash)
+	assert.Equal(t, uint(3), deleted, "the old session is revoked")
+}
@@ -0,0 +1,215 @@
+package testutils
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jt00721/habit-tracker/infrastructure"
+)
+
+const (
+	MockClientID     = "habit-tracker"
+	MockClientSecret = "client-secret"
+	mockKeyID        = "test-key"
+)
+
+// MockIdentity is the account the mock issuer logs in as
+type MockIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// MockIssuer is a local OpenID Connect provider for tests. Its authorization
+// endpoint logs Identity in straight away instead of showing a login page, and
+// its token endpoint checks the client secret, redirect URI and PKCE verifier.
+type MockIssuer struct {
+	*httptest.Server
+	Identity MockIdentity
+
+	// Key signs ID tokens and is published at the JWKS endpoint. SigningKey, if
+	// set, signs ID tokens instead, to test signature checks.
+	Key        *rsa.PrivateKey
+	SigningKey *rsa.PrivateKey
+	// ModifyClaims, if set, is called on the claims of each ID token before it
+	// is signed
+	ModifyClaims func(claims map[string]interface{})
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	identity      MockIdentity
+	nonce         string
+	codeChallenge string
+	redirectURI   string
+}
+
+func NewMockIssuer(t *testing.T) *MockIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate mock issuer key: %v", err)
+	}
+
+	issuer := &MockIssuer{
+		Identity: MockIdentity{Subject: "mock-subject", Email: "sso@example.com", EmailVerified: true},
+		Key:      key,
+		codes:    make(map[string]mockAuthorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/jwks", issuer.jwks)
+	mux.HandleFunc("/authorize", issuer.authorize)
+	mux.HandleFunc("/token", issuer.token)
+	issuer.Server = httptest.NewServer(mux)
+	return issuer
+}
+
+// Config returns the client registration for this issuer
+func (issuer *MockIssuer) Config(redirectURL string) infrastructure.OIDCConfig {
+	return infrastructure.OIDCConfig{
+		IssuerURL:    issuer.URL,
+		ClientID:     MockClientID,
+		ClientSecret: MockClientSecret,
+		RedirectURL:  redirectURL,
+	}
+}
+
+// Authorize logs Identity in as the authorization endpoint would and returns the
+// code it would redirect back with
+func (issuer *MockIssuer) Authorize(nonce, codeChallenge, redirectURI string) string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	code := base64.RawURLEncoding.EncodeToString(buf)
+
+	issuer.mu.Lock()
+	defer issuer.mu.Unlock()
+	issuer.codes[code] = mockAuthorization{identity: issuer.Identity, nonce: nonce, codeChallenge: codeChallenge, redirectURI: redirectURI}
+	return code
+}
+
+func (issuer *MockIssuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 issuer.URL,
+		"authorization_endpoint": issuer.URL + "/authorize",
+		"token_endpoint":         issuer.URL + "/token",
+		"jwks_uri":               issuer.URL + "/jwks",
+	})
+}
+
+func (issuer *MockIssuer) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": mockKeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(issuer.Key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(issuer.Key.E)).Bytes()),
+		}},
+	})
+}
+
+func (issuer *MockIssuer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != MockClientID || query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := redirect.Query()
+	params.Set("code", issuer.Authorize(query.Get("nonce"), query.Get("code_challenge"), query.Get("redirect_uri")))
+	params.Set("state", query.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (issuer *MockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, _ := r.BasicAuth()
+	if clientID != MockClientID || secret != MockClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostFormValue("code")
+	issuer.mu.Lock()
+	auth, ok := issuer.codes[code]
+	delete(issuer.codes, code)
+	issuer.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != auth.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":            issuer.URL,
+		"sub":            auth.identity.Subject,
+		"aud":            MockClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          auth.nonce,
+		"email":          auth.identity.Email,
+		"email_verified": auth.identity.EmailVerified,
+	}
+	if issuer.ModifyClaims != nil {
+		issuer.ModifyClaims(claims)
+	}
+
+	idToken, err := issuer.sign(claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": "mock-access-token", "token_type": "Bearer", "expires_in": 3600, "id_token": idToken})
+}
+
+func (issuer *MockIssuer) sign(claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": mockKeyID, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	key := issuer.Key
+	if issuer.SigningKey != nil {
+		key = issuer.SigningKey
+	}
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
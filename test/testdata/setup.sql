@@ -1,18 +1,261 @@
 -- setup.sql
 
+DROP TABLE IF EXISTS challenge_participants CASCADE;
+DROP TABLE IF EXISTS challenges CASCADE;
+DROP TABLE IF EXISTS completion_comments CASCADE;
+DROP TABLE IF EXISTS completion_reactions CASCADE;
+DROP TABLE IF EXISTS habit_members CASCADE;
+DROP TABLE IF EXISTS streak_runs CASCADE;
+DROP TABLE IF EXISTS skips CASCADE;
+DROP TABLE IF EXISTS relapses CASCADE;
+DROP TABLE IF EXISTS timer_sessions CASCADE;
+DROP TABLE IF EXISTS settings CASCADE;
+DROP TABLE IF EXISTS habit_completions CASCADE;
 DROP TABLE IF EXISTS habits CASCADE;
+DROP TABLE IF EXISTS login_challenges CASCADE;
+DROP TABLE IF EXISTS recovery_codes CASCADE;
+DROP TABLE IF EXISTS user_identities CASCADE;
+DROP TABLE IF EXISTS access_tokens CASCADE;
+DROP TABLE IF EXISTS sessions CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
+
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    totp_secret TEXT NOT NULL DEFAULT '',
+    totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    totp_last_counter BIGINT NOT NULL DEFAULT 0,
+    is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE sessions (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+CREATE INDEX idx_sessions_expires_at ON sessions (expires_at);
+
+CREATE TABLE access_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT NOT NULL,
+    last_used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_access_tokens_user_id ON access_tokens (user_id);
+
+CREATE TABLE user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities (user_id);
+CREATE UNIQUE INDEX idx_user_identities_issuer_subject ON user_identities (issuer, subject);
+
+CREATE TABLE recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes (user_id);
+
+CREATE TABLE login_challenges (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_challenges_user_id ON login_challenges (user_id);
+CREATE INDEX idx_login_challenges_expires_at ON login_challenges (expires_at);
 
 CREATE TABLE habits (
     id SERIAL PRIMARY KEY,
+    user_id BIGINT NULL,
     name VARCHAR(100) NOT NULL,
     frequency VARCHAR(10) NOT NULL,
+    kind TEXT NOT NULL DEFAULT 'build',
+    times_per_period INT NOT NULL DEFAULT 0,
+    weekdays TEXT NOT NULL DEFAULT '',
+    month_days TEXT NOT NULL DEFAULT '',
+    interval_days INT NOT NULL DEFAULT 0,
+    rrule TEXT NOT NULL DEFAULT '',
+    target_value DOUBLE PRECISION NOT NULL DEFAULT 0,
+    unit TEXT NOT NULL DEFAULT '',
     current_streak INT DEFAULT 0,
+    longest_streak INT NOT NULL DEFAULT 0,
+    strength DOUBLE PRECISION NOT NULL DEFAULT 0,
     last_completed_at TIMESTAMP NULL,
     total_completions INT DEFAULT 0,
+    streak_freezes INT NOT NULL DEFAULT 0,
+    last_relapse_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_habits_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_habits_user_id ON habits (user_id);
+
+CREATE TABLE habit_completions (
+    id SERIAL PRIMARY KEY,
+    habit_id INT NOT NULL,
+    completed_at TIMESTAMP NOT NULL,
+    quantity DOUBLE PRECISION NOT NULL DEFAULT 1,
+    strength DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_habit_completions_habit_id ON habit_completions (habit_id);
+CREATE INDEX idx_habit_completions_completed_at ON habit_completions (completed_at);
+
+CREATE TABLE settings (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL DEFAULT 0,
+    timezone TEXT NOT NULL,
+    week_start BIGINT,
+    day_end_hour BIGINT,
+    freeze_earn_interval BIGINT NOT NULL DEFAULT 7,
+    max_streak_freezes BIGINT NOT NULL DEFAULT 2,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE UNIQUE INDEX idx_settings_user_id ON settings (user_id);
+
+CREATE TABLE timer_sessions (
+    id SERIAL PRIMARY KEY,
+    habit_id INT NOT NULL,
+    status TEXT NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    resumed_at TIMESTAMP NULL,
+    elapsed_seconds BIGINT NOT NULL DEFAULT 0,
+    stopped_at TIMESTAMP NULL,
+    completion_id BIGINT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_timer_sessions_habit_id ON timer_sessions (habit_id);
+
+CREATE TABLE relapses (
+    id SERIAL PRIMARY KEY,
+    habit_id INT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_relapses_habit_id ON relapses (habit_id);
+CREATE INDEX idx_relapses_occurred_at ON relapses (occurred_at);
+
+CREATE TABLE skips (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL DEFAULT 0,
+    habit_id BIGINT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    reason TEXT NOT NULL DEFAULT 'skip',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_skips_user_id ON skips (user_id);
+CREATE INDEX idx_skips_habit_id ON skips (habit_id);
+
+CREATE TABLE streak_runs (
+    id SERIAL PRIMARY KEY,
+    habit_id BIGINT NOT NULL,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    length BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_streak_runs_habit_id ON streak_runs (habit_id);
+
+CREATE TABLE habit_members (
+    id SERIAL PRIMARY KEY,
+    habit_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    role TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_habit_members_habit_user ON habit_members (habit_id, user_id);
+CREATE INDEX idx_habit_members_user_id ON habit_members (user_id);
+
+CREATE TABLE completion_reactions (
+    id SERIAL PRIMARY KEY,
+    completion_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_completion_reactions_user_emoji ON completion_reactions (completion_id, user_id, emoji);
+
+CREATE TABLE completion_comments (
+    id SERIAL PRIMARY KEY,
+    completion_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_completion_comments_completion_id ON completion_comments (completion_id);
+CREATE INDEX idx_completion_comments_user_id ON completion_comments (user_id);
+
+CREATE TABLE challenges (
+    id SERIAL PRIMARY KEY,
+    creator_id BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    frequency TEXT NOT NULL,
+    times_per_period INT NOT NULL DEFAULT 0,
+    weekdays TEXT NOT NULL DEFAULT '',
+    month_days TEXT NOT NULL DEFAULT '',
+    interval_days INT NOT NULL DEFAULT 0,
+    rrule TEXT NOT NULL DEFAULT '',
+    target_value DOUBLE PRECISION NOT NULL DEFAULT 0,
+    unit TEXT NOT NULL DEFAULT '',
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_challenges_creator_id ON challenges (creator_id);
+CREATE INDEX idx_challenges_start_date ON challenges (start_date);
+
+CREATE TABLE challenge_participants (
+    id SERIAL PRIMARY KEY,
+    challenge_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    habit_id BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_challenge_participants_challenge_user ON challenge_participants (challenge_id, user_id);
+CREATE INDEX idx_challenge_participants_user_id ON challenge_participants (user_id);
+CREATE INDEX idx_challenge_participants_habit_id ON challenge_participants (habit_id);
+
 -- Trigger to auto-update updated_at on row update
 CREATE OR REPLACE FUNCTION update_timestamp()
 RETURNS TRIGGER AS $$
@@ -29,6 +272,13 @@ BEFORE UPDATE ON habits
 FOR EACH ROW
 EXECUTE FUNCTION update_timestamp();
 
--- Seed data
-INSERT INTO habits (name, frequency, current_streak, last_completed_at, total_completions)
-VALUES ('Test Habit', 'daily', 5, NOW(), 10);
+-- Seed data, both users' password is "password123" and the first is an admin
+INSERT INTO users (email, password_hash, is_admin)
+VALUES ('test@example.com', '$2a$10$m58MmBBMCg4Y.00H4ytt1Oxf95DSO.GgcFbmP839eAV80OoNXQ6uS', TRUE),
+       ('other@example.com', '$2a$10$m58MmBBMCg4Y.00H4ytt1Oxf95DSO.GgcFbmP839eAV80OoNXQ6uS', FALSE);
+
+INSERT INTO habits (user_id, name, frequency, current_streak, last_completed_at, total_completions, created_at)
+VALUES (1, 'Test Habit', 'daily', 5, NOW(), 10, '2023-12-01');
+
+INSERT INTO habit_completions (habit_id, completed_at)
+VALUES (1, NOW());
@@ -1,3 +1,20 @@
 -- teardown.sql
+DROP TABLE IF EXISTS challenge_participants CASCADE;
+DROP TABLE IF EXISTS challenges CASCADE;
+DROP TABLE IF EXISTS completion_comments CASCADE;
+DROP TABLE IF EXISTS completion_reactions CASCADE;
+DROP TABLE IF EXISTS habit_members CASCADE;
+DROP TABLE IF EXISTS streak_runs CASCADE;
+DROP TABLE IF EXISTS skips CASCADE;
+DROP TABLE IF EXISTS relapses CASCADE;
+DROP TABLE IF EXISTS timer_sessions CASCADE;
+DROP TABLE IF EXISTS settings CASCADE;
+DROP TABLE IF EXISTS habit_completions CASCADE;
 DROP TABLE IF EXISTS habits CASCADE;
+DROP TABLE IF EXISTS login_challenges CASCADE;
+DROP TABLE IF EXISTS recovery_codes CASCADE;
+DROP TABLE IF EXISTS user_identities CASCADE;
+DROP TABLE IF EXISTS access_tokens CASCADE;
+DROP TABLE IF EXISTS sessions CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
 DROP FUNCTION IF EXISTS update_timestamp();
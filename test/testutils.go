@@ -1,6 +1,8 @@
 package testutils
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/jt00721/habit-tracker/infrastructure"
 	"github.com/jt00721/habit-tracker/internal/repository"
 	"github.com/jt00721/habit-tracker/internal/routes"
 	"github.com/jt00721/habit-tracker/internal/usecase"
@@ -19,8 +22,20 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+// Credentials of the users seeded by setup.sql, the first owns the seeded habit
+const (
+	TestUserEmail  = "test@example.com"
+	OtherUserEmail = "other@example.com"
+	TestPassword   = "password123"
+)
+
 type TestServer struct {
 	*httptest.Server
+	// Client is logged in as the test user, requests made with it send the
+	// session token as a bearer token
+	Client *http.Client
+	// Issuer is the identity provider single sign-on is configured with
+	Issuer *MockIssuer
 }
 
 func NewTestDB(t *testing.T) (*gorm.DB, func()) {
@@ -75,22 +90,77 @@ func NewTestServer(t *testing.T) (*TestServer, *usecase.HabitUsecase, func()) {
 	db, teardownDB := NewTestDB(t)
 
 	repo := &repository.HabitRepository{DB: db}
-	habitUc := &usecase.HabitUsecase{HabitRepo: repo}
+	settingsRepo := &repository.SettingsRepository{DB: db}
+	timerRepo := &repository.TimerSessionRepository{DB: db}
+	skipRepo := &repository.SkipRepository{DB: db}
+	habitUc := &usecase.HabitUsecase{HabitRepo: repo, SettingsRepo: settingsRepo, TimerRepo: timerRepo, SkipRepo: skipRepo}
+	settingsUc := &usecase.SettingsUsecase{SettingsRepo: settingsRepo, HabitRepo: repo, SkipRepo: skipRepo}
+	skipUc := &usecase.SkipUsecase{SkipRepo: skipRepo, HabitRepo: repo, SettingsRepo: settingsRepo}
+	statsUc := &usecase.StatsUsecase{HabitRepo: repo, SettingsRepo: settingsRepo, SkipRepo: skipRepo}
+	userUc := &usecase.UserUsecase{UserRepo: &repository.UserRepository{DB: db}, SessionRepo: &repository.SessionRepository{DB: db}}
+	tokenUc := &usecase.AccessTokenUsecase{TokenRepo: &repository.AccessTokenRepository{DB: db}}
+	ssoUc := &usecase.SSOUsecase{UserRepo: userUc.UserRepo}
+	twoFactorUc := &usecase.TwoFactorUsecase{UserRepo: userUc.UserRepo, TwoFactorRepo: &repository.TwoFactorRepository{DB: db}, SessionRepo: userUc.SessionRepo}
+	sharingUc := &usecase.SharingUsecase{HabitRepo: repo, SharingRepo: &repository.SharingRepository{DB: db}, UserRepo: userUc.UserRepo}
+	challengeUc := &usecase.ChallengeUsecase{ChallengeRepo: &repository.ChallengeRepository{DB: db}, HabitRepo: repo, UserRepo: userUc.UserRepo, SettingsRepo: settingsRepo, SkipRepo: skipRepo}
+	streakResetUc := &usecase.StreakResetUsecase{HabitRepo: repo, SettingsRepo: settingsRepo, SkipRepo: skipRepo, TimerRepo: timerRepo, Lock: &repository.JobLockRepository{DB: db}}
 
 	router := gin.Default()
 	router.Use(gin.Recovery())
-	routes.SetupRoutes(router, habitUc)
+	routes.SetupRoutes(router, habitUc, settingsUc, skipUc, streakResetUc, statsUc, userUc, tokenUc, ssoUc, twoFactorUc, sharingUc, challengeUc)
 
 	server := httptest.NewServer(router)
 
-	return &TestServer{server}, habitUc, func() {
+	// The redirect URL is only known once the server is listening
+	issuer := NewMockIssuer(t)
+	ssoUc.Provider = infrastructure.NewOIDCProvider(issuer.Config(server.URL + "/api/oidc/callback"))
+
+	ts := &TestServer{Server: server, Issuer: issuer}
+	ts.Client = ts.Login(t, TestUserEmail, TestPassword)
+
+	return ts, habitUc, func() {
 		server.Close() // close test server
+		issuer.Close() // close mock identity provider
 		teardownDB()   // teardown DB
 	}
 }
 
+// Login starts a session for the user and returns a client authenticated with it
+func (ts *TestServer) Login(t *testing.T, email, password string) *http.Client {
+	body, err := json.Marshal(map[string]string{"email": email, "password": password})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := http.Post(ts.URL+"/api/login", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Failed to log in as %s: status %d", email, res.StatusCode)
+	}
+
+	var session struct{ Token string }
+	if err := json.NewDecoder(res.Body).Decode(&session); err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{Transport: bearerTransport{token: session.Token}}
+}
+
+// bearerTransport sends the token as a bearer token with every request
+type bearerTransport struct {
+	token string
+}
+
+func (transport bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+transport.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
 func (ts *TestServer) Get(t *testing.T, path string) (*http.Response, []byte) {
-	res, err := http.Get(ts.URL + path)
+	res, err := ts.Client.Get(ts.URL + path)
 	if err != nil {
 		t.Fatal(err)
 	}